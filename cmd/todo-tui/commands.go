@@ -0,0 +1,1007 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/backup"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/deps"
+	"github.com/blackraven/todo-tui/internal/editor"
+	"github.com/blackraven/todo-tui/internal/export"
+	"github.com/blackraven/todo-tui/internal/importer"
+	"github.com/blackraven/todo-tui/internal/recur"
+	"github.com/blackraven/todo-tui/internal/remind"
+	"github.com/blackraven/todo-tui/internal/schedule"
+	"github.com/blackraven/todo-tui/internal/stats"
+	"github.com/blackraven/todo-tui/internal/taskdoc"
+	"github.com/blackraven/todo-tui/internal/templates"
+	"github.com/blackraven/todo-tui/internal/timelog"
+)
+
+// command is a subcommand such as "todo-tui export"
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// commands lists the available subcommands
+var commands = []command{
+	{"export", "Export tasks to JSON, CSV, Markdown, todo.txt or iCalendar", runExport},
+	{"import", "Import tasks from todo.txt, Markdown, CSV, Taskwarrior or JSON", runImport},
+	{"add", "Create a task, optionally from a template", runAdd},
+	{"template", "List, show, save or delete task templates", runTemplate},
+	{"done", "Mark tasks done, creating the next occurrence of repeating ones", runDone},
+	{"repeat", "Show or set how a task repeats", runRepeat},
+	{"deps", "Show, add or remove the tasks a task waits on", runDeps},
+	{"edit", "Edit a task's fields, notes and subtasks in $EDITOR", runEdit},
+	{"stats", "Show completion counts, overdue tasks and other statistics", runStats},
+	{"timer", "Start or stop a timer on a task and report the time logged", runTimer},
+	{"daemon", "Send reminders for tasks coming due, or snooze one", runDaemon},
+	{"plan", "Propose time blocks for estimated tasks and schedule them", runPlan},
+	{"backup", "Snapshot all tasks and categories into a backup archive", runBackup},
+	{"restore", "Recreate tasks and categories from a backup archive", runRestore},
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// usage prints help for the top-level flags and the subcommands
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags]\n       %s <command> [flags]\n\nFlags:\n", os.Args[0], os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(out, "\nRun '%s <command> -h' for command flags.\n", os.Args[0])
+}
+
+// newFlagSet creates the flag set for a subcommand
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n\nFlags:\n", os.Args[0], name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseStatus maps a -status flag onto list parameters; "all" is no
+// status, which export.Fetch lists as open and done tasks in turn
+func parseStatus(status string) (string, error) {
+	switch status {
+	case "open", "done":
+		return status, nil
+	case "all", "":
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid status %q (want open, done or all)", status)
+}
+
+// runExport writes tasks to a file or stdout
+func runExport(args []string) {
+	fs := newFlagSet("export", "[flags]")
+	format := fs.String("format", "json", "Output format: json, csv, markdown, todotxt or ics")
+	output := fs.String("o", "", "Output file, or - for stdout (default todo-export-<time>.<ext>)")
+	status := fs.String("status", "all", "Tasks to export: open, done or all")
+	scope := fs.String("scope", "all", "Ownership scope: all, mine or shared")
+	fs.Parse(args)
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		fatal(err)
+	}
+	st, err := parseStatus(*status)
+	if err != nil {
+		fatal(err)
+	}
+
+	client, _ := setup()
+	if !ensureAuth(client) {
+		return
+	}
+
+	doc, err := export.Fetch(client, api.TaskListParams{Status: st, Scope: *scope})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching tasks: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "-" {
+		if err := export.Write(os.Stdout, f, doc); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	path := *output
+	if path == "" {
+		path = export.FileName(f, time.Now())
+	}
+	if err := export.WriteFile(path, f, doc); err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Exported %d tasks to %s\n", len(doc.Tasks), path)
+}
+
+// runStats prints statistics about open and completed tasks
+func runStats(args []string) {
+	fs := newFlagSet("stats", "[flags]")
+	output := fs.String("output", "text", "Output format: text or json")
+	fs.Parse(args)
+	if *output != "text" && *output != "json" {
+		fatal(fmt.Errorf("invalid output %q (want text or json)", *output))
+	}
+
+	client, _ := setup()
+	if !ensureAuth(client) {
+		return
+	}
+	report, err := stats.Fetch(client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching tasks: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if err := stats.WriteText(os.Stdout, report); err != nil {
+		fatal(err)
+	}
+}
+
+// runImport creates tasks from a file written by another tool
+func runImport(args []string) {
+	fs := newFlagSet("import", "[flags] <file>")
+	format := fs.String("format", "auto", "Input format: todotxt, markdown, csv, taskwarrior, json or auto")
+	dryRun := fs.Bool("dry-run", false, "Show what would be imported without creating anything")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fatal(err)
+	}
+	var f importer.Format
+	if *format == "auto" {
+		f, err = importer.Detect(path, data)
+	} else {
+		f, err = importer.ParseFormat(*format)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	items, err := importer.Parse(bytes.NewReader(data), f)
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	client, _ := setup()
+	if !ensureAuth(client) {
+		return
+	}
+	existing, err := client.ListAllTasks("all")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching tasks: %v\n", err)
+		os.Exit(1)
+	}
+	categories, err := client.ListCategories()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching categories: %v\n", err)
+		os.Exit(1)
+	}
+
+	plan := importer.BuildPlan(items, existing, categories)
+	if *dryRun {
+		plan.WriteTable(os.Stdout)
+		return
+	}
+
+	res, err := importer.Apply(client, plan)
+	for _, e := range res.Errors {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", e)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Imported %d tasks (%d duplicates skipped, %d categories created)\n",
+		res.Created, res.Skipped, res.CategoriesCreated)
+	if len(res.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+// runBackup snapshots the account into the profile's backup directory
+func runBackup(args []string) {
+	fs := newFlagSet("backup", "[flags]")
+	profile := fs.String("profile", "", "Profile to back up")
+	server := fs.String("server", "", "API URL, overriding the profile's")
+	output := fs.String("o", "", "Archive file (default <data dir>/backups/backup-<time>.json.gz)")
+	list := fs.Bool("list", false, "List existing backups instead of taking one")
+	fs.Parse(args)
+
+	client, cfg := setupProfile(*profile, *server)
+	dir := backup.Dir(cfg.DataDir)
+	if *list {
+		backups, err := backup.List(dir)
+		if err != nil {
+			fatal(err)
+		}
+		if len(backups) == 0 {
+			fmt.Printf("No backups in %s\n", dir)
+			return
+		}
+		for _, b := range backups {
+			fmt.Printf("%s  %7.1f KB  %s\n", b.CreatedAt.Format("2006-01-02 15:04:05"), float64(b.Size)/1024, b.Path)
+		}
+		return
+	}
+
+	if !ensureAuth(client) {
+		return
+	}
+	a, err := backup.Snapshot(client)
+	if err != nil {
+		fatal(err)
+	}
+	path := *output
+	if path == "" {
+		path, err = backup.Save(dir, a)
+	} else {
+		err = backup.WriteFile(path, a)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("Backed up %d tasks and %d categories to %s\n", len(a.Tasks), len(a.Categories), path)
+}
+
+// runRestore recreates a backup into a profile, optionally diffing first
+func runRestore(args []string) {
+	fs := newFlagSet("restore", "[flags] [file|latest]")
+	profile := fs.String("profile", "", "Profile to restore into")
+	server := fs.String("server", "", "API URL, overriding the profile's")
+	from := fs.String("from", "", "Profile whose backups to read (default the target profile)")
+	diff := fs.Bool("diff", false, "Show how the backup differs from the live account and exit")
+	dryRun := fs.Bool("dry-run", false, "Show what would be restored without changing anything")
+	shared := fs.Bool("include-shared", false, "Also recreate tasks that were shared with the account")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, cfg := setupProfile(*profile, *server)
+	path := fs.Arg(0)
+	if path == "" || path == "latest" {
+		dir := backup.Dir(cfg.DataDir)
+		if *from != "" {
+			dir = backup.Dir(config.ProfileDataDir(*from))
+		}
+		var err error
+		if path, err = backup.Latest(dir); err != nil {
+			fatal(err)
+		}
+	}
+	a, err := backup.Load(path)
+	if err != nil {
+		fatal(err)
+	}
+
+	if !ensureAuth(client) {
+		return
+	}
+	if *diff {
+		d, err := backup.Compare(client, a)
+		if err != nil {
+			fatal(err)
+		}
+		d.WriteTo(os.Stdout)
+		return
+	}
+
+	res, err := backup.Restore(client, a, backup.RestoreOptions{IncludeShared: *shared, DryRun: *dryRun})
+	for _, e := range res.Errors {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", e)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	verb := "Restored"
+	if *dryRun {
+		verb = "Would restore"
+	}
+	fmt.Printf("%s %d tasks from %s (%d already present; %d categories created, %d reused)\n",
+		verb, res.TasksCreated, path, res.TasksSkipped, res.CategoriesCreated, res.CategoriesReused)
+	if len(res.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+// runAdd creates a task from a title or from a template
+func runAdd(args []string) {
+	fs := newFlagSet("add", "[flags] <title> | -template <name> [var=value...]")
+	name := fs.String("template", "", "Template to create the task from")
+	fs.Parse(args)
+
+	client, cfg := setup()
+	if *name == "" {
+		title := strings.TrimSpace(strings.Join(fs.Args(), " "))
+		if title == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		createTaskFromCLI(client, title)
+		return
+	}
+
+	list, err := templates.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+	tmpl, ok := templates.Find(list, *name)
+	if !ok {
+		fatal(fmt.Errorf("no template named %q in %s", *name, templates.Dir(cfg.DataDir)))
+	}
+	vars := make(map[string]string)
+	for _, arg := range fs.Args() {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			fatal(fmt.Errorf("expected var=value, got %q", arg))
+		}
+		vars[k] = v
+	}
+	tmpl, err = tmpl.Expand(vars, time.Now())
+	if err != nil {
+		fatal(err)
+	}
+
+	if !ensureAuth(client) {
+		return
+	}
+	categories, err := client.ListCategories()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching categories: %v\n", err)
+		os.Exit(1)
+	}
+	task, err := templates.Instantiate(client, tmpl, categories)
+	if task != nil {
+		fmt.Printf("Created task #%d: %s (%d subtasks)\n", task.ID, task.Title, len(task.Subtasks))
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// runTemplate manages the saved templates
+func runTemplate(args []string) {
+	fs := newFlagSet("template", "list | show <name> | save <name> <task-id> | delete <name>")
+	fs.Parse(args)
+
+	client, cfg := setup()
+	switch sub := fs.Arg(0); {
+	case sub == "" || sub == "list":
+		list, err := templates.Load(cfg.DataDir)
+		if err != nil {
+			fatal(err)
+		}
+		if len(list) == 0 {
+			fmt.Printf("No templates in %s\n", templates.Dir(cfg.DataDir))
+			return
+		}
+		for _, t := range list {
+			vars := ""
+			if p := t.Prompts(); len(p) > 0 {
+				vars = " (" + strings.Join(p, ", ") + ")"
+			}
+			fmt.Printf("%-16s %s%s, %d subtasks\n", t.Name, t.Title, vars, len(t.Subtasks))
+		}
+
+	case sub == "show" && fs.NArg() == 2:
+		list, err := templates.Load(cfg.DataDir)
+		if err != nil {
+			fatal(err)
+		}
+		t, ok := templates.Find(list, fs.Arg(1))
+		if !ok {
+			fatal(fmt.Errorf("no template named %q", fs.Arg(1)))
+		}
+		fmt.Printf("Title:    %s\n", t.Title)
+		if t.Category != "" {
+			fmt.Printf("Category: %s\n", t.Category)
+		}
+		if t.Priority > 0 {
+			fmt.Printf("Priority: %d\n", t.Priority)
+		}
+		if t.Notes != "" {
+			fmt.Printf("Notes:    %s\n", t.Notes)
+		}
+		for _, st := range t.Subtasks {
+			fmt.Printf("  [ ] %s\n", st)
+		}
+
+	case sub == "save" && fs.NArg() == 3:
+		id := parseTaskID(fs.Arg(2))
+		if !ensureAuth(client) {
+			return
+		}
+		task, err := client.GetTask(id)
+		if err != nil {
+			fatal(err)
+		}
+		if err := templates.Save(cfg.DataDir, templates.FromTask(fs.Arg(1), *task)); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Saved task #%d as template %q\n", id, fs.Arg(1))
+
+	case sub == "delete" && fs.NArg() == 2:
+		if err := templates.Delete(cfg.DataDir, fs.Arg(1)); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Deleted template %q\n", fs.Arg(1))
+
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// parseTaskID reads a task ID argument
+func parseTaskID(s string) int {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || id <= 0 {
+		fatal(fmt.Errorf("invalid task ID %q", s))
+	}
+	return id
+}
+
+// loadRecurrence reads the repeat rules stored next to the configuration
+func loadRecurrence(cfg *config.Config) *recur.Store {
+	store, err := recur.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+	return store
+}
+
+// runDone completes tasks by ID
+func runDone(args []string) {
+	fs := newFlagSet("done", "<id>...")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	ids := make([]int, fs.NArg())
+	for i, arg := range fs.Args() {
+		ids[i] = parseTaskID(arg)
+	}
+
+	client, cfg := setup()
+	if !ensureAuth(client) {
+		return
+	}
+	rules := loadRecurrence(cfg)
+	links, err := deps.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+
+	status := "done"
+	failed := false
+	for _, id := range ids {
+		task, err := client.UpdateTask(id, api.TaskUpdateRequest{Status: &status})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error completing task #%d: %v\n", id, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Completed task #%d: %s\n", task.ID, task.Title)
+		for _, t := range unblocked(client, links, id) {
+			fmt.Printf("  Unblocked #%d: %s\n", t.ID, t.Title)
+		}
+
+		next, err := rules.Complete(client, *task, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating next occurrence of #%d: %v\n", id, err)
+			failed = true
+		}
+		if next != nil && next.DueAt != nil {
+			fmt.Printf("  Next occurrence #%d due %s\n", next.ID, next.DueAt.Local().Format("Mon Jan 2, 2006 3:04 PM"))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runRepeat shows, sets or clears a task's repeat rule
+func runRepeat(args []string) {
+	fs := newFlagSet("repeat", "<id> [rule|none]")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		fmt.Fprintf(fs.Output(), "\nRules: daily, every 3 days, weekly on mon,thu, every 2 weeks on fri,\n"+
+			"monthly on 15, monthly on last, monthly on 2nd tue, every 10 days after done\n")
+		os.Exit(2)
+	}
+	id := parseTaskID(fs.Arg(0))
+	spec := strings.Join(fs.Args()[1:], " ")
+
+	client, cfg := setup()
+	rules := loadRecurrence(cfg)
+
+	switch spec {
+	case "":
+		if r, ok := rules.Get(id); ok {
+			fmt.Printf("Task #%d repeats %s\n", id, r)
+		} else {
+			fmt.Printf("Task #%d does not repeat\n", id)
+		}
+	case "none":
+		if err := rules.Delete(id); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Task #%d no longer repeats\n", id)
+	default:
+		r, err := recur.Parse(spec)
+		if err != nil {
+			fatal(err)
+		}
+		task, err := client.GetTask(id)
+		if err != nil {
+			fatal(err)
+		}
+		r = r.Anchor(task.DueAt)
+		if err := rules.Set(id, r); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Task #%d repeats %s\n", id, r)
+	}
+}
+
+// runTimer starts and stops timers in the time log the TUI shares, and
+// reports the time logged per task
+func runTimer(args []string) {
+	fs := newFlagSet("timer", "start [-pomodoro] <task-id> | stop | report [-days n]")
+	pomodoro := fs.Bool("pomodoro", false, "Work in pomodoro cycles (start)")
+	days := fs.Int("days", 7, "Report on the last n days, 0 for all time (report)")
+	fs.Parse(args)
+	sub := fs.Arg(0)
+	if sub != "" {
+		fs.Parse(fs.Args()[1:])
+	}
+
+	client, cfg := setup()
+	log, err := timelog.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+	now := time.Now()
+
+	switch {
+	case sub == "start" && fs.NArg() == 1:
+		id := parseTaskID(fs.Arg(0))
+		if !ensureAuth(client) {
+			return
+		}
+		task, err := client.GetTask(id)
+		if err != nil {
+			fatal(err)
+		}
+		timer := timelog.Timer{TaskID: id, Title: task.Title, Start: now}
+		if *pomodoro {
+			timer.Pomodoro = &timelog.Pomodoro{Work: cfg.PomodoroWork, Break: cfg.PomodoroBreak}
+		}
+		logged, err := log.Start(timer)
+		if err != nil {
+			fatal(err)
+		}
+		printLogged(logged)
+		fmt.Printf("Started a timer on #%d: %s\n", id, task.Title)
+		if timer.Pomodoro != nil {
+			fmt.Printf("  %s of work, then %s breaks\n", timer.Pomodoro.Work, timer.Pomodoro.Break)
+		}
+
+	case sub == "stop" && fs.NArg() == 0:
+		logged, ok, err := log.Stop(now)
+		if err != nil {
+			fatal(err)
+		}
+		if !ok {
+			fmt.Println("No timer is running")
+			return
+		}
+		printLogged(logged)
+
+	case sub == "report" && fs.NArg() == 0:
+		var since time.Time
+		if *days > 0 {
+			y, m, d := now.AddDate(0, 0, -*days).Date()
+			since = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+		}
+		sessions := log.Sessions(0, since)
+		if active, ok := log.Active(); ok {
+			sessions = append(sessions, active.Sessions(now)...)
+			fmt.Printf("Running: #%d %s, %s so far\n\n", active.TaskID, active.Title, timelog.Format(active.Worked(now)))
+		}
+		totals := timelog.Totals(sessions)
+		if len(totals) == 0 {
+			fmt.Println("No time logged")
+			return
+		}
+
+		// Estimates come from the server; the report still works offline
+		estimates := map[int]int{}
+		if client.HasToken() {
+			if tasks, err := client.ListAllTasks("all"); err == nil {
+				for _, t := range tasks {
+					estimates[t.ID] = t.EffortMin
+				}
+			}
+		}
+		var sum time.Duration
+		for _, t := range totals {
+			sum += t.Logged
+			line := fmt.Sprintf("%9s  #%-5d %s", timelog.Format(t.Logged), t.TaskID, t.Title)
+			if est := estimates[t.TaskID]; est > 0 {
+				line += fmt.Sprintf(" (%d%% of %s estimated)", int(t.Logged/time.Minute)*100/est, time.Duration(est)*time.Minute)
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("%9s  total over %d tasks\n", timelog.Format(sum), len(totals))
+
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// runDaemon checks for tasks coming due with reminders on and notifies
+// about them until interrupted, or snoozes a task's reminder
+func runDaemon(args []string) {
+	fs := newFlagSet("daemon", "[flags] | snooze <task-id> [duration]")
+	every := fs.Duration("every", time.Minute, "How often to check for tasks coming due")
+	lead := fs.Duration("lead", 0, "Remind this long before the due time (default reminder_lead, 15m)")
+	notifier := fs.String("notifier", "auto", "Where reminders go: auto, desktop or terminal")
+	once := fs.Bool("once", false, "Check once and exit, e.g. from cron")
+	fs.Parse(args)
+
+	client, cfg := setup()
+	store, err := remind.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+
+	if fs.Arg(0) == "snooze" {
+		if fs.NArg() < 2 || fs.NArg() > 3 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		id := parseTaskID(fs.Arg(1))
+		d := 10 * time.Minute
+		if fs.NArg() == 3 {
+			if d, err = time.ParseDuration(fs.Arg(2)); err != nil || d <= 0 {
+				fatal(fmt.Errorf("invalid snooze %q", fs.Arg(2)))
+			}
+		}
+		until := time.Now().Add(d)
+		if err := store.Snooze(id, until); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Snoozed task #%d until %s\n", id, until.Format("3:04 PM"))
+		return
+	}
+	if fs.NArg() > 0 || *every <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	n, err := remind.NewNotifier(*notifier, os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	if !ensureAuth(client) {
+		return
+	}
+	d := &remind.Daemon{Client: client, Store: store, Notifier: n, Lead: cfg.ReminderLead}
+	if *lead > 0 {
+		d.Lead = *lead
+	}
+	if *once {
+		if _, err := d.Check(); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Checking every %s for tasks due within %s; Ctrl+C to stop\n", *every, d.Lead)
+	d.Run(ctx, *every, func(err error) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", time.Now().Format("15:04:05"), err)
+	})
+}
+
+// runPlan proposes time blocks for the coming days within the working
+// hours, and writes them to the tasks once confirmed
+func runPlan(args []string) {
+	fs := newFlagSet("plan", "[flags]")
+	days := fs.Int("days", 0, "Plan this many days, starting today (default plan_days, 3)")
+	yes := fs.Bool("yes", false, "Schedule the proposal without asking")
+	fs.Parse(args)
+	if fs.NArg() > 0 || *days < 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, cfg := setup()
+	if !ensureAuth(client) {
+		return
+	}
+	opts := schedule.Options{Start: cfg.WorkStart, End: cfg.WorkEnd, Days: cfg.WorkDays, Ahead: cfg.PlanDays}
+	if *days > 0 {
+		opts.Ahead = *days
+	}
+	tasks, err := client.ListTasks(api.TaskListParams{Status: "open", Scope: "all"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching tasks: %v\n", err)
+		os.Exit(1)
+	}
+	plan := schedule.Propose(tasks, time.Now(), opts)
+
+	day := ""
+	for _, s := range plan.Slots {
+		if d := s.Start.Format("Monday, Jan 2"); d != day {
+			day = d
+			fmt.Println(d)
+		}
+		late := ""
+		if s.Late {
+			late = "  (ends after it is due)"
+		}
+		fmt.Printf("  %s-%s  #%-5d %s%s\n", s.Start.Format("15:04"), s.End.Format("15:04"), s.Task.ID, s.Task.Title, late)
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Println("Not scheduled")
+		for _, s := range plan.Skipped {
+			fmt.Printf("  #%-5d %s (%s)\n", s.Task.ID, s.Task.Title, s.Reason)
+		}
+	}
+	if len(plan.Slots) == 0 {
+		fmt.Println("Nothing to schedule")
+		return
+	}
+
+	if !*yes {
+		fmt.Printf("Schedule %d tasks? [y/N] ", len(plan.Slots))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing scheduled")
+			return
+		}
+	}
+	n, err := schedule.Apply(client, plan.Slots)
+	if err != nil {
+		fatal(fmt.Errorf("scheduled %d of %d: %w", n, len(plan.Slots), err))
+	}
+	fmt.Printf("Scheduled %d tasks\n", n)
+}
+
+// printLogged reports the sessions a stopped timer logged
+func printLogged(sessions []timelog.Session) {
+	if len(sessions) == 0 {
+		return
+	}
+	var total time.Duration
+	for _, s := range sessions {
+		total += s.Duration()
+	}
+	fmt.Printf("Logged %s on #%d: %s\n", timelog.Format(total), sessions[0].TaskID, sessions[0].Title)
+}
+
+// runDeps shows the tasks a task waits on and those waiting on it, or
+// adds or removes a link
+func runDeps(args []string) {
+	fs := newFlagSet("deps", "<task-id> [add|remove <blocker-id>]")
+	fs.Parse(args)
+	if fs.NArg() != 1 && fs.NArg() != 3 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	id := parseTaskID(fs.Arg(0))
+
+	client, cfg := setup()
+	links, err := deps.Load(cfg.DataDir)
+	if err != nil {
+		fatal(err)
+	}
+
+	if fs.NArg() == 3 {
+		blocker := parseTaskID(fs.Arg(2))
+		switch fs.Arg(1) {
+		case "add":
+			err = links.Add(id, blocker)
+		case "remove":
+			err = links.Remove(id, blocker)
+		default:
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	if !ensureAuth(client) {
+		return
+	}
+	line := func(id int) string {
+		t, err := client.GetTask(id)
+		if err != nil {
+			return fmt.Sprintf("#%d (%v)", id, err)
+		}
+		mark := "[ ]"
+		if t.Status == "done" {
+			mark = "[x]"
+		}
+		return fmt.Sprintf("%s #%d %s", mark, t.ID, t.Title)
+	}
+	fmt.Println(line(id))
+	var walk func(nodes []deps.Node, depth int)
+	walk = func(nodes []deps.Node, depth int) {
+		for _, n := range nodes {
+			fmt.Printf("%swaits on %s\n", strings.Repeat("  ", depth+1), line(n.ID))
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(links.Tree(id), 0)
+	for _, b := range links.Blocks(id) {
+		fmt.Printf("  blocks %s\n", line(b))
+	}
+}
+
+// unblocked returns the open tasks that waited on id and wait on nothing
+// else still open
+func unblocked(client *api.Client, links *deps.Store, id int) []api.Task {
+	open := func(id int) bool {
+		t, err := client.GetTask(id)
+		return err == nil && t.Status != "done"
+	}
+	var out []api.Task
+	for _, waiting := range links.Blocks(id) {
+		t, err := client.GetTask(waiting)
+		if err != nil || t.Status == "done" || links.Blocked(waiting, open) {
+			continue
+		}
+		out = append(out, *t)
+	}
+	return out
+}
+
+// runEdit opens a task as a document in $EDITOR and saves what changed. A
+// document with mistakes, or one that fails to save, is reopened with the
+// problems noted at the top.
+func runEdit(args []string) {
+	fs := newFlagSet("edit", "<id>")
+	printOnly := fs.Bool("print", false, "Write the document to stdout instead of editing it")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	id := parseTaskID(fs.Arg(0))
+
+	client, _ := setup()
+	if !ensureAuth(client) {
+		return
+	}
+	task, err := client.GetTask(id)
+	if err != nil {
+		fatal(err)
+	}
+	text := taskdoc.Marshal(*task)
+	if *printOnly {
+		fmt.Print(text)
+		return
+	}
+
+	for {
+		edited, err := editText(text)
+		if err != nil {
+			fatal(err)
+		}
+		if strings.TrimSpace(edited) == "" || edited == strings.TrimSuffix(text, "\n") {
+			fmt.Println("No changes")
+			return
+		}
+		doc, err := taskdoc.Parse(edited)
+		var plan taskdoc.Plan
+		if err == nil {
+			plan, err = taskdoc.Diff(*task, doc)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			text = taskdoc.Annotate(edited, err)
+			continue
+		}
+		if plan.Empty() {
+			fmt.Println("No changes")
+			return
+		}
+
+		categories, err := client.ListCategories()
+		if err != nil {
+			fatal(err)
+		}
+		if _, err := taskdoc.Apply(client, *task, plan, categories); err != nil {
+			// Some changes may have been saved before the failure, so the
+			// next attempt is compared with the task as it is now
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			fresh, getErr := client.GetTask(id)
+			if getErr != nil {
+				fatal(getErr)
+			}
+			task = fresh
+			text = taskdoc.Annotate(edited, err)
+			continue
+		}
+		fmt.Printf("Updated task #%d: %s\n", id, plan.Summary())
+		return
+	}
+}
+
+// editText runs the editor on text and returns the result
+func editText(text string) (string, error) {
+	path, err := editor.TempFile("todo-task-*.md", text)
+	if err != nil {
+		return "", err
+	}
+	cmd, err := editor.Command(path)
+	if err != nil {
+		editor.ReadBack(path)
+		return "", err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		editor.ReadBack(path)
+		return "", err
+	}
+	return editor.ReadBack(path)
+}
+
+// fatal prints an error and exits
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
@@ -7,29 +7,40 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
 	"github.com/blackraven/todo-tui/internal/config"
 	"github.com/blackraven/todo-tui/internal/models"
 	"github.com/blackraven/todo-tui/internal/styles"
 )
 
 func main() {
+	// Subcommands take their own flags
+	if len(os.Args) > 1 {
+		if cmd, ok := findCommand(os.Args[1]); ok {
+			cmd.run(os.Args[2:])
+			return
+		}
+	}
+
 	// Parse command line flags
 	newTask := flag.String("n", "", "Create a new task with the given title")
 	listTasks := flag.Bool("l", false, "List all open tasks")
 	deleteTask := flag.Int("d", 0, "Delete a task by ID")
+	demo := flag.Bool("demo", false, "Run the TUI against an in-memory server with sample data")
+	profile := flag.String("profile", "", "Use a named profile with its own login and settings")
+	screenReader := flag.Bool("screen-reader", false, "Print changes as plain lines of text instead of redrawing the screen")
+	flag.Usage = usage
 	flag.Parse()
 
-	// Load configuration
-	cfg := config.Load()
-
-	// Ensure data directory exists
-	if err := config.EnsureDataDir(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating data directory: %v\n", err)
-		os.Exit(1)
+	if *demo {
+		runDemo(*screenReader)
+		return
 	}
 
-	// Create API client
-	client := api.NewClient(cfg)
+	client, cfg := setupProfile(*profile, "")
+	if *screenReader {
+		cfg.ScreenReader = true
+	}
 
 	// Handle CLI modes
 	if *newTask != "" {
@@ -47,24 +58,96 @@ func main() {
 		return
 	}
 
+	runTUI(client, cfg)
+}
+
+// setup loads the default configuration and creates the API client
+func setup() (*api.Client, *config.Config) {
+	return setupProfile("", "")
+}
+
+// setupProfile loads the configuration for a profile and creates the API
+// client. A non-empty server overrides the configured API URL.
+func setupProfile(profile, server string) (*api.Client, *config.Config) {
+	// Load configuration
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config file: %v\n", err)
+	}
+	if server != "" {
+		cfg.APIURL = server
+	}
+
+	// Ensure data directory exists
+	if err := os.MkdirAll(cfg.DataDir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating data directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create API client
+	return api.NewClient(cfg), cfg
+}
+
+// runTUI starts the interactive interface
+func runTUI(client *api.Client, cfg *config.Config) {
 	// Initialize styles
 	styles.Init()
 
 	// Create initial model
-	model := models.NewModel(client)
-
-	// Create Bubble Tea program with alt screen
-	p := tea.NewProgram(
-		model,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	model := models.NewModel(client, cfg)
+
+	// Create Bubble Tea program with alt screen, or printing lines for
+	// screen readers
+	var p *tea.Program
+	if cfg.ScreenReader {
+		p = tea.NewProgram(models.NewPlain(model))
+	} else {
+		p = tea.NewProgram(
+			model,
+			tea.WithAltScreen(),
+			tea.WithMouseCellMotion(),
+		)
+	}
 
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	switch m := final.(type) {
+	case models.Model:
+		m.Close()
+	case models.Plain:
+		m.Close()
+	}
+}
+
+// runDemo runs the TUI against a seeded in-memory server. Tokens and
+// credentials go to a temporary directory so the real ones are untouched.
+func runDemo(screenReader bool) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.Seed()
+
+	dataDir, err := os.MkdirTemp("", config.AppName+"-demo-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating demo directory: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dataDir)
+
+	cfg := config.ForDataDir(dataDir)
+	cfg.APIURL = srv.URL()
+	cfg.ScreenReader = screenReader
+
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		fmt.Fprintf(os.Stderr, "Error logging in to demo server: %v\n", err)
+		os.Exit(1)
+	}
+
+	runTUI(client, cfg)
 }
 
 // createTaskFromCLI creates a task directly from command line
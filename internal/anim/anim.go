@@ -0,0 +1,206 @@
+// Package anim holds the animations played on a task's title when it is
+// completed, and picks which one to play.
+package anim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// DefaultDuration is how long most animations run
+const DefaultDuration = 290 * time.Millisecond
+
+// Animation draws a title as it is being completed
+type Animation interface {
+	Name() string
+	Duration() time.Duration
+	// Frame renders text at a point between 0 (start) and 1 (end)
+	Frame(text string, progress float64, theme themes.Theme) string
+}
+
+// Func makes an Animation out of a render function
+type Func struct {
+	ID     string
+	Length time.Duration
+	Render func(text string, progress float64, theme themes.Theme) string
+}
+
+func (f Func) Name() string            { return f.ID }
+func (f Func) Duration() time.Duration { return f.Length }
+
+func (f Func) Frame(text string, progress float64, theme themes.Theme) string {
+	return f.Render(text, clamp(progress), theme)
+}
+
+// frame is what the built-in animations draw from: elapsed is in seconds
+// and r is seeded afresh for every frame
+type frame struct {
+	text     string
+	progress float64
+	elapsed  float64
+	theme    themes.Theme
+	r        *rand.Rand
+}
+
+type builtin struct {
+	name     string
+	duration time.Duration
+	draw     func(f frame) string
+}
+
+func (b builtin) Name() string            { return b.name }
+func (b builtin) Duration() time.Duration { return b.duration }
+
+func (b builtin) Frame(text string, progress float64, theme themes.Theme) string {
+	progress = clamp(progress)
+	return b.draw(frame{
+		text:     text,
+		progress: progress,
+		elapsed:  progress * b.duration.Seconds(),
+		theme:    theme,
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	})
+}
+
+func clamp(progress float64) float64 {
+	return min(max(progress, 0), 1)
+}
+
+// Registry is a set of animations with unique names, in the order they
+// were registered
+type Registry struct {
+	list []Animation
+}
+
+// Default holds the built-in animations
+var Default = NewRegistry()
+
+// NewRegistry returns a registry holding the built-in animations
+func NewRegistry() *Registry {
+	r := &Registry{}
+	for _, b := range builtins {
+		r.list = append(r.list, b)
+	}
+	return r
+}
+
+// Register adds an animation; names must be unique, ignoring case
+func (r *Registry) Register(a Animation) error {
+	if _, ok := r.Get(a.Name()); ok {
+		return fmt.Errorf("animation %q is already registered", a.Name())
+	}
+	r.list = append(r.list, a)
+	return nil
+}
+
+// Get finds an animation by name, ignoring case
+func (r *Registry) Get(name string) (Animation, bool) {
+	for _, a := range r.list {
+		if strings.EqualFold(a.Name(), name) {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// All returns the animations in registration order
+func (r *Registry) All() []Animation {
+	return append([]Animation(nil), r.list...)
+}
+
+// Names returns the animation names in registration order
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.list))
+	for i, a := range r.list {
+		names[i] = a.Name()
+	}
+	return names
+}
+
+// Picker chooses animations at random in proportion to their weights
+type Picker struct {
+	anims   []Animation
+	weights []int
+	total   int
+	last    string
+	rand    *rand.Rand
+}
+
+// Picker returns a picker over the registry. weights maps names to how
+// often each is played relative to the others; unlisted animations have a
+// weight of 1 and 0 turns one off. Unknown names are an error.
+func (r *Registry) Picker(weights map[string]int) (*Picker, error) {
+	p := &Picker{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	var unknown []string
+	for name, w := range weights {
+		if _, ok := r.Get(name); !ok {
+			unknown = append(unknown, name)
+		} else if w < 0 {
+			return nil, fmt.Errorf("animation %q: weight %d is negative", name, w)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown animations: %s", strings.Join(unknown, ", "))
+	}
+
+	for _, a := range r.list {
+		w := 1
+		for name, v := range weights {
+			if strings.EqualFold(name, a.Name()) {
+				w = v
+			}
+		}
+		if w > 0 {
+			p.anims = append(p.anims, a)
+			p.weights = append(p.weights, w)
+			p.total += w
+		}
+	}
+	return p, nil
+}
+
+// Weight returns how often an animation is played relative to the others
+func (p *Picker) Weight(name string) int {
+	for i, a := range p.anims {
+		if strings.EqualFold(a.Name(), name) {
+			return p.weights[i]
+		}
+	}
+	return 0
+}
+
+// Next picks an animation, avoiding the one picked last time when there
+// is a choice. It returns nil when every animation is turned off.
+func (p *Picker) Next() Animation {
+	if p.total == 0 {
+		return nil
+	}
+	skip := -1
+	total := p.total
+	for i, a := range p.anims {
+		if a.Name() == p.last && len(p.anims) > 1 {
+			skip = i
+			total -= p.weights[i]
+		}
+	}
+	n := p.rand.Intn(total)
+	var a Animation
+	for i, w := range p.weights {
+		if i == skip {
+			continue
+		}
+		if n < w {
+			a = p.anims[i]
+			break
+		}
+		n -= w
+	}
+	p.last = a.Name()
+	return a
+}
@@ -0,0 +1,93 @@
+package anim
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+func TestBuiltins(t *testing.T) {
+	names := Default.Names()
+	if len(names) != 30 {
+		t.Fatalf("%d built-in animations, want 30", len(names))
+	}
+	seen := map[string]bool{}
+	for _, a := range Default.All() {
+		if seen[a.Name()] {
+			t.Errorf("duplicate name %q", a.Name())
+		}
+		seen[a.Name()] = true
+		if a.Duration() <= 0 {
+			t.Errorf("%s: duration %v", a.Name(), a.Duration())
+		}
+		// Out of range progress is clamped rather than breaking the frame
+		for _, p := range []float64{-1, 0, 0.5, 1, 2} {
+			if a.Frame("Write report", p, themes.All[0]) == "" {
+				t.Errorf("%s: empty frame at %v", a.Name(), p)
+			}
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(Func{ID: "Sparkle", Length: time.Second}); err == nil {
+		t.Error("registered a duplicate name")
+	}
+	pulse := Func{ID: "stars", Length: time.Second, Render: func(text string, progress float64, _ themes.Theme) string {
+		return strings.Repeat("*", int(progress*float64(len(text))))
+	}}
+	if err := r.Register(pulse); err != nil {
+		t.Fatal(err)
+	}
+	a, ok := r.Get("STARS")
+	if !ok || a.Frame("abcd", 2, themes.All[0]) != "****" {
+		t.Errorf("Get(STARS) = %v, %v", a, ok)
+	}
+	if _, ok := Default.Get("stars"); ok {
+		t.Error("registering changed the default registry")
+	}
+}
+
+func TestPicker(t *testing.T) {
+	if _, err := Default.Picker(map[string]int{"fireworks": 1, "confetti": 2}); err == nil ||
+		!strings.Contains(err.Error(), "confetti, fireworks") {
+		t.Errorf("unknown names: %v", err)
+	}
+	if _, err := Default.Picker(map[string]int{"matrix": -1}); err == nil {
+		t.Error("accepted a negative weight")
+	}
+
+	weights := map[string]int{}
+	for _, name := range Default.Names() {
+		weights[name] = 0
+	}
+	delete(weights, "matrix")
+	weights["Matrix"] = 3
+	weights["glitch"] = 1
+	p, err := Default.Picker(weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Weight("matrix") != 3 || p.Weight("sparkle") != 0 {
+		t.Errorf("weights matrix %d, sparkle %d", p.Weight("matrix"), p.Weight("sparkle"))
+	}
+	last := ""
+	for range 40 {
+		a := p.Next()
+		if a == nil || (a.Name() != "matrix" && a.Name() != "glitch") {
+			t.Fatalf("Next = %v", a)
+		}
+		if a.Name() == last {
+			t.Fatalf("%s picked twice in a row", last)
+		}
+		last = a.Name()
+	}
+
+	weights["Matrix"], weights["glitch"] = 0, 0
+	if p, _ = Default.Picker(weights); p.Next() != nil {
+		t.Error("Next with every animation off")
+	}
+}
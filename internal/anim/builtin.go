@@ -0,0 +1,399 @@
+package anim
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/styles"
+)
+
+// progressDuration is for the animations that sweep across the title, so
+// the sweep can be followed
+const progressDuration = 450 * time.Millisecond
+
+// builtins are the animations the app ships with
+var builtins = []builtin{
+	{"sparkle", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		chars := []string{"*", "+", ".", "x", "o"}
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.4 {
+				char := chars[r.Intn(len(chars))]
+				col := theme.Accent
+				if r.Intn(2) == 0 {
+					col = theme.Secondary
+				}
+				sb.WriteString(lipgloss.NewStyle().Foreground(col).Render(char))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"matrix", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		matrixChars := "H3LL0W0RLD$#@!%*&^"
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			char := string(matrixChars[r.Intn(len(matrixChars))])
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render(char))
+		}
+		return sb.String()
+	}},
+	{"wipe-right", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		idx := int(math.Floor(progress * float64(len(text))))
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if i < idx {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else if i == idx {
+				sb.WriteString(lipgloss.NewStyle().Background(theme.Secondary).Foreground(theme.Bg).Render(string(text[i])))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"wipe-left", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		idx := len(text) - 1 - int(math.Floor(progress*float64(len(text))))
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if i > idx {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else if i == idx {
+				sb.WriteString(lipgloss.NewStyle().Background(theme.Accent).Foreground(theme.Bg).Render(string(text[i])))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"rainbow", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		colors := []lipgloss.Color{theme.Accent, theme.Secondary, theme.Success, theme.Warning, "#FF0000", "#00FF00", "#0000FF"}
+		var sb strings.Builder
+		for _, char := range text {
+			c := colors[r.Intn(len(colors))]
+			sb.WriteString(lipgloss.NewStyle().Foreground(c).Render(string(char)))
+		}
+		return sb.String()
+	}},
+	{"wave", DefaultDuration, func(f frame) string {
+		text, theme, elapsed := f.text, f.theme, f.elapsed
+		colors := []lipgloss.Color{theme.Accent, theme.Secondary, theme.Success, theme.Fg}
+		offset := int(elapsed * 30)
+		var sb strings.Builder
+		for i, char := range text {
+			cIdx := (i + offset) % len(colors)
+			sb.WriteString(lipgloss.NewStyle().Foreground(colors[cIdx]).Render(string(char)))
+		}
+		return sb.String()
+	}},
+	{"binary", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			bit := "0"
+			if r.Intn(2) == 1 {
+				bit = "1"
+			}
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render(bit))
+		}
+		return sb.String()
+	}},
+	{"dissolve", progressDuration, func(f frame) string {
+		text, r, progress := f.text, f.r, f.progress
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float64() < progress*1.5 {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"flip", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		for _, char := range text {
+			s := string(char)
+			if r.Float32() < 0.3 {
+				if strings.ToUpper(s) == s {
+					s = strings.ToLower(s)
+				} else {
+					s = strings.ToUpper(s)
+				}
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(s))
+			} else {
+				sb.WriteString(s)
+			}
+		}
+		return sb.String()
+	}},
+	{"pulse", DefaultDuration, func(f frame) string {
+		text, theme, elapsed := f.text, f.theme, f.elapsed
+		var sb strings.Builder
+		phase := math.Sin(elapsed * 40)
+		col := theme.Fg
+		if phase > 0 {
+			col = theme.Accent
+		}
+		for _, char := range text {
+			sb.WriteString(lipgloss.NewStyle().Foreground(col).Bold(phase > 0).Render(string(char)))
+		}
+		return sb.String()
+	}},
+	{"typewriter", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		visibleChars := int(float64(len(text)) * progress)
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if i <= visibleChars {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render(string(text[i])))
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		return sb.String()
+	}},
+	{"particle", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.5 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render("."))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"redact", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		chars := []string{"#", "%", "@", "*"}
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.5 {
+				char := chars[r.Intn(len(chars))]
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Warning).Render(char))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"chaos", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		symbols := "!@#$%^&*()_+"
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.5 {
+				s := string(symbols[r.Intn(len(symbols))])
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(s))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"converge", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		var sb strings.Builder
+		mid := len(text) / 2
+		fill := int(float64(mid) * progress)
+		for i := 0; i < len(text); i++ {
+			if i < fill || i >= len(text)-fill {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"bounce", DefaultDuration, func(f frame) string {
+		text, theme, r, elapsed := f.text, f.theme, f.r, f.elapsed
+		var sb strings.Builder
+		for i, char := range text {
+			if r.Intn(2) == 0 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render(string(char)))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(string(char)))
+			}
+			if i%2 == int(elapsed*10)%2 {
+				sb.WriteString("")
+			}
+		}
+		return sb.String()
+	}},
+	{"spin", DefaultDuration, func(f frame) string {
+		text, theme, elapsed := f.text, f.theme, f.elapsed
+		spinners := []string{"-", "\\", "|", "/"}
+		spinIdx := int(elapsed*20) % 4
+		var sb strings.Builder
+		for range text {
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render(spinners[spinIdx]))
+		}
+		return sb.String()
+	}},
+	{"zipper", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		var sb strings.Builder
+		mid := len(text) / 2
+		zipperPos := int(progress * float64(mid))
+		for i := 0; i < len(text); i++ {
+			distFromEdge := i
+			if i >= mid {
+				distFromEdge = len(text) - 1 - i
+			}
+			if distFromEdge < zipperPos {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Background(theme.Accent).Foreground(theme.Bg).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"eraser", progressDuration, func(f frame) string {
+		text, theme, r, progress := f.text, f.theme, f.r, f.progress
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float64() < progress {
+				sb.WriteString(" ")
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"glitch", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		glitchChars := "!@#$%^&*<>?{}[]"
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.3 {
+				char := string(glitchChars[r.Intn(len(glitchChars))])
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Warning).Background(theme.Dim).Render(char))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"moons", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		phases := []string{"(", ")", "[", "]"}
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.3 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(phases[r.Intn(len(phases))]))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render(string(text[i])))
+			}
+		}
+		return sb.String()
+	}},
+	{"braille", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		brailleChars := ".:;|+=-_"
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.4 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render(string(brailleChars[r.Intn(len(brailleChars))])))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"hex", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		hexChars := "0123456789ABCDEF"
+		for i := 0; i < len(text); i++ {
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render(string(hexChars[r.Intn(len(hexChars))])))
+		}
+		return sb.String()
+	}},
+	{"reverse", DefaultDuration, func(f frame) string {
+		text, theme := f.text, f.theme
+		var sb strings.Builder
+		for i := len(text) - 1; i >= 0; i-- {
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Warning).Render(string(text[i])))
+		}
+		return sb.String()
+	}},
+	{"case-flip", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		for _, char := range text {
+			s := string(char)
+			if r.Intn(2) == 0 {
+				s = strings.ToUpper(s)
+			} else {
+				s = strings.ToLower(s)
+			}
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render(s))
+		}
+		return sb.String()
+	}},
+	{"wide", DefaultDuration, func(f frame) string {
+		text, theme := f.text, f.theme
+		var sb strings.Builder
+		for _, char := range text {
+			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(string(char) + " "))
+		}
+		return sb.String()
+	}},
+	{"traffic", DefaultDuration, func(f frame) string {
+		text, theme, elapsed := f.text, f.theme, f.elapsed
+		colors := []lipgloss.Color{theme.Warning, "#FFFF00", theme.Success}
+		cIdx := int(elapsed*10) % 3
+		return lipgloss.NewStyle().Foreground(colors[cIdx]).Render(text)
+	}},
+	{"center-strike", progressDuration, func(f frame) string {
+		text, progress := f.text, f.progress
+		var sb strings.Builder
+		mid := len(text) / 2
+		strikeWidth := int(progress * float64(mid))
+		for i := 0; i < len(text); i++ {
+			dist := int(math.Abs(float64(i - mid)))
+			if dist < strikeWidth {
+				sb.WriteString(styles.StrikeStyle.Render(string(text[i])))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+	{"loading", progressDuration, func(f frame) string {
+		text, theme, progress := f.text, f.theme, f.progress
+		var sb strings.Builder
+		fill := int(progress * float64(len(text)))
+		for i := 0; i < len(text); i++ {
+			if i < fill {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Success).Render("#"))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render("-"))
+			}
+		}
+		return sb.String()
+	}},
+	{"slider", DefaultDuration, func(f frame) string {
+		text, theme, r := f.text, f.theme, f.r
+		var sb strings.Builder
+		for i := 0; i < len(text); i++ {
+			if r.Float32() < 0.3 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Render("^"))
+			} else {
+				sb.WriteString(string(text[i]))
+			}
+		}
+		return sb.String()
+	}},
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -53,7 +54,7 @@ func (c *Client) loadToken() {
 // saveToken saves the JWT token to disk
 func (c *Client) saveToken(token string) error {
 	c.token = token
-	if err := config.EnsureDataDir(); err != nil {
+	if err := os.MkdirAll(filepath.Dir(c.tokenPath), 0700); err != nil {
 		return err
 	}
 	return os.WriteFile(c.tokenPath, []byte(token), 0600)
@@ -65,6 +66,11 @@ func (c *Client) clearToken() error {
 	return os.Remove(c.tokenPath)
 }
 
+// BaseURL returns the API server the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
 // HasToken returns true if a token is loaded
 func (c *Client) HasToken() bool {
 	return c.token != ""
@@ -82,7 +88,7 @@ func (c *Client) ClearToken() error {
 
 // SaveCredentials stores login credentials for auto-login
 func (c *Client) SaveCredentials(email, password string) error {
-	if err := config.EnsureDataDir(); err != nil {
+	if err := os.MkdirAll(filepath.Dir(c.credsPath), 0700); err != nil {
 		return err
 	}
 	creds := Credentials{Email: email, Password: password}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event types sent on the server's event stream
+const (
+	EventTaskCreated     = "task.created"
+	EventTaskUpdated     = "task.updated"
+	EventTaskDeleted     = "task.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+// ErrStreamUnsupported is returned when the server has no event stream
+var ErrStreamUnsupported = errors.New("server does not support event streaming")
+
+// Event is a change notification from the server. Task or Category is set
+// for created/updated events; ID is set for every event.
+type Event struct {
+	Type     string
+	ID       int
+	Task     *Task
+	Category *Category
+}
+
+// StreamStatus describes the state of an event subscription
+type StreamStatus int
+
+const (
+	StreamConnecting StreamStatus = iota
+	StreamConnected
+	StreamDisconnected
+	StreamUnsupported
+)
+
+// StreamUpdate is delivered on a subscription channel: either an event or
+// a change in connection status
+type StreamUpdate struct {
+	Event  *Event
+	Status StreamStatus
+	Err    error
+}
+
+// StreamOptions controls reconnection backoff
+type StreamOptions struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultStreamOptions are used by Subscribe when no options are given
+var DefaultStreamOptions = StreamOptions{
+	MinBackoff: time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// Subscription is a self-reconnecting event stream
+type Subscription struct {
+	C      <-chan StreamUpdate
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops the subscription and waits for it to shut down
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// Subscribe opens the event stream, reconnecting with exponential backoff
+// until closed. If the server reports that streaming is unsupported, a
+// StreamUnsupported update is sent and the channel is closed.
+func (c *Client) Subscribe(opts StreamOptions) *Subscription {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultStreamOptions.MinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan StreamUpdate, 16)
+	sub := &Subscription{C: ch, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(sub.done)
+		defer close(ch)
+
+		send := func(u StreamUpdate) bool {
+			select {
+			case ch <- u:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		backoff := opts.MinBackoff
+		for {
+			if !send(StreamUpdate{Status: StreamConnecting}) {
+				return
+			}
+			connected := false
+			err := c.StreamEvents(ctx, func() {
+				connected = true
+				backoff = opts.MinBackoff
+				send(StreamUpdate{Status: StreamConnected})
+			}, func(e Event) {
+				send(StreamUpdate{Event: &e, Status: StreamConnected})
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, ErrStreamUnsupported) {
+				send(StreamUpdate{Status: StreamUnsupported, Err: err})
+				return
+			}
+			if !send(StreamUpdate{Status: StreamDisconnected, Err: err}) {
+				return
+			}
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if !connected {
+				backoff *= 2
+				if backoff > opts.MaxBackoff {
+					backoff = opts.MaxBackoff
+				}
+			}
+		}
+	}()
+
+	return sub
+}
+
+// StreamEvents opens a single server-sent events connection to /events and
+// calls handle for each event until the stream ends or ctx is cancelled.
+// onConnect is called once the server has accepted the stream.
+func (c *Client) StreamEvents(ctx context.Context, onConnect func(), handle func(Event)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/events", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	// The regular client has a request timeout, which would cut the stream
+	stream := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return ErrStreamUnsupported
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return ErrStreamUnsupported
+	}
+
+	if onConnect != nil {
+		onConnect()
+	}
+	return readEvents(resp.Body, handle)
+}
+
+// readEvents parses a text/event-stream body
+func readEvents(r io.Reader, handle func(Event)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var eventType string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if eventType != "" && data.Len() > 0 {
+				if e, ok := decodeEvent(eventType, data.String()); ok {
+					handle(e)
+				}
+			}
+			eventType = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Comment, used as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// decodeEvent builds an Event from an SSE event type and JSON payload
+func decodeEvent(eventType, data string) (Event, bool) {
+	e := Event{Type: eventType}
+	switch eventType {
+	case EventTaskCreated, EventTaskUpdated:
+		var t Task
+		if json.Unmarshal([]byte(data), &t) != nil {
+			return e, false
+		}
+		e.ID = t.ID
+		e.Task = &t
+	case EventCategoryCreated, EventCategoryUpdated:
+		var cat Category
+		if json.Unmarshal([]byte(data), &cat) != nil {
+			return e, false
+		}
+		e.ID = cat.ID
+		e.Category = &cat
+	case EventTaskDeleted, EventCategoryDeleted:
+		var payload struct {
+			ID int `json:"id"`
+		}
+		if json.Unmarshal([]byte(data), &payload) != nil {
+			return e, false
+		}
+		e.ID = payload.ID
+	default:
+		return e, false
+	}
+	return e, true
+}
@@ -0,0 +1,177 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+var fastBackoff = api.StreamOptions{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+// newStreamClient starts a fake server and returns a client logged in as
+// the demo user
+func newStreamClient(t *testing.T) (*fakeserver.Server, *api.Client) {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return srv, client
+}
+
+// next returns the next update on the subscription
+func next(t *testing.T, sub *api.Subscription) api.StreamUpdate {
+	t.Helper()
+	select {
+	case u, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for stream update")
+	}
+	return api.StreamUpdate{}
+}
+
+// waitStatus skips updates until the subscription reports the given status
+func waitStatus(t *testing.T, sub *api.Subscription, status api.StreamStatus) api.StreamUpdate {
+	t.Helper()
+	for {
+		u := next(t, sub)
+		if u.Event == nil && u.Status == status {
+			return u
+		}
+	}
+}
+
+// nextEvent skips status updates and returns the next event
+func nextEvent(t *testing.T, sub *api.Subscription) api.Event {
+	t.Helper()
+	for {
+		if u := next(t, sub); u.Event != nil {
+			return *u.Event
+		}
+	}
+}
+
+func TestSubscribeDeliversTaskEvents(t *testing.T) {
+	srv, client := newStreamClient(t)
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+	waitStatus(t, sub, api.StreamConnected)
+
+	created := srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Water plants"})
+	e := nextEvent(t, sub)
+	if e.Type != api.EventTaskCreated || e.Task == nil || e.Task.Title != "Water plants" {
+		t.Fatalf("got %+v, want task.created for Water plants", e)
+	}
+
+	srv.UpdateTask(created.ID, func(t *api.Task) { t.Priority = 8 })
+	e = nextEvent(t, sub)
+	if e.Type != api.EventTaskUpdated || e.Task.Priority != 8 {
+		t.Fatalf("got %+v, want task.updated with priority 8", e)
+	}
+
+	srv.DeleteTask(created.ID)
+	e = nextEvent(t, sub)
+	if e.Type != api.EventTaskDeleted || e.ID != created.ID {
+		t.Fatalf("got %+v, want task.deleted for %d", e, created.ID)
+	}
+}
+
+func TestSubscribeDeliversCategoryEvents(t *testing.T) {
+	_, client := newStreamClient(t)
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+	waitStatus(t, sub, api.StreamConnected)
+
+	cat, err := client.CreateCategory("Garden", "#00FF00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := nextEvent(t, sub)
+	if e.Type != api.EventCategoryCreated || e.Category == nil || e.Category.Name != "Garden" {
+		t.Fatalf("got %+v, want category.created for Garden", e)
+	}
+
+	if err := client.DeleteCategory(cat.ID); err != nil {
+		t.Fatal(err)
+	}
+	e = nextEvent(t, sub)
+	if e.Type != api.EventCategoryDeleted || e.ID != cat.ID {
+		t.Fatalf("got %+v, want category.deleted for %d", e, cat.ID)
+	}
+}
+
+func TestSubscribeSkipsOtherUsersTasks(t *testing.T) {
+	srv, client := newStreamClient(t)
+	srv.AddUser(fakeserver.TeamEmail, "secret")
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+	waitStatus(t, sub, api.StreamConnected)
+
+	srv.AddTask(fakeserver.TeamEmail, api.Task{Title: "Private"})
+	srv.AddTask(fakeserver.TeamEmail, api.Task{Title: "Shared",
+		SharedWith: []api.ShareInfo{{Email: fakeserver.DemoEmail}}})
+
+	e := nextEvent(t, sub)
+	if e.Task == nil || e.Task.Title != "Shared" {
+		t.Fatalf("got %+v, want only the shared task", e)
+	}
+}
+
+func TestSubscribeReconnectsAfterDrop(t *testing.T) {
+	srv, client := newStreamClient(t)
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+	waitStatus(t, sub, api.StreamConnected)
+
+	srv.DropStreams()
+	waitStatus(t, sub, api.StreamDisconnected)
+	waitStatus(t, sub, api.StreamConnected)
+
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "After reconnect"})
+	if e := nextEvent(t, sub); e.Task == nil || e.Task.Title != "After reconnect" {
+		t.Fatalf("got %+v, want event after reconnect", e)
+	}
+}
+
+func TestSubscribeRetriesServerErrors(t *testing.T) {
+	srv, client := newStreamClient(t)
+	srv.InjectFault(fakeserver.Fault{PathPrefix: "/events", Status: 503, Count: 2})
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+
+	u := waitStatus(t, sub, api.StreamDisconnected)
+	if u.Err == nil {
+		t.Error("disconnect should carry the error")
+	}
+	waitStatus(t, sub, api.StreamConnected)
+}
+
+func TestSubscribeReportsUnsupported(t *testing.T) {
+	srv, client := newStreamClient(t)
+	srv.DisableEvents()
+	sub := client.Subscribe(fastBackoff)
+	defer sub.Close()
+
+	waitStatus(t, sub, api.StreamUnsupported)
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Fatal("subscription should close after reporting unsupported")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription did not close")
+	}
+}
@@ -0,0 +1,134 @@
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// subscriber is a connected event stream
+type subscriber struct {
+	userID int
+	events chan streamEvent
+	drop   chan struct{}
+}
+
+// streamEvent is one server-sent event
+type streamEvent struct {
+	name string
+	data []byte
+}
+
+// DisableEvents makes /events answer 404, as a server without streaming
+// support would. Connected streams are dropped.
+func (s *Server) DisableEvents() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventsDisabled = true
+	s.dropStreams()
+}
+
+// DropStreams disconnects every open event stream, as a network failure would
+func (s *Server) DropStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropStreams()
+}
+
+// Streams returns the number of connected event streams
+func (s *Server) Streams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+func (s *Server) dropStreams() {
+	for sub := range s.subscribers {
+		close(sub.drop)
+		delete(s.subscribers, sub)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request, userID int) {
+	flusher, ok := w.(http.Flusher)
+	s.mu.Lock()
+	if s.eventsDisabled || s.closed || !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	sub := &subscriber{userID: userID, events: make(chan streamEvent, 64), drop: make(chan struct{})}
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case e := <-sub.events:
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data)
+			flusher.Flush()
+		case <-sub.drop:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// publish queues an event for the subscribers accepted by want. The caller
+// holds s.mu. A subscriber that has fallen too far behind misses events.
+func (s *Server) publish(name string, want func(userID int) bool, payload func(userID int) interface{}) {
+	for sub := range s.subscribers {
+		if !want(sub.userID) {
+			continue
+		}
+		data, err := json.Marshal(payload(sub.userID))
+		if err != nil {
+			continue
+		}
+		select {
+		case sub.events <- streamEvent{name: name, data: data}:
+		default:
+		}
+	}
+}
+
+// publishTask sends a task created/updated event to everyone who can see it
+func (s *Server) publishTask(name string, t *task) {
+	s.publish(name,
+		func(userID int) bool { return s.visible(t, userID) },
+		func(userID int) interface{} { return s.render(t, userID) })
+}
+
+// publishTaskDeleted announces a deletion; call it before removing the task
+func (s *Server) publishTaskDeleted(t *task) {
+	s.publish(api.EventTaskDeleted,
+		func(userID int) bool { return s.visible(t, userID) },
+		func(int) interface{} { return map[string]int{"id": t.ID} })
+}
+
+// publishCategory sends a category event to the category's owner
+func (s *Server) publishCategory(name string, cat *api.Category) {
+	owner := s.catOwners[cat.ID]
+	s.publish(name,
+		func(userID int) bool { return userID == owner },
+		func(int) interface{} {
+			if name == api.EventCategoryDeleted {
+				return map[string]int{"id": cat.ID}
+			}
+			return cat
+		})
+}
@@ -0,0 +1,465 @@
+// Package fakeserver provides an in-memory implementation of the todo API
+// served over httptest. It is used by tests and by the --demo mode.
+package fakeserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Fault describes an injected failure for matching requests
+type Fault struct {
+	Method     string // empty matches any method
+	PathPrefix string // empty matches any path
+	Status     int    // HTTP status to return
+	Count      int    // number of requests to fail, 0 means until cleared
+}
+
+// user is a registered account
+type user struct {
+	ID       int
+	Email    string
+	Password string
+	IsAdmin  bool
+}
+
+// task is a stored task together with its owner
+type task struct {
+	api.Task
+	OwnerID int
+}
+
+// setStatus changes the status, stamping or clearing the completion time
+func (t *task) setStatus(status string) {
+	if status == "done" && t.Status != "done" {
+		now := time.Now()
+		t.CompletedAt = &now
+	} else if status != "done" {
+		t.CompletedAt = nil
+	}
+	t.Status = status
+}
+
+// Server is an in-memory todo API server
+type Server struct {
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	users      map[string]*user
+	tokens     map[string]int
+	tasks      map[int]*task
+	categories map[int]*api.Category
+	catOwners  map[int]int
+	nextID     int
+
+	latency time.Duration
+	faults  []*Fault
+
+	subscribers    map[*subscriber]struct{}
+	eventsDisabled bool
+	closed         bool
+}
+
+// New starts a new fake server
+func New() *Server {
+	s := &Server{
+		users:      make(map[string]*user),
+		tokens:     make(map[string]int),
+		tasks:      make(map[int]*task),
+		categories: make(map[int]*api.Category),
+		catOwners:  make(map[int]int),
+		nextID:     1,
+
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	s.srv = httptest.NewServer(s.routes())
+	return s
+}
+
+// URL returns the base URL of the server, suitable for config.APIURL
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Close shuts the server down. Open event streams are dropped first, since
+// httptest waits for outstanding requests to finish.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.dropStreams()
+	s.mu.Unlock()
+	s.srv.Close()
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFault adds a failure rule; rules are checked in order
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// FailNext makes the next n requests fail with the given status
+func (s *Server) FailNext(n, status int) {
+	s.InjectFault(Fault{Status: status, Count: n})
+}
+
+// ClearFaults removes all injected faults and latency
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.latency = 0
+}
+
+// ExpireTokens invalidates every issued token so subsequent calls get 401
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]int)
+}
+
+// AddUser registers an account and returns its ID
+func (s *Server) AddUser(email, password string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(email, password)
+}
+
+// IssueToken returns a valid token for the given account
+func (s *Server) IssueToken(email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[email]
+	if !ok {
+		return ""
+	}
+	return s.issueToken(u.ID)
+}
+
+// AddCategory stores a category owned by the given account
+func (s *Server) AddCategory(email, name, color string) api.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat := api.Category{ID: s.newID(), Name: name, Color: color, CreatedAt: time.Now()}
+	s.categories[cat.ID] = &cat
+	s.catOwners[cat.ID] = s.users[email].ID
+	s.publishCategory(api.EventCategoryCreated, &cat)
+	return cat
+}
+
+// AddTask stores a task owned by the given account. The ID, subtask IDs and
+// ownership fields are assigned by the server.
+func (s *Server) AddTask(email string, t api.Task) api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.ID = s.newID()
+	if t.Status == "" {
+		t.Status = "open"
+	}
+	if t.CreatedAt == nil {
+		now := time.Now()
+		t.CreatedAt = &now
+	}
+	if t.Status == "done" && t.CompletedAt == nil {
+		t.CompletedAt = t.CreatedAt
+	}
+	for i := range t.Subtasks {
+		t.Subtasks[i].ID = s.newID()
+		t.Subtasks[i].Sort = i
+		if t.Subtasks[i].Status == "" {
+			t.Subtasks[i].Status = "open"
+		}
+	}
+	s.tasks[t.ID] = &task{Task: t, OwnerID: s.users[email].ID}
+	s.publishTask(api.EventTaskCreated, s.tasks[t.ID])
+	return s.render(s.tasks[t.ID], s.users[email].ID)
+}
+
+// UpdateTask applies fn to the stored task, as if another client had edited
+// it. It returns false if the task does not exist.
+func (s *Server) UpdateTask(id int, fn func(t *api.Task)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return false
+	}
+	fn(&t.Task)
+	s.publishTask(api.EventTaskUpdated, t)
+	return true
+}
+
+// DeleteTask removes a task, as if another client had deleted it
+func (s *Server) DeleteTask(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return false
+	}
+	s.publishTaskDeleted(t)
+	delete(s.tasks, id)
+	return true
+}
+
+// Tasks returns a snapshot of every stored task ordered by ID
+func (s *Server) Tasks() []api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []api.Task
+	for _, t := range s.sortedTasks() {
+		out = append(out, s.render(t, t.OwnerID))
+	}
+	return out
+}
+
+// Categories returns a snapshot of every stored category ordered by ID
+func (s *Server) Categories() []api.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedCategories()
+}
+
+func (s *Server) addUser(email, password string) int {
+	if u, ok := s.users[email]; ok {
+		return u.ID
+	}
+	u := &user{ID: s.newID(), Email: email, Password: password}
+	s.users[email] = u
+	return u.ID
+}
+
+func (s *Server) issueToken(userID int) string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	s.tokens[token] = userID
+	return token
+}
+
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *Server) userByID(id int) *user {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) sortedTasks() []*task {
+	out := make([]*task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (s *Server) sortedCategories() []api.Category {
+	out := make([]api.Category, 0, len(s.categories))
+	for _, c := range s.categories {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// render produces the API view of a task for the given viewer
+func (s *Server) render(t *task, viewerID int) api.Task {
+	out := t.Task
+	out.Subtasks = append([]api.Subtask(nil), t.Subtasks...)
+	out.Tags = append([]string(nil), t.Tags...)
+	out.SharedWith = append([]api.ShareInfo(nil), t.SharedWith...)
+	if out.Subtasks == nil {
+		out.Subtasks = []api.Subtask{}
+	}
+	sort.Slice(out.Subtasks, func(i, j int) bool { return out.Subtasks[i].Sort < out.Subtasks[j].Sort })
+
+	out.Category = nil
+	if t.CategoryID != nil {
+		if cat, ok := s.categories[*t.CategoryID]; ok {
+			c := *cat
+			out.Category = &c
+		}
+	}
+
+	isOwner := t.OwnerID == viewerID
+	out.IsOwner = &isOwner
+	out.CanComplete = boolPtr(true)
+	out.CanDelete = boolPtr(isOwner)
+	out.CanShare = boolPtr(isOwner)
+	out.OwnerEmail = nil
+	if owner := s.userByID(t.OwnerID); owner != nil {
+		email := owner.Email
+		out.OwnerEmail = &email
+	}
+	return out
+}
+
+// visible reports whether the viewer can see the task
+func (s *Server) visible(t *task, viewerID int) bool {
+	return t.OwnerID == viewerID || s.sharedWith(t, viewerID)
+}
+
+func (s *Server) sharedWith(t *task, viewerID int) bool {
+	viewer := s.userByID(viewerID)
+	if viewer == nil {
+		return false
+	}
+	for _, sh := range t.SharedWith {
+		if sh.Email == viewer.Email {
+			return true
+		}
+	}
+	return false
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// Handler plumbing
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, userID int)
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /auth/login", s.handleLogin)
+	mux.HandleFunc("POST /auth/register", s.handleRegister)
+	mux.HandleFunc("POST /auth/logout", s.authed(s.handleLogout))
+	mux.HandleFunc("GET /auth/me", s.authed(s.handleMe))
+
+	mux.HandleFunc("GET /tasks", s.authed(s.handleListTasks))
+	mux.HandleFunc("POST /tasks", s.authed(s.handleCreateTask))
+	mux.HandleFunc("GET /tasks/{id}", s.authed(s.handleGetTask))
+	mux.HandleFunc("PATCH /tasks/{id}", s.authed(s.handleUpdateTask))
+	mux.HandleFunc("DELETE /tasks/{id}", s.authed(s.handleDeleteTask))
+	mux.HandleFunc("POST /tasks/{id}/complete", s.authed(s.handleCompleteTask))
+	mux.HandleFunc("POST /tasks/{id}/breakdown", s.authed(s.handleBreakdown))
+	mux.HandleFunc("POST /tasks/{id}/subtasks", s.authed(s.handleCreateSubtask))
+	mux.HandleFunc("PATCH /tasks/subtasks/{id}", s.authed(s.handleUpdateSubtask))
+	mux.HandleFunc("DELETE /tasks/subtasks/{id}", s.authed(s.handleDeleteSubtask))
+
+	mux.HandleFunc("GET /categories", s.authed(s.handleListCategories))
+	mux.HandleFunc("POST /categories", s.authed(s.handleCreateCategory))
+	mux.HandleFunc("GET /categories/{id}", s.authed(s.handleGetCategory))
+	mux.HandleFunc("PATCH /categories/{id}", s.authed(s.handleUpdateCategory))
+	mux.HandleFunc("DELETE /categories/{id}", s.authed(s.handleDeleteCategory))
+
+	mux.HandleFunc("GET /events", s.authed(s.handleEvents))
+
+	return s.withFaults(mux)
+}
+
+// withFaults applies latency and injected failures before dispatching
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		latency := s.latency
+		status := 0
+		for i, f := range s.faults {
+			if f.Method != "" && f.Method != r.Method {
+				continue
+			}
+			if f.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, f.PathPrefix) {
+				continue
+			}
+			status = f.Status
+			if f.Count > 0 {
+				f.Count--
+				if f.Count == 0 {
+					s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				}
+			}
+			break
+		}
+		s.mu.Unlock()
+
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+		if status != 0 {
+			writeError(w, status, http.StatusText(status))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authed resolves the bearer token and rejects unauthenticated requests
+func (s *Server) authed(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		userID, ok := s.tokens[token]
+		s.mu.Unlock()
+		if token == "" || !ok {
+			writeError(w, http.StatusUnauthorized, "Not authenticated")
+			return
+		}
+		h(w, r, userID)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
+
+func decode(r *http.Request, v interface{}) bool {
+	if r.Body == nil {
+		return false
+	}
+	return json.NewDecoder(r.Body).Decode(v) == nil
+}
+
+func pathID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	return id, err == nil
+}
+
+// lookupTask finds a task visible to the user, writing a 404 if missing
+func (s *Server) lookupTask(w http.ResponseWriter, r *http.Request, userID int) *task {
+	id, ok := pathID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Task not found")
+		return nil
+	}
+	t, ok := s.tasks[id]
+	if !ok || !s.visible(t, userID) {
+		writeError(w, http.StatusNotFound, "Task not found")
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,439 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Auth endpoints
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req api.AuthRequest
+	if !decode(r, &req) {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[req.Email]
+	if !ok || u.Password != req.Password {
+		writeError(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+	writeJSON(w, http.StatusOK, api.AuthResponse{OK: true, Token: s.issueToken(u.ID)})
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req api.AuthRequest
+	if !decode(r, &req) || req.Email == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "Email and password are required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.users[req.Email]; exists {
+		writeError(w, http.StatusConflict, "Email already registered")
+		return
+	}
+	id := s.addUser(req.Email, req.Password)
+	writeJSON(w, http.StatusOK, api.AuthResponse{OK: true, Token: s.issueToken(id)})
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request, userID int) {
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.userByID(userID)
+	if u == nil {
+		writeError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	writeJSON(w, http.StatusOK, api.UserInfo{ID: u.ID, Email: u.Email, IsAdmin: u.IsAdmin})
+}
+
+// Task endpoints
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request, userID int) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	scope := q.Get("scope")
+	search := strings.ToLower(q.Get("search"))
+	var categoryID *int
+	if v := q.Get("category_id"); v != "" {
+		if id, err := strconv.Atoi(v); err == nil {
+			categoryID = &id
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []api.Task{}
+	for _, t := range s.sortedTasks() {
+		if !s.visible(t, userID) {
+			continue
+		}
+		switch scope {
+		case "mine":
+			if t.OwnerID != userID {
+				continue
+			}
+		case "shared":
+			if !s.sharedWith(t, userID) && !(t.OwnerID == userID && len(t.SharedWith) > 0) {
+				continue
+			}
+		}
+		if status != "" && t.Status != status {
+			continue
+		}
+		if categoryID != nil && (t.CategoryID == nil || *t.CategoryID != *categoryID) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(t.Title), search) {
+			continue
+		}
+		tasks = append(tasks, s.render(t, userID))
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.TaskCreateRequest
+	if !decode(r, &req) || strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Title is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &task{OwnerID: userID}
+	t.ID = s.newID()
+	t.Title = req.Title
+	t.Notes = req.Notes
+	t.Status = "open"
+	now := time.Now()
+	t.CreatedAt = &now
+	t.DueAt = req.DueAt
+	t.CategoryID = req.CategoryID
+	t.Tags = req.Tags
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.EffortMin != nil {
+		t.EffortMin = *req.EffortMin
+	}
+	if req.GenerateSubtasks != nil && *req.GenerateSubtasks {
+		s.breakdown(t)
+	}
+	s.tasks[t.ID] = t
+	s.publishTask(api.EventTaskCreated, t)
+	writeJSON(w, http.StatusCreated, s.render(t, userID))
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.lookupTask(w, r, userID); t != nil {
+		writeJSON(w, http.StatusOK, s.render(t, userID))
+	}
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.TaskUpdateRequest
+	if !decode(r, &req) {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.lookupTask(w, r, userID)
+	if t == nil {
+		return
+	}
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			writeError(w, http.StatusUnprocessableEntity, "Title is required")
+			return
+		}
+		t.Title = *req.Title
+	}
+	if req.Notes != nil {
+		t.Notes = req.Notes
+	}
+	if req.Status != nil {
+		if *req.Status != "open" && *req.Status != "done" {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid status")
+			return
+		}
+		t.setStatus(*req.Status)
+	}
+	if req.DueAt != nil {
+		t.DueAt = req.DueAt
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.EffortMin != nil {
+		t.EffortMin = *req.EffortMin
+	}
+	if req.CategoryID != nil {
+		if _, ok := s.categories[*req.CategoryID]; !ok {
+			writeError(w, http.StatusUnprocessableEntity, "Category not found")
+			return
+		}
+		t.CategoryID = req.CategoryID
+	}
+	if req.NotificationsEnabled != nil {
+		t.NotificationsEnabled = *req.NotificationsEnabled
+	}
+	if req.ScheduledStart != nil {
+		t.ScheduledStart = req.ScheduledStart
+	}
+	if req.ScheduledEnd != nil {
+		t.ScheduledEnd = req.ScheduledEnd
+	}
+	if req.AutoScheduled != nil {
+		t.AutoScheduled = *req.AutoScheduled
+	}
+	if req.Tags != nil {
+		t.Tags = append([]string(nil), (*req.Tags)...)
+	}
+	s.publishTask(api.EventTaskUpdated, t)
+	writeJSON(w, http.StatusOK, s.render(t, userID))
+}
+
+func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookupTask(w, r, userID)
+	if t == nil {
+		return
+	}
+	if t.OwnerID != userID {
+		writeError(w, http.StatusForbidden, "Only the owner can delete this task")
+		return
+	}
+	s.publishTaskDeleted(t)
+	delete(s.tasks, t.ID)
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func (s *Server) handleCompleteTask(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.lookupTask(w, r, userID); t != nil {
+		t.setStatus("done")
+		s.publishTask(api.EventTaskUpdated, t)
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	}
+}
+
+func (s *Server) handleBreakdown(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.lookupTask(w, r, userID); t != nil {
+		s.breakdown(t)
+		s.publishTask(api.EventTaskUpdated, t)
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	}
+}
+
+// breakdown appends deterministic "AI" subtasks to a task
+func (s *Server) breakdown(t *task) {
+	steps := []string{"Plan", "Do", "Review"}
+	for _, step := range steps {
+		t.Subtasks = append(t.Subtasks, api.Subtask{
+			ID:     s.newID(),
+			Title:  fmt.Sprintf("%s: %s", step, t.Title),
+			Status: "open",
+			Sort:   len(t.Subtasks),
+		})
+	}
+}
+
+func (s *Server) handleCreateSubtask(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.SubtaskCreateRequest
+	if !decode(r, &req) || strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Title is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookupTask(w, r, userID)
+	if t == nil {
+		return
+	}
+	st := api.Subtask{ID: s.newID(), Title: req.Title, Status: "open", Sort: len(t.Subtasks)}
+	t.Subtasks = append(t.Subtasks, st)
+	s.publishTask(api.EventTaskUpdated, t)
+	writeJSON(w, http.StatusCreated, st)
+}
+
+func (s *Server) handleUpdateSubtask(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.SubtaskUpdateRequest
+	if !decode(r, &req) {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Subtask not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if !s.visible(t, userID) {
+			continue
+		}
+		for i := range t.Subtasks {
+			st := &t.Subtasks[i]
+			if st.ID != id {
+				continue
+			}
+			if req.Title != nil {
+				st.Title = *req.Title
+			}
+			if req.Status != nil {
+				st.Status = *req.Status
+			}
+			if req.Sort != nil {
+				st.Sort = *req.Sort
+			}
+			s.publishTask(api.EventTaskUpdated, t)
+			writeJSON(w, http.StatusOK, *st)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Subtask not found")
+}
+
+func (s *Server) handleDeleteSubtask(w http.ResponseWriter, r *http.Request, userID int) {
+	id, ok := pathID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Subtask not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if !s.visible(t, userID) {
+			continue
+		}
+		for i, st := range t.Subtasks {
+			if st.ID != id {
+				continue
+			}
+			t.Subtasks = append(t.Subtasks[:i], t.Subtasks[i+1:]...)
+			s.publishTask(api.EventTaskUpdated, t)
+			writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Subtask not found")
+}
+
+// Category endpoints
+
+func (s *Server) handleListCategories(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	categories := []api.Category{}
+	for _, c := range s.sortedCategories() {
+		if s.catOwners[c.ID] == userID {
+			categories = append(categories, c)
+		}
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+func (s *Server) handleCreateCategory(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.CategoryCreateRequest
+	if !decode(r, &req) || strings.TrimSpace(req.Name) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Name is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat := &api.Category{ID: s.newID(), Name: req.Name, Color: req.Color, CreatedAt: time.Now()}
+	s.categories[cat.ID] = cat
+	s.catOwners[cat.ID] = userID
+	s.publishCategory(api.EventCategoryCreated, cat)
+	writeJSON(w, http.StatusCreated, cat)
+}
+
+func (s *Server) handleGetCategory(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cat := s.lookupCategory(w, r, userID); cat != nil {
+		writeJSON(w, http.StatusOK, cat)
+	}
+}
+
+func (s *Server) handleUpdateCategory(w http.ResponseWriter, r *http.Request, userID int) {
+	var req api.CategoryUpdateRequest
+	if !decode(r, &req) {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat := s.lookupCategory(w, r, userID)
+	if cat == nil {
+		return
+	}
+	if req.Name != nil {
+		cat.Name = *req.Name
+	}
+	if req.Color != nil {
+		cat.Color = *req.Color
+	}
+	s.publishCategory(api.EventCategoryUpdated, cat)
+	writeJSON(w, http.StatusOK, cat)
+}
+
+func (s *Server) handleDeleteCategory(w http.ResponseWriter, r *http.Request, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat := s.lookupCategory(w, r, userID)
+	if cat == nil {
+		return
+	}
+	s.publishCategory(api.EventCategoryDeleted, cat)
+	delete(s.categories, cat.ID)
+	delete(s.catOwners, cat.ID)
+	for _, t := range s.sortedTasks() {
+		if t.CategoryID != nil && *t.CategoryID == cat.ID {
+			t.CategoryID = nil
+			s.publishTask(api.EventTaskUpdated, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// lookupCategory finds a category owned by the user, writing a 404 if missing
+func (s *Server) lookupCategory(w http.ResponseWriter, r *http.Request, userID int) *api.Category {
+	id, ok := pathID(r)
+	if ok {
+		if cat, found := s.categories[id]; found && s.catOwners[id] == userID {
+			return cat
+		}
+	}
+	writeError(w, http.StatusNotFound, "Category not found")
+	return nil
+}
@@ -0,0 +1,101 @@
+package fakeserver
+
+import (
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Demo account credentials used by Seed and the --demo mode
+const (
+	DemoEmail    = "demo@example.com"
+	DemoPassword = "demo"
+	TeamEmail    = "teammate@example.com"
+)
+
+// Seed registers the demo accounts and fills them with sample data
+func (s *Server) Seed() {
+	s.AddUser(DemoEmail, DemoPassword)
+	s.AddUser(TeamEmail, DemoPassword)
+
+	work := s.AddCategory(DemoEmail, "Work", "#45B7D1")
+	home := s.AddCategory(DemoEmail, "Home", "#96CEB4")
+	errands := s.AddCategory(DemoEmail, "Errands", "#FFEAA7")
+
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d).Truncate(time.Minute)
+		return &t
+	}
+	str := func(v string) *string { return &v }
+	cat := func(c api.Category) *int { return &c.ID }
+
+	s.AddTask(DemoEmail, api.Task{
+		Title:      "Prepare quarterly report",
+		Notes:      str("Pull numbers from the dashboard and summarise the highlights."),
+		Priority:   9,
+		EffortMin:  120,
+		DueAt:      at(26 * time.Hour),
+		CategoryID: cat(work),
+		Tags:       []string{"reports"},
+		Subtasks: []api.Subtask{
+			{Title: "Export metrics"},
+			{Title: "Draft summary", Status: "done"},
+			{Title: "Send for review"},
+		},
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:      "Renew car insurance",
+		Priority:   7,
+		EffortMin:  30,
+		DueAt:      at(-30 * time.Hour),
+		CategoryID: cat(errands),
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:      "Fix the leaking kitchen tap",
+		Notes:      str("Washer size is 1/2 inch."),
+		Priority:   5,
+		EffortMin:  45,
+		CategoryID: cat(home),
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:      "Book dentist appointment",
+		Priority:   3,
+		EffortMin:  10,
+		DueAt:      at(5 * 24 * time.Hour),
+		CategoryID: cat(errands),
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:     "Read the new architecture proposal",
+		Priority:  4,
+		EffortMin: 60,
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:       "Water the plants",
+		Status:      "done",
+		Priority:    2,
+		CategoryID:  cat(home),
+		CreatedAt:   at(-3 * 24 * time.Hour),
+		CompletedAt: at(-2 * 24 * time.Hour),
+	})
+	s.AddTask(DemoEmail, api.Task{
+		Title:       "Submit expense claims",
+		Status:      "done",
+		Priority:    6,
+		CategoryID:  cat(work),
+		CreatedAt:   at(-9 * 24 * time.Hour),
+		CompletedAt: at(-5 * time.Hour),
+	})
+	s.AddTask(TeamEmail, api.Task{
+		Title:      "Plan the team offsite",
+		Notes:      str("Shortlist three venues and share the budget."),
+		Priority:   8,
+		EffortMin:  90,
+		DueAt:      at(10 * 24 * time.Hour),
+		SharedWith: []api.ShareInfo{{Email: DemoEmail}},
+		Subtasks: []api.Subtask{
+			{Title: "Collect availability"},
+			{Title: "Shortlist venues"},
+		},
+	})
+}
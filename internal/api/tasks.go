@@ -31,6 +31,8 @@ type Task struct {
 	SharedWith         []ShareInfo `json:"shared_with"`
 	OwnerEmail         *string    `json:"owner_email"`
 	CommentCount       int        `json:"comment_count"`
+	CreatedAt          *time.Time `json:"created_at"`
+	CompletedAt        *time.Time `json:"completed_at"`
 }
 
 // Subtask represents a subtask
@@ -57,6 +59,7 @@ type TaskCreateRequest struct {
 	EffortMin         *int       `json:"effort_min,omitempty"`
 	CategoryID        *int       `json:"category_id,omitempty"`
 	GenerateSubtasks  *bool      `json:"generate_subtasks,omitempty"`
+	Tags              []string   `json:"tags,omitempty"`
 }
 
 // TaskUpdateRequest represents an update task request
@@ -69,6 +72,10 @@ type TaskUpdateRequest struct {
 	EffortMin          *int       `json:"effort_min,omitempty"`
 	CategoryID         *int       `json:"category_id,omitempty"`
 	NotificationsEnabled *bool    `json:"notifications_enabled,omitempty"`
+	ScheduledStart     *time.Time `json:"scheduled_start,omitempty"`
+	ScheduledEnd       *time.Time `json:"scheduled_end,omitempty"`
+	AutoScheduled      *bool      `json:"auto_scheduled,omitempty"`
+	Tags               *[]string  `json:"tags,omitempty"`
 }
 
 // SubtaskCreateRequest represents a create subtask request
@@ -119,6 +126,21 @@ func (c *Client) ListTasks(params TaskListParams) ([]Task, error) {
 	return tasks, nil
 }
 
+// ListAllTasks fetches the open tasks and then the done ones in a scope.
+// The server's choice when no status is given is not specified, so each
+// status is asked for in turn.
+func (c *Client) ListAllTasks(scope string) ([]Task, error) {
+	var out []Task
+	for _, status := range []string{"open", "done"} {
+		tasks, err := c.ListTasks(TaskListParams{Status: status, Scope: scope})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tasks...)
+	}
+	return out, nil
+}
+
 // GetTask fetches a single task by ID
 func (c *Client) GetTask(id int) (*Task, error) {
 	var task Task
@@ -162,12 +184,21 @@ func (c *Client) BreakdownTask(id int) error {
 }
 
 // CreateSubtask creates a new subtask
-func (c *Client) CreateSubtask(taskID int, title string) error {
+func (c *Client) CreateSubtask(taskID int, title string) (*Subtask, error) {
 	req := SubtaskCreateRequest{Title: title}
-	return c.Post(fmt.Sprintf("/tasks/%d/subtasks", taskID), req, nil)
+	var subtask Subtask
+	if err := c.Post(fmt.Sprintf("/tasks/%d/subtasks", taskID), req, &subtask); err != nil {
+		return nil, err
+	}
+	return &subtask, nil
 }
 
 // UpdateSubtask updates an existing subtask
 func (c *Client) UpdateSubtask(subtaskID int, req SubtaskUpdateRequest) error {
 	return c.Patch(fmt.Sprintf("/tasks/subtasks/%d", subtaskID), req, nil)
 }
+
+// DeleteSubtask deletes a subtask
+func (c *Client) DeleteSubtask(subtaskID int) error {
+	return c.Delete(fmt.Sprintf("/tasks/subtasks/%d", subtaskID), nil)
+}
@@ -0,0 +1,22 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+)
+
+func TestListAllTasks(t *testing.T) {
+	srv, client := newStreamClient(t)
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Open one"})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Done one", Status: "done"})
+
+	tasks, err := client.ListAllTasks("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].Title != "Open one" || tasks[1].Title != "Done one" {
+		t.Errorf("tasks = %+v, want the open task then the done one", tasks)
+	}
+}
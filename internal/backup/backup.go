@@ -0,0 +1,186 @@
+// Package backup snapshots a whole account into a versioned archive and
+// recreates it, on the same or another server, from such an archive.
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Version is the current archive format version
+const Version = 1
+
+// DirName is the directory under the data directory that holds backups
+const DirName = "backups"
+
+// Archive is a snapshot of an account
+type Archive struct {
+	Version    int            `json:"version"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Server     string         `json:"server"`
+	Account    string         `json:"account"`
+	Categories []api.Category `json:"categories"`
+	Tasks      []api.Task     `json:"tasks"`
+}
+
+// Info describes a backup file on disk
+type Info struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Dir returns the backup directory within a data directory
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, DirName)
+}
+
+// Snapshot fetches every task the account can see, open, done and shared
+// with it, along with its categories
+func Snapshot(client *api.Client) (Archive, error) {
+	a := Archive{Version: Version, CreatedAt: time.Now().UTC(), Server: client.BaseURL()}
+
+	if user, err := client.GetCurrentUser(); err == nil {
+		a.Account = user.Email
+	}
+
+	seen := make(map[int]bool)
+	for _, params := range []api.TaskListParams{
+		{Status: "open", Scope: "all"},
+		{Status: "done", Scope: "all"},
+		{Scope: "shared"},
+	} {
+		tasks, err := client.ListTasks(params)
+		if err != nil {
+			return a, fmt.Errorf("listing tasks: %w", err)
+		}
+		for _, t := range tasks {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				a.Tasks = append(a.Tasks, t)
+			}
+		}
+	}
+	sort.Slice(a.Tasks, func(i, j int) bool { return a.Tasks[i].ID < a.Tasks[j].ID })
+
+	categories, err := client.ListCategories()
+	if err != nil {
+		return a, fmt.Errorf("listing categories: %w", err)
+	}
+	a.Categories = categories
+	if a.Tasks == nil {
+		a.Tasks = []api.Task{}
+	}
+	if a.Categories == nil {
+		a.Categories = []api.Category{}
+	}
+	return a, nil
+}
+
+// Save writes the archive as gzipped JSON into dir and returns its path
+func Save(dir string, a Archive) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	stamp := a.CreatedAt.Local().Format("20060102-150405")
+	path := filepath.Join(dir, "backup-"+stamp+".json.gz")
+	// Never overwrite an earlier backup taken in the same second
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("backup-%s-%d.json.gz", stamp, n))
+	}
+	return path, WriteFile(path, a)
+}
+
+// WriteFile writes the archive as gzipped JSON to path
+func WriteFile(path string, a Archive) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if err := json.NewEncoder(zw).Encode(a); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads an archive written by Save
+func Load(path string) (Archive, error) {
+	var a Archive
+	f, err := os.Open(path)
+	if err != nil {
+		return a, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return a, fmt.Errorf("%s: not a backup archive: %w", path, err)
+	}
+	defer zr.Close()
+	if err := json.NewDecoder(zr).Decode(&a); err != nil {
+		return a, fmt.Errorf("%s: %w", path, err)
+	}
+	if a.Version < 1 || a.Version > Version {
+		return a, fmt.Errorf("%s: unsupported backup version %d", path, a.Version)
+	}
+	return a, nil
+}
+
+// List returns the backups in dir, newest first
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Info
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "backup-") || !strings.HasSuffix(e.Name(), ".json.gz") {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(e.Name(), "backup-"), ".json.gz")
+		created, err := time.ParseInLocation("20060102-150405", stamp[:min(len(stamp), 15)], time.Local)
+		if err != nil {
+			created = fi.ModTime()
+		}
+		out = append(out, Info{Path: filepath.Join(dir, e.Name()), CreatedAt: created, Size: fi.Size()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Latest returns the path of the newest backup in dir
+func Latest(dir string) (string, error) {
+	backups, err := List(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", fmt.Errorf("no backups in %s", dir)
+	}
+	return backups[0].Path, nil
+}
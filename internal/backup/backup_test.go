@@ -0,0 +1,201 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+func login(t *testing.T, srv *fakeserver.Server) *api.Client {
+	t.Helper()
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// seeded returns a server with an open, a done and a shared task
+func seeded(t *testing.T) *fakeserver.Server {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	srv.AddUser("friend@example.com", "secret")
+
+	work := srv.AddCategory(fakeserver.DemoEmail, "Work", "#45B7D1")
+	due := time.Date(2026, 3, 14, 17, 0, 0, 0, time.UTC)
+	notes := "Use the Q1 numbers"
+	srv.AddTask(fakeserver.DemoEmail, api.Task{
+		Title: "Write report", Priority: 8, DueAt: &due, Notes: &notes, CategoryID: &work.ID,
+		Tags:     []string{"q1"},
+		Subtasks: []api.Subtask{{Title: "Outline", Status: "done"}, {Title: "Draft"}},
+	})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Pay rent", Status: "done"})
+	srv.AddTask("friend@example.com", api.Task{
+		Title:      "Plan party",
+		SharedWith: []api.ShareInfo{{Email: fakeserver.DemoEmail}},
+	})
+	return srv
+}
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	srv := seeded(t)
+	client := login(t, srv)
+
+	a, err := Snapshot(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Tasks) != 3 || len(a.Categories) != 1 {
+		t.Fatalf("snapshot has %d tasks and %d categories, want 3 and 1", len(a.Tasks), len(a.Categories))
+	}
+	if a.Server != srv.URL() || a.Account != fakeserver.DemoEmail {
+		t.Errorf("snapshot metadata = %q %q", a.Server, a.Account)
+	}
+
+	dir := t.TempDir()
+	first, err := Save(dir, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Save(dir, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("second backup in the same second overwrote the first")
+	}
+
+	backups, err := List(dir)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("List = %v, %v", backups, err)
+	}
+	loaded, err := Load(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Tasks) != 3 || loaded.Tasks[0].Title != "Write report" || len(loaded.Tasks[0].Subtasks) != 2 {
+		t.Errorf("loaded tasks = %+v", loaded.Tasks)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	path := t.TempDir() + "/future.json.gz"
+	if err := WriteFile(path, Archive{Version: Version + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Load = %v, want a version error", err)
+	}
+}
+
+func TestRestoreRemapsCategories(t *testing.T) {
+	a, err := Snapshot(login(t, seeded(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := fakeserver.New()
+	defer target.Close()
+	target.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	// Shift the ID sequence so the archive's category IDs are wrong here
+	target.AddCategory(fakeserver.DemoEmail, "Home", "#96CEB4")
+	target.AddCategory(fakeserver.DemoEmail, "Errands", "#FFEAA7")
+	client := login(t, target)
+
+	res, err := Restore(client, a, RestoreOptions{})
+	if err != nil || len(res.Errors) > 0 {
+		t.Fatalf("Restore: %v %v", err, res.Errors)
+	}
+	if res.TasksCreated != 2 || res.CategoriesCreated != 1 {
+		t.Errorf("result = %+v, want 2 tasks and 1 category (shared task skipped)", res)
+	}
+
+	tasks := target.Tasks()
+	if len(tasks) != 2 {
+		t.Fatalf("target tasks = %+v", tasks)
+	}
+	report := tasks[0]
+	if report.Category == nil || report.Category.Name != "Work" {
+		t.Errorf("report category = %+v, want Work", report.Category)
+	}
+	if report.Priority != 8 || report.Notes == nil || len(report.Tags) != 1 || report.DueAt == nil {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Subtasks) != 2 || report.Subtasks[0].Title != "Outline" || report.Subtasks[0].Status != "done" {
+		t.Errorf("report subtasks = %+v", report.Subtasks)
+	}
+	if tasks[1].Status != "done" {
+		t.Errorf("rent status = %q, want done", tasks[1].Status)
+	}
+
+	// Restoring again is a no-op
+	again, err := Restore(client, a, RestoreOptions{IncludeShared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.TasksCreated != 1 || again.TasksSkipped != 2 || again.CategoriesReused != 1 {
+		t.Errorf("second restore = %+v, want only the shared task created", again)
+	}
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	a, err := Snapshot(login(t, seeded(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := fakeserver.New()
+	defer target.Close()
+	target.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+
+	res, err := Restore(login(t, target), a, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TasksCreated != 2 || len(target.Tasks()) != 0 || len(target.Categories()) != 0 {
+		t.Errorf("dry run = %+v, server has %d tasks", res, len(target.Tasks()))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	srv := seeded(t)
+	client := login(t, srv)
+	a, err := Snapshot(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Compare(client, a)
+	if err != nil || !d.Empty() {
+		t.Fatalf("fresh backup diff = %+v, %v", d, err)
+	}
+
+	srv.UpdateTask(a.Tasks[0].ID, func(t *api.Task) { t.Priority = 2; t.Status = "done" })
+	srv.DeleteTask(a.Tasks[1].ID)
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "New since backup"})
+
+	d, err = Compare(client, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	want := []string{
+		"+ Pay rent (only in backup)",
+		"- New since backup (only in live account)",
+		"~ Write report (status, priority)",
+	}
+	for _, line := range want {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("diff missing %q:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// RestoreOptions controls what Restore recreates
+type RestoreOptions struct {
+	// IncludeShared also recreates tasks that other accounts shared with
+	// the backed-up account, as tasks owned by the target account
+	IncludeShared bool
+	// DryRun reports what would happen without changing anything
+	DryRun bool
+}
+
+// RestoreResult summarises a restore
+type RestoreResult struct {
+	CategoriesCreated int
+	CategoriesReused  int
+	TasksCreated      int
+	TasksSkipped      int
+	// CategoryIDs maps category IDs in the archive to IDs on the target
+	CategoryIDs map[int]int
+	// Errors holds per-task failures; the restore carries on past them
+	Errors []error
+}
+
+// Restore recreates the archive's categories and tasks through client.
+// Categories are matched to existing ones by name and created otherwise,
+// and task category IDs are remapped accordingly. Tasks already present
+// with the same title and due date are skipped, so a restore can be
+// repeated safely.
+func Restore(client *api.Client, a Archive, opts RestoreOptions) (RestoreResult, error) {
+	res := RestoreResult{CategoryIDs: make(map[int]int)}
+
+	liveCats, err := client.ListCategories()
+	if err != nil {
+		return res, fmt.Errorf("listing categories: %w", err)
+	}
+	byName := make(map[string]int)
+	for _, c := range liveCats {
+		byName[strings.ToLower(c.Name)] = c.ID
+	}
+	for _, c := range a.Categories {
+		if id, ok := byName[strings.ToLower(c.Name)]; ok {
+			res.CategoryIDs[c.ID] = id
+			res.CategoriesReused++
+			continue
+		}
+		res.CategoriesCreated++
+		if opts.DryRun {
+			continue
+		}
+		created, err := client.CreateCategory(c.Name, c.Color)
+		if err != nil {
+			return res, fmt.Errorf("creating category %q: %w", c.Name, err)
+		}
+		res.CategoryIDs[c.ID] = created.ID
+		byName[strings.ToLower(c.Name)] = created.ID
+	}
+
+	live, err := liveTasks(client)
+	if err != nil {
+		return res, err
+	}
+	existing := make(map[string]bool)
+	for _, t := range live {
+		existing[matchKey(t)] = true
+	}
+
+	for _, t := range a.Tasks {
+		if !owned(t) && !opts.IncludeShared {
+			continue
+		}
+		if existing[matchKey(t)] {
+			res.TasksSkipped++
+			continue
+		}
+		existing[matchKey(t)] = true
+		res.TasksCreated++
+		if opts.DryRun {
+			continue
+		}
+		if err := restoreTask(client, t, res.CategoryIDs); err != nil {
+			res.TasksCreated--
+			res.Errors = append(res.Errors, fmt.Errorf("%s: %w", t.Title, err))
+		}
+	}
+	return res, nil
+}
+
+// restoreTask creates one task with its subtasks and state
+func restoreTask(client *api.Client, t api.Task, categoryIDs map[int]int) error {
+	req := api.TaskCreateRequest{Title: t.Title, Notes: t.Notes, DueAt: t.DueAt, Tags: t.Tags}
+	if t.Priority > 0 {
+		p := t.Priority
+		req.Priority = &p
+	}
+	if t.EffortMin > 0 {
+		e := t.EffortMin
+		req.EffortMin = &e
+	}
+	if t.CategoryID != nil {
+		if id, ok := categoryIDs[*t.CategoryID]; ok {
+			req.CategoryID = &id
+		}
+	}
+
+	created, err := client.CreateTask(req)
+	if err != nil {
+		return err
+	}
+
+	subtasks := append([]api.Subtask(nil), t.Subtasks...)
+	sort.SliceStable(subtasks, func(i, j int) bool { return subtasks[i].Sort < subtasks[j].Sort })
+	for _, st := range subtasks {
+		sub, err := client.CreateSubtask(created.ID, st.Title)
+		if err != nil {
+			return err
+		}
+		if st.Status == "done" {
+			done := "done"
+			if err := client.UpdateSubtask(sub.ID, api.SubtaskUpdateRequest{Status: &done}); err != nil {
+				return err
+			}
+		}
+	}
+
+	var update api.TaskUpdateRequest
+	changed := false
+	if t.Status == "done" {
+		update.Status = &t.Status
+		changed = true
+	}
+	if t.NotificationsEnabled {
+		update.NotificationsEnabled = &t.NotificationsEnabled
+		changed = true
+	}
+	if changed {
+		if _, err := client.UpdateTask(created.ID, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// liveTasks lists every task the account can currently see
+func liveTasks(client *api.Client) ([]api.Task, error) {
+	tasks, err := client.ListAllTasks("all")
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// owned reports whether the backed-up account owned the task
+func owned(t api.Task) bool {
+	return t.IsOwner == nil || *t.IsOwner
+}
+
+// matchKey identifies a task across accounts by its title and due date
+func matchKey(t api.Task) string {
+	due := ""
+	if t.DueAt != nil {
+		due = t.DueAt.UTC().Format("2006-01-02T15:04")
+	}
+	return strings.ToLower(strings.TrimSpace(t.Title)) + "\x00" + due
+}
+
+// Change is a task that differs between the backup and the live account
+type Change struct {
+	Title  string
+	Fields []string
+}
+
+// Diff compares a backup with the live account
+type Diff struct {
+	// OnlyInBackup would be recreated by a restore
+	OnlyInBackup []api.Task
+	// OnlyLive are tasks created since the backup
+	OnlyLive []api.Task
+	Changed  []Change
+}
+
+// Empty reports whether the backup matches the account
+func (d Diff) Empty() bool {
+	return len(d.OnlyInBackup) == 0 && len(d.OnlyLive) == 0 && len(d.Changed) == 0
+}
+
+// Compare diffs the archive against the live account. Tasks are matched by
+// ID when the archive came from the same server, and by title and due date
+// otherwise.
+func Compare(client *api.Client, a Archive) (Diff, error) {
+	live, err := liveTasks(client)
+	if err != nil {
+		return Diff{}, err
+	}
+	sameServer := a.Server == client.BaseURL()
+	key := func(t api.Task) string {
+		if sameServer {
+			return fmt.Sprint(t.ID)
+		}
+		return matchKey(t)
+	}
+
+	liveByKey := make(map[string]api.Task)
+	for _, t := range live {
+		liveByKey[key(t)] = t
+	}
+
+	var d Diff
+	for _, t := range a.Tasks {
+		k := key(t)
+		lt, ok := liveByKey[k]
+		if !ok {
+			d.OnlyInBackup = append(d.OnlyInBackup, t)
+			continue
+		}
+		delete(liveByKey, k)
+		if fields := changedFields(t, lt); len(fields) > 0 {
+			d.Changed = append(d.Changed, Change{Title: t.Title, Fields: fields})
+		}
+	}
+	for _, t := range live {
+		if _, ok := liveByKey[key(t)]; ok {
+			d.OnlyLive = append(d.OnlyLive, t)
+		}
+	}
+	return d, nil
+}
+
+// changedFields names the user-visible fields that differ
+func changedFields(a, b api.Task) []string {
+	var out []string
+	if a.Title != b.Title {
+		out = append(out, "title")
+	}
+	if a.Status != b.Status {
+		out = append(out, "status")
+	}
+	if deref(a.Notes) != deref(b.Notes) {
+		out = append(out, "notes")
+	}
+	if a.Priority != b.Priority {
+		out = append(out, "priority")
+	}
+	if !sameTime(a.DueAt, b.DueAt) {
+		out = append(out, "due")
+	}
+	if categoryName(a) != categoryName(b) {
+		out = append(out, "category")
+	}
+	if !reflect.DeepEqual(subtaskSummary(a), subtaskSummary(b)) {
+		out = append(out, "subtasks")
+	}
+	if strings.Join(a.Tags, ",") != strings.Join(b.Tags, ",") {
+		out = append(out, "tags")
+	}
+	return out
+}
+
+// WriteTo prints the diff in a short human-readable form
+func (d Diff) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	if d.Empty() {
+		b.WriteString("Backup matches the live account.\n")
+	}
+	for _, t := range d.OnlyInBackup {
+		fmt.Fprintf(&b, "+ %s (only in backup)\n", t.Title)
+	}
+	for _, t := range d.OnlyLive {
+		fmt.Fprintf(&b, "- %s (only in live account)\n", t.Title)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %s (%s)\n", c.Title, strings.Join(c.Fields, ", "))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func categoryName(t api.Task) string {
+	if t.Category != nil {
+		return t.Category.Name
+	}
+	return ""
+}
+
+func subtaskSummary(t api.Task) []string {
+	var out []string
+	for _, st := range t.Subtasks {
+		out = append(out, st.Status+" "+st.Title)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,14 +1,27 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const (
 	AppName      = "todo-tui"
 	APIURL       = "https://todo.blackraven.org/api"
 	FPS          = 30
+	FileName     = "config.json"
+	KeymapFile   = "keymap.json"
+
+	DefaultRefreshInterval = time.Minute
+	DefaultDailyCapacity   = 6 * time.Hour
+	DefaultPomodoroWork    = 25 * time.Minute
+	DefaultPomodoroBreak   = 5 * time.Minute
+	DefaultReminderLead    = 15 * time.Minute
+	DefaultPlanDays        = 3
 )
 
 // Config holds application configuration
@@ -17,22 +30,391 @@ type Config struct {
 	TokenPath  string
 	CredsPath  string
 	DataDir    string
+
+	// Profile is the name of the profile in use; empty for the default
+	Profile string
+
+	// RefreshInterval is how often the TUI refetches in the background;
+	// zero disables polling
+	RefreshInterval time.Duration
+
+	// LiveUpdates subscribes to the server's event stream; polling is
+	// paused while the stream is connected
+	LiveUpdates bool
+
+	// ExportDir is where exports from the TUI are written; empty means
+	// the current directory
+	ExportDir string
+
+	// Keymap holds the key binding settings from keymap.json
+	Keymap Keymap
+
+	// Theme is the name of the theme to start with; empty for the first.
+	// ThemeVariant is "dark", "light" or "auto" to follow the terminal's
+	// background where the theme has a variant for it.
+	Theme        string
+	ThemeVariant string
+
+	// Animation is "random", "off" or the name of the completion
+	// animation to always use; "off" also stops the other motion
+	Animation string
+
+	// Animations weighs how often each animation is picked at random;
+	// unlisted ones have a weight of 1 and 0 turns one off
+	Animations map[string]int
+
+	// TextMarkers spells out what is otherwise shown only by color: the
+	// priority level, overdue tasks and categories
+	TextMarkers bool
+
+	// GroupBy is how the task list starts out split into sections: "none",
+	// "category", "priority", "due" or "owner"
+	GroupBy string
+
+	// ScreenReader prints changes as lines of plain text instead of
+	// redrawing the screen
+	ScreenReader bool
+
+	// DailyCapacity is how much estimated effort fits in a day, shown
+	// against the Today list; zero hides it
+	DailyCapacity time.Duration
+
+	// PomodoroWork and PomodoroBreak are the lengths of a pomodoro timer's
+	// work intervals and the breaks between them
+	PomodoroWork  time.Duration
+	PomodoroBreak time.Duration
+
+	// ReminderLead is how long before a task is due the daemon reminds
+	// about it
+	ReminderLead time.Duration
+
+	// WorkStart and WorkEnd are the working hours the planner fills, as
+	// offsets from midnight, on WorkDays; PlanDays is how far ahead it plans
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	WorkDays  []time.Weekday
+	PlanDays  int
+}
+
+// Keymap is the contents of keymap.json: a preset ("default", "vim" or
+// "emacs") and per-action key lists that replace the preset's
+type Keymap struct {
+	Preset   string              `json:"preset"`
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// fileConfig mirrors the optional config.json in the data directory.
+// Durations are strings such as "30s" or "5m"; "off" disables polling.
+type fileConfig struct {
+	APIURL          string `json:"api_url"`
+	RefreshInterval string `json:"refresh_interval"`
+	LiveUpdates     *bool  `json:"live_updates"`
+	ExportDir       string `json:"export_dir"`
+	Theme           string `json:"theme"`
+	ThemeVariant    string `json:"theme_variant"`
+	Animation       string         `json:"animation"`
+	Animations      map[string]int `json:"animations"`
+	TextMarkers     bool   `json:"text_markers"`
+	ScreenReader    bool   `json:"screen_reader"`
+	GroupBy         string `json:"group_by"`
+	DailyCapacity   string `json:"daily_capacity"`
+	PomodoroWork    string `json:"pomodoro_work"`
+	PomodoroBreak   string `json:"pomodoro_break"`
+	ReminderLead    string `json:"reminder_lead"`
+	WorkHours       string `json:"work_hours"`
+	WorkDays        string `json:"work_days"`
+	PlanDays        int    `json:"plan_days"`
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
-	dataDir := GetDataDir()
+	return ForDataDir(GetDataDir())
+}
+
+// ForDataDir returns the default configuration rooted at the given data directory
+func ForDataDir(dataDir string) *Config {
 	return &Config{
 		APIURL:    APIURL,
 		TokenPath: filepath.Join(dataDir, "token"),
 		CredsPath: filepath.Join(dataDir, "credentials"),
 		DataDir:   dataDir,
+
+		RefreshInterval: DefaultRefreshInterval,
+		LiveUpdates:     true,
+		ThemeVariant:    "auto",
+		Animation:       "random",
+		DailyCapacity:   DefaultDailyCapacity,
+		PomodoroWork:    DefaultPomodoroWork,
+		PomodoroBreak:   DefaultPomodoroBreak,
+		ReminderLead:    DefaultReminderLead,
+		WorkStart:       9 * time.Hour,
+		WorkEnd:         17 * time.Hour,
+		WorkDays:        []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		PlanDays:        DefaultPlanDays,
+	}
+}
+
+// Load loads the configuration, applying config.json from the data
+// directory on top of the defaults. A missing file is not an error; on a
+// malformed file the defaults are returned along with the error.
+func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile is like Load for a named profile. Each profile has its own
+// data directory, so its own token, credentials, settings and backups.
+// A malformed keymap.json only loses the key bindings.
+func LoadProfile(name string) (*Config, error) {
+	dataDir := ProfileDataDir(name)
+	cfg := ForDataDir(dataDir)
+	cfg.Profile = name
+	if err := cfg.loadFile(filepath.Join(dataDir, FileName)); err != nil {
+		cfg = ForDataDir(dataDir)
+		cfg.Profile = name
+		return cfg, err
+	}
+	if err := cfg.loadKeymap(filepath.Join(dataDir, KeymapFile)); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ProfileDataDir returns the data directory for a named profile; the empty
+// name is the default profile
+func ProfileDataDir(name string) string {
+	if name == "" {
+		return GetDataDir()
 	}
+	return filepath.Join(GetDataDir(), "profiles", name)
 }
 
-// Load loads the configuration (currently just returns defaults)
-func Load() *Config {
-	return DefaultConfig()
+// loadFile applies the settings in the given file
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if fc.APIURL != "" {
+		c.APIURL = fc.APIURL
+	}
+	if fc.RefreshInterval != "" {
+		d, err := parseInterval(fc.RefreshInterval)
+		if err != nil {
+			return fmt.Errorf("%s: refresh_interval: %w", path, err)
+		}
+		c.RefreshInterval = d
+	}
+	if fc.ExportDir != "" {
+		c.ExportDir = fc.ExportDir
+	}
+	if fc.LiveUpdates != nil {
+		c.LiveUpdates = *fc.LiveUpdates
+	}
+	if fc.Theme != "" {
+		c.Theme = fc.Theme
+	}
+	switch fc.ThemeVariant {
+	case "":
+	case "auto", "dark", "light":
+		c.ThemeVariant = fc.ThemeVariant
+	default:
+		return fmt.Errorf("%s: theme_variant: %q is not auto, dark or light", path, fc.ThemeVariant)
+	}
+	if fc.Animation != "" {
+		c.Animation = fc.Animation
+	}
+	c.Animations = fc.Animations
+	c.TextMarkers = fc.TextMarkers
+	c.ScreenReader = fc.ScreenReader
+	switch fc.GroupBy {
+	case "":
+	case "none", "category", "priority", "due", "owner":
+		c.GroupBy = fc.GroupBy
+	default:
+		return fmt.Errorf("%s: group_by: %q is not none, category, priority, due or owner", path, fc.GroupBy)
+	}
+	if fc.DailyCapacity != "" {
+		d, err := parseInterval(fc.DailyCapacity)
+		if err != nil {
+			return fmt.Errorf("%s: daily_capacity: %w", path, err)
+		}
+		c.DailyCapacity = d
+	}
+	if fc.PomodoroWork != "" {
+		d, err := parseInterval(fc.PomodoroWork)
+		if err != nil || d == 0 {
+			return fmt.Errorf("%s: pomodoro_work: %q is not a positive duration", path, fc.PomodoroWork)
+		}
+		c.PomodoroWork = d
+	}
+	if fc.PomodoroBreak != "" {
+		d, err := parseInterval(fc.PomodoroBreak)
+		if err != nil {
+			return fmt.Errorf("%s: pomodoro_break: %w", path, err)
+		}
+		c.PomodoroBreak = d
+	}
+	if fc.ReminderLead != "" {
+		d, err := parseInterval(fc.ReminderLead)
+		if err != nil {
+			return fmt.Errorf("%s: reminder_lead: %w", path, err)
+		}
+		c.ReminderLead = d
+	}
+	if fc.WorkHours != "" {
+		start, end, err := parseHours(fc.WorkHours)
+		if err != nil {
+			return fmt.Errorf("%s: work_hours: %w", path, err)
+		}
+		c.WorkStart, c.WorkEnd = start, end
+	}
+	if fc.WorkDays != "" {
+		days, err := parseDays(fc.WorkDays)
+		if err != nil {
+			return fmt.Errorf("%s: work_days: %w", path, err)
+		}
+		c.WorkDays = days
+	}
+	if fc.PlanDays < 0 {
+		return fmt.Errorf("%s: plan_days: must not be negative", path)
+	}
+	if fc.PlanDays > 0 {
+		c.PlanDays = fc.PlanDays
+	}
+	return nil
+}
+
+// parseHours parses working hours such as "9:00-17:30" or "18:00-24:00"
+func parseHours(s string) (time.Duration, time.Duration, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q is not a range such as 9:00-17:00", s)
+	}
+	var clock [2]time.Duration
+	for i, part := range []string{from, to} {
+		// time.Parse has no 24:00, but the day can end there
+		if i == 1 && strings.TrimSpace(part) == "24:00" {
+			clock[i] = 24 * time.Hour
+			continue
+		}
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("%q is not a time such as 9:00", strings.TrimSpace(part))
+		}
+		clock[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if clock[1] <= clock[0] {
+		return 0, 0, fmt.Errorf("%q ends before it starts", s)
+	}
+	return clock[0], clock[1], nil
+}
+
+// weekdays maps day names to weekdays, Monday first
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// parseDays parses working days such as "mon-fri" or "monday,wed,fri". A
+// day is its full name or its first three letters.
+func parseDays(s string) ([]time.Weekday, error) {
+	index := func(name string) (int, error) {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for i, d := range weekdays {
+			if name == d || name == d[:3] {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%q is not a day of the week", name)
+	}
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := index(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = index(to); err != nil {
+				return nil, err
+			}
+		}
+		for i := first; ; i = (i + 1) % 7 {
+			days = append(days, time.Weekday((i+1)%7))
+			if i == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// SaveTheme records the theme to start with in config.json, keeping the
+// file's other settings
+func (c *Config) SaveTheme(name string) error {
+	path := filepath.Join(c.DataDir, FileName)
+	settings := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	settings["theme"], _ = json.Marshal(name)
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.DataDir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	c.Theme = name
+	return nil
+}
+
+// loadKeymap reads the key binding settings in the given file
+func (c *Config) loadKeymap(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var km Keymap
+	if err := json.Unmarshal(data, &km); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.Keymap = km
+	return nil
+}
+
+// parseInterval parses a duration setting, accepting "off" for zero
+func parseInterval(s string) (time.Duration, error) {
+	if s == "off" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return d, nil
 }
 
 // GetDataDir returns the data directory path
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to time.Duration
+		err      string
+	}{
+		{"9:00-17:30", 9 * time.Hour, 17*time.Hour + 30*time.Minute, ""},
+		{" 08:15 - 12:00 ", 8*time.Hour + 15*time.Minute, 12 * time.Hour, ""},
+		{"18:00-24:00", 18 * time.Hour, 24 * time.Hour, ""},
+		{"0:00-24:00", 0, 24 * time.Hour, ""},
+		{"24:00-24:00", 0, 0, "not a time"},
+		{"9:00", 0, 0, "not a range"},
+		{"9-17", 0, 0, "not a time"},
+		{"17:00-9:00", 0, 0, "ends before it starts"},
+		{"9:00-9:00", 0, 0, "ends before it starts"},
+	}
+	for _, tt := range tests {
+		from, to, err := parseHours(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseHours(%q) error = %v, want %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("parseHours(%q) = %v, %v, %v", tt.in, from, to, err)
+		}
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []time.Weekday
+		ok   bool
+	}{
+		{"mon-fri", []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, true},
+		{"Monday, wed,FRIDAY", []time.Weekday{time.Monday, time.Wednesday, time.Friday}, true},
+		{"sat-sun", []time.Weekday{time.Saturday, time.Sunday}, true},
+		{"fri-mon", []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}, true},
+		{"thursday", []time.Weekday{time.Thursday}, true},
+		{"monkey", nil, false},
+		{"sunshine", nil, false},
+		{"tues", nil, false},
+		{"mo", nil, false},
+		{"mon-", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parseDays(tt.in)
+		if (err == nil) != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseDays(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		check func(*Config) bool
+		err   string
+	}{
+		{"empty", `{}`, func(c *Config) bool { return c.WorkEnd == 17*time.Hour && c.GroupBy == "" }, ""},
+		{"work", `{"work_hours": "18:00-24:00", "work_days": "sat,sunday"}`, func(c *Config) bool {
+			return c.WorkStart == 18*time.Hour && c.WorkEnd == 24*time.Hour &&
+				reflect.DeepEqual(c.WorkDays, []time.Weekday{time.Saturday, time.Sunday})
+		}, ""},
+		{"intervals", `{"refresh_interval": "off", "reminder_lead": "10m"}`, func(c *Config) bool {
+			return c.RefreshInterval == 0 && c.ReminderLead == 10*time.Minute
+		}, ""},
+		{"group", `{"group_by": "due"}`, func(c *Config) bool { return c.GroupBy == "due" }, ""},
+		{"bad days", `{"work_days": "monkey"}`, nil, "work_days"},
+		{"bad hours", `{"work_hours": "9-5"}`, nil, "work_hours"},
+		{"bad group", `{"group_by": "colour"}`, nil, "group_by"},
+		{"bad pomodoro", `{"pomodoro_work": "off"}`, nil, "pomodoro_work"},
+		{"bad plan days", `{"plan_days": -1}`, nil, "plan_days"},
+		{"malformed", `{`, nil, "unexpected end"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(path, []byte(tt.json), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c := ForDataDir(filepath.Dir(path))
+		err := c.loadFile(path)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !tt.check(c) {
+			t.Errorf("%s: config = %+v, %v", tt.name, c, err)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	write := func(dir, name, text string) {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	work := ProfileDataDir("work")
+	if work == GetDataDir() || !strings.HasPrefix(work, GetDataDir()) {
+		t.Fatalf("profile dir = %s", work)
+	}
+	write(GetDataDir(), FileName, `{"theme": "nord"}`)
+	write(work, FileName, `{"theme": "dracula"}`)
+	write(work, KeymapFile, `{"preset": "vim", "bindings": {"delete": ["D"]}}`)
+
+	tests := []struct {
+		profile, theme, preset string
+	}{
+		{"", "nord", ""},
+		{"work", "dracula", "vim"},
+		{"home", "", ""},
+	}
+	for _, tt := range tests {
+		c, err := LoadProfile(tt.profile)
+		if err != nil {
+			t.Fatalf("profile %q: %v", tt.profile, err)
+		}
+		if c.Profile != tt.profile || c.Theme != tt.theme || c.Keymap.Preset != tt.preset || c.DataDir != ProfileDataDir(tt.profile) {
+			t.Errorf("profile %q = %+v", tt.profile, c)
+		}
+	}
+
+	// A malformed keymap keeps the other settings; a malformed config.json
+	// gives the defaults
+	write(work, KeymapFile, `{`)
+	if c, err := LoadProfile("work"); err == nil || c.Theme != "dracula" || c.Keymap.Preset != "" {
+		t.Errorf("malformed keymap: %+v, %v", c, err)
+	}
+	write(work, FileName, `{"work_days": "monkey"}`)
+	if c, err := LoadProfile("work"); err == nil || c.Theme != "" || c.Profile != "work" {
+		t.Errorf("malformed config: %+v, %v", c, err)
+	}
+}
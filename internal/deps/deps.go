@@ -0,0 +1,232 @@
+// Package deps stores which tasks wait on which. The server has no field
+// for it, so the links are kept next to the configuration, by task ID.
+package deps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FileName is the name of the dependency file within the data directory
+const FileName = "dependencies.json"
+
+// ErrCycle is returned for a link that would make a task wait on itself
+var ErrCycle = errors.New("tasks would wait on each other")
+
+// Store maps each task to the tasks blocking it. It is safe for concurrent
+// use, and a nil Store has no links.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	by   map[int][]int
+}
+
+// Load reads the dependencies in dataDir. A missing file gives no links;
+// on a malformed file there are none and the error is returned.
+func Load(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, FileName), by: make(map[int][]int)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.by); err != nil {
+		s.by = make(map[int][]int)
+		return s, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// BlockedBy returns the tasks id waits on
+func (s *Store) BlockedBy(id int) []int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.by[id])
+}
+
+// Blocks returns the tasks waiting on id, in ID order
+func (s *Store) Blocks(id int) []int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []int
+	for task, blockers := range s.by {
+		if slices.Contains(blockers, id) {
+			out = append(out, task)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Waiting returns the tasks that wait on others, in ID order
+func (s *Store) Waiting() []int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]int, 0, len(s.by))
+	for id := range s.by {
+		out = append(out, id)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Blocked reports whether any of the tasks id waits on is still open
+func (s *Store) Blocked(id int, open func(id int) bool) bool {
+	for _, b := range s.BlockedBy(id) {
+		if open(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add makes id wait on blocker. It returns an error wrapping ErrCycle if
+// blocker already waits on id, directly or through other tasks.
+func (s *Store) Add(id, blocker int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id == blocker {
+		return fmt.Errorf("%w: a task can't wait on itself", ErrCycle)
+	}
+	if slices.Contains(s.by[id], blocker) {
+		return nil
+	}
+	if path := s.chain(blocker, id); path != nil {
+		steps := make([]string, 0, len(path)+1)
+		for _, p := range append([]int{id}, path...) {
+			steps = append(steps, fmt.Sprintf("#%d", p))
+		}
+		return fmt.Errorf("%w: %s", ErrCycle, strings.Join(steps, " waits on "))
+	}
+	s.by[id] = append(s.by[id], blocker)
+	slices.Sort(s.by[id])
+	return s.save()
+}
+
+// chain returns the tasks from waits on in turn until reaching to,
+// starting with from, or nil if from doesn't wait on to; the caller holds
+// s.mu
+func (s *Store) chain(from, to int) []int {
+	seen := make(map[int]bool)
+	var walk func(id int) []int
+	walk = func(id int) []int {
+		if id == to {
+			return []int{id}
+		}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		for _, b := range s.by[id] {
+			if rest := walk(b); rest != nil {
+				return append([]int{id}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// Remove stops id waiting on blocker
+func (s *Store) Remove(id, blocker int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.by[id], blocker)
+	if i < 0 {
+		return nil
+	}
+	s.by[id] = slices.Delete(s.by[id], i, i+1)
+	if len(s.by[id]) == 0 {
+		delete(s.by, id)
+	}
+	return s.save()
+}
+
+// Forget drops every link to or from a deleted task
+func (s *Store) Forget(id int) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := false
+	if _, ok := s.by[id]; ok {
+		delete(s.by, id)
+		changed = true
+	}
+	for task, blockers := range s.by {
+		if i := slices.Index(blockers, id); i >= 0 {
+			s.by[task] = slices.Delete(blockers, i, i+1)
+			if len(s.by[task]) == 0 {
+				delete(s.by, task)
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// Node is a task in a dependency tree, with the tasks it waits on
+type Node struct {
+	ID       int
+	Children []Node
+}
+
+// Tree returns what id waits on, and what those wait on in turn
+func (s *Store) Tree(id int) []Node {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var build func(id int, seen map[int]bool) []Node
+	build = func(id int, seen map[int]bool) []Node {
+		var out []Node
+		for _, b := range s.by[id] {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+			out = append(out, Node{ID: b, Children: build(b, seen)})
+			delete(seen, b)
+		}
+		return out
+	}
+	return build(id, map[int]bool{id: true})
+}
+
+// save writes the links; the caller holds s.mu
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.by, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
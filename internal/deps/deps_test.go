@@ -0,0 +1,71 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 waits on 2 and 3; 2 waits on 4
+	for _, l := range [][2]int{{1, 2}, {1, 3}, {2, 4}} {
+		if err := s.Add(l[0], l[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 4 waiting on 1 would go round
+	err = s.Add(4, 1)
+	if !errors.Is(err, ErrCycle) || err.Error() != "tasks would wait on each other: #4 waits on #1 waits on #2 waits on #4" {
+		t.Errorf("cycle error = %v", err)
+	}
+	if err := s.Add(3, 3); !errors.Is(err, ErrCycle) {
+		t.Errorf("self link error = %v", err)
+	}
+
+	if got := s.Blocks(2); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Blocks(2) = %v", got)
+	}
+	open := map[int]bool{2: true}
+	if !s.Blocked(1, func(id int) bool { return open[id] }) {
+		t.Error("1 not blocked by open 2")
+	}
+	if s.Blocked(1, func(int) bool { return false }) {
+		t.Error("1 blocked once everything is done")
+	}
+	if got := fmt.Sprint(s.Tree(1)); got != "[{2 [{4 []}]} {3 []}]" {
+		t.Errorf("tree = %s", got)
+	}
+
+	// Links survive a reload, and deleting a task drops its links
+	s, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Forget(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.BlockedBy(1); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("BlockedBy(1) after forgetting 2 = %v", got)
+	}
+	if got := s.Waiting(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("waiting = %v", got)
+	}
+	if err := s.Remove(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Waiting(); len(got) != 0 {
+		t.Errorf("waiting after removing the last link = %v", got)
+	}
+
+	var none *Store
+	if none.Blocked(1, func(int) bool { return true }) || none.Tree(1) != nil {
+		t.Error("nil store has links")
+	}
+}
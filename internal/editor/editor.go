@@ -0,0 +1,60 @@
+// Package editor hands text to the user's own editor ($VISUAL or $EDITOR)
+// through a temporary file.
+package editor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Fallback is run when neither $VISUAL nor $EDITOR is set
+const Fallback = "vi"
+
+// Command returns the command that edits path. The editor variable may
+// include arguments, e.g. "code --wait".
+func Command(path string) (*exec.Cmd, error) {
+	spec := os.Getenv("VISUAL")
+	if strings.TrimSpace(spec) == "" {
+		spec = os.Getenv("EDITOR")
+	}
+	if strings.TrimSpace(spec) == "" {
+		spec = Fallback
+	}
+	args := strings.Fields(spec)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, errors.New("editor " + args[0] + " not found; set $EDITOR")
+	}
+	return exec.Command(args[0], append(args[1:], path)...), nil
+}
+
+// TempFile writes text to a new temporary file named after pattern (see
+// os.CreateTemp) and returns its path. The caller removes it.
+func TempFile(pattern, text string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// ReadBack returns the edited contents of a temporary file and removes it.
+// A single trailing newline, which most editors add, is dropped.
+func ReadBack(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	os.Remove(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
@@ -0,0 +1,40 @@
+package editor
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true --wait")
+	cmd, err := Command("/tmp/notes.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Args; len(got) != 3 || got[1] != "--wait" || got[2] != "/tmp/notes.md" {
+		t.Errorf("args = %q", got)
+	}
+
+	t.Setenv("VISUAL", "no-such-editor-here")
+	if _, err := Command("x"); err == nil {
+		t.Error("missing editor accepted")
+	}
+}
+
+func TestTempFileRoundTrip(t *testing.T) {
+	path, err := TempFile("notes-*.md", "first\nsecond")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("edited\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBack(path)
+	if err != nil || got != "edited" {
+		t.Errorf("ReadBack = %q, %v", got, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("temp file not removed")
+	}
+}
@@ -0,0 +1,270 @@
+// Package export writes tasks to files in several interchange formats.
+// The JSON format is lossless and can be read back with ReadJSON; the
+// others are meant for other tools and drop what they cannot express.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Format identifies an export format
+type Format string
+
+const (
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Markdown Format = "markdown"
+	TodoTxt  Format = "todotxt"
+	ICal     Format = "ics"
+)
+
+// Formats lists every supported format in menu order
+var Formats = []Format{JSON, CSV, Markdown, TodoTxt, ICal}
+
+// Version is the current JSON document version
+const Version = 1
+
+// Document is the lossless JSON representation of an export
+type Document struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Tasks      []api.Task     `json:"tasks"`
+	Categories []api.Category `json:"categories"`
+}
+
+// NewDocument builds a document for the given data
+func NewDocument(tasks []api.Task, categories []api.Category) Document {
+	if tasks == nil {
+		tasks = []api.Task{}
+	}
+	if categories == nil {
+		categories = []api.Category{}
+	}
+	return Document{Version: Version, ExportedAt: time.Now().UTC(), Tasks: tasks, Categories: categories}
+}
+
+// ParseFormat parses a format name, accepting common aliases
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "json":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	case "md", "markdown":
+		return Markdown, nil
+	case "todotxt", "todo.txt", "txt":
+		return TodoTxt, nil
+	case "ics", "ical", "icalendar":
+		return ICal, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want json, csv, markdown, todotxt or ics)", s)
+}
+
+// Ext returns the file extension for the format, without the dot
+func (f Format) Ext() string {
+	switch f {
+	case Markdown:
+		return "md"
+	case TodoTxt:
+		return "txt"
+	}
+	return string(f)
+}
+
+// Label returns a human readable name for the format
+func (f Format) Label() string {
+	switch f {
+	case JSON:
+		return "JSON (lossless)"
+	case CSV:
+		return "CSV"
+	case Markdown:
+		return "Markdown checklist"
+	case TodoTxt:
+		return "todo.txt"
+	case ICal:
+		return "iCalendar (.ics)"
+	}
+	return string(f)
+}
+
+// FileName returns a default file name for an export made at the given time
+func FileName(f Format, now time.Time) string {
+	return fmt.Sprintf("todo-export-%s.%s", now.Format("20060102-150405"), f.Ext())
+}
+
+// Write encodes the document in the given format
+func Write(w io.Writer, f Format, doc Document) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, doc)
+	case CSV:
+		return writeCSV(w, doc)
+	case Markdown:
+		return writeMarkdown(w, doc)
+	case TodoTxt:
+		return writeTodoTxt(w, doc)
+	case ICal:
+		return writeICal(w, doc)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+// WriteFile writes the document to path in the given format
+func WriteFile(path string, f Format, doc Document) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, f, doc); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadJSON decodes a document written in the JSON format
+func ReadJSON(r io.Reader) (Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return doc, fmt.Errorf("invalid export file: %w", err)
+	}
+	if doc.Version > Version {
+		return doc, fmt.Errorf("export version %d is newer than supported version %d", doc.Version, Version)
+	}
+	return doc, nil
+}
+
+func writeJSON(w io.Writer, doc Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// csvHeader lists the CSV columns
+var csvHeader = []string{
+	"id", "title", "status", "priority", "effort_min", "due_at",
+	"scheduled_start", "scheduled_end", "category", "tags", "notes",
+	"subtasks", "shared_with", "owner",
+}
+
+func writeCSV(w io.Writer, doc Document) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, t := range doc.Tasks {
+		var subtasks []string
+		for _, st := range sortedSubtasks(t) {
+			subtasks = append(subtasks, checkbox(st.Status)+" "+st.Title)
+		}
+		record := []string{
+			strconv.Itoa(t.ID),
+			t.Title,
+			t.Status,
+			strconv.Itoa(t.Priority),
+			strconv.Itoa(t.EffortMin),
+			formatTime(t.DueAt),
+			formatTime(t.ScheduledStart),
+			formatTime(t.ScheduledEnd),
+			categoryName(t, doc.Categories),
+			strings.Join(t.Tags, ";"),
+			deref(t.Notes),
+			strings.Join(subtasks, "; "),
+			strings.Join(shareEmails(t), ";"),
+			deref(t.OwnerEmail),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Helpers shared by the formats
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func checkbox(status string) string {
+	if status == "done" {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// categoryName returns the task's category name, looking it up by ID if the
+// task does not carry the category itself
+func categoryName(t api.Task, categories []api.Category) string {
+	if t.Category != nil {
+		return t.Category.Name
+	}
+	if t.CategoryID != nil {
+		for _, c := range categories {
+			if c.ID == *t.CategoryID {
+				return c.Name
+			}
+		}
+	}
+	return ""
+}
+
+func shareEmails(t api.Task) []string {
+	var out []string
+	for _, sh := range t.SharedWith {
+		out = append(out, sh.Email)
+	}
+	return out
+}
+
+func sortedSubtasks(t api.Task) []api.Subtask {
+	out := append([]api.Subtask(nil), t.Subtasks...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Sort < out[j].Sort })
+	return out
+}
+
+// Fetch loads the tasks matching params, along with the categories, into a
+// new document. Without a status, open and done tasks are listed in turn
+// rather than left to the server's default.
+func Fetch(client *api.Client, params api.TaskListParams) (Document, error) {
+	statuses := []string{params.Status}
+	if params.Status == "" {
+		statuses = []string{"open", "done"}
+	}
+	var tasks []api.Task
+	for _, status := range statuses {
+		params.Status = status
+		list, err := client.ListTasks(params)
+		if err != nil {
+			return Document{}, err
+		}
+		tasks = append(tasks, list...)
+	}
+	categories, err := client.ListCategories()
+	if err != nil {
+		return Document{}, err
+	}
+	return NewDocument(tasks, categories), nil
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// sampleDocument returns a document exercising every exported field
+func sampleDocument() Document {
+	due := time.Date(2026, 3, 14, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 3, 13, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	notes := "Use the Q1 numbers\nAsk Sam, then Kim; both"
+	owner := "demo@example.com"
+	isOwner := true
+	catID := 2
+	work := api.Category{ID: catID, Name: "Work", Color: "#45B7D1", CreatedAt: start}
+
+	return Document{
+		Version:    Version,
+		ExportedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Categories: []api.Category{work},
+		Tasks: []api.Task{
+			{
+				ID: 10, Title: "Write report", Notes: &notes, Status: "open", DueAt: &due,
+				Priority: 9, EffortMin: 90, ScheduledStart: &start, ScheduledEnd: &end,
+				CategoryID: &catID, Category: &work, Tags: []string{"q1", "finance"},
+				Subtasks: []api.Subtask{
+					{ID: 12, Title: "Draft", Status: "done", Sort: 1},
+					{ID: 11, Title: "Outline", Status: "done", Sort: 0},
+				},
+				IsOwner: &isOwner, OwnerEmail: &owner,
+				SharedWith: []api.ShareInfo{{Email: "team@example.com"}},
+			},
+			{ID: 20, Title: "Buy milk", Status: "done", Priority: 2, Subtasks: []api.Subtask{}},
+		},
+	}
+}
+
+func render(t *testing.T, f Format, doc Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, f, doc); err != nil {
+		t.Fatalf("Write(%s): %v", f, err)
+	}
+	return buf.String()
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	doc := sampleDocument()
+	got, err := ReadJSON(strings.NewReader(render(t, JSON, doc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip changed the document\n got: %+v\nwant: %+v", got, doc)
+	}
+}
+
+func TestReadJSONRejectsNewerVersion(t *testing.T) {
+	if _, err := ReadJSON(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("expected an error for a newer version")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(render(t, CSV, sampleDocument()))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("records = %v", records)
+	}
+	row := make(map[string]string)
+	for i, col := range csvHeader {
+		row[col] = records[1][i]
+	}
+	want := map[string]string{
+		"title":       "Write report",
+		"due_at":      "2026-03-14T17:00:00Z",
+		"category":    "Work",
+		"tags":        "q1;finance",
+		"notes":       "Use the Q1 numbers\nAsk Sam, then Kim; both",
+		"subtasks":    "[x] Outline; [x] Draft",
+		"shared_with": "team@example.com",
+	}
+	for col, v := range want {
+		if row[col] != v {
+			t.Errorf("%s = %q, want %q", col, row[col], v)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	want := `# Tasks
+
+## Work
+
+- [ ] Write report (P9, due 2026-03-14 17:00, scheduled 2026-03-13 09:00, shared with team@example.com) #q1 #finance
+  - [x] Outline
+  - [x] Draft
+  > Use the Q1 numbers
+  > Ask Sam, then Kim; both
+
+## Uncategorized
+
+- [x] Buy milk (P2)
+`
+	if got := render(t, Markdown, sampleDocument()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTodoTxt(t *testing.T) {
+	want := `(A) Write report +Work @q1 @finance due:2026-03-14 t:2026-03-13 shared:team@example.com id:10
+x Outline parent:10
+x Draft parent:10
+x Buy milk pri:D id:20
+`
+	if got := render(t, TodoTxt, sampleDocument()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestICal(t *testing.T) {
+	got := render(t, ICal, sampleDocument())
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:task-10@todo-tui\r\n",
+		"DUE:20260314T170000Z\r\n",
+		"PRIORITY:1\r\n",
+		"CATEGORIES:Work,q1,finance\r\n",
+		"ATTENDEE:mailto:team@example.com\r\n",
+		"STATUS:COMPLETED\r\n",
+		"BEGIN:VEVENT\r\nUID:slot-10@todo-tui\r\n",
+		"DTEND:20260313T103000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "BEGIN:VTODO") != 2 || strings.Count(got, "BEGIN:VEVENT") != 1 {
+		t.Errorf("want two VTODOs and one VEVENT:\n%s", got)
+	}
+	for _, line := range strings.Split(got, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	// The escaped description is long enough to be folded
+	unfolded := strings.ReplaceAll(got, "\r\n ", "")
+	if !strings.Contains(unfolded, `DESCRIPTION:Use the Q1 numbers\nAsk Sam\, then Kim\; both\n\n[x] Outline\n[x] Draft`) {
+		t.Errorf("description not escaped as expected:\n%s", unfolded)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"md": Markdown, "ICS": ICal, "todo.txt": TodoTxt, ".json": JSON} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// icalTime is the UTC date-time format used in iCalendar files
+const icalTime = "20060102T150405Z"
+
+// writeICal writes an iCalendar file with a VTODO per task and a VEVENT for
+// each task that has a scheduled time slot
+func writeICal(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	stamp := doc.ExportedAt.UTC().Format(icalTime)
+
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//blackraven//todo-tui//EN")
+	line("CALSCALE", "GREGORIAN")
+
+	for _, t := range doc.Tasks {
+		line("BEGIN", "VTODO")
+		line("UID", fmt.Sprintf("task-%d@todo-tui", t.ID))
+		line("DTSTAMP", stamp)
+		line("SUMMARY", icalEscape(t.Title))
+		if desc := icalDescription(t); desc != "" {
+			line("DESCRIPTION", icalEscape(desc))
+		}
+		if t.Status == "done" {
+			line("STATUS", "COMPLETED")
+		} else {
+			line("STATUS", "NEEDS-ACTION")
+		}
+		if t.DueAt != nil {
+			line("DUE", t.DueAt.UTC().Format(icalTime))
+		}
+		if t.ScheduledStart != nil {
+			line("DTSTART", t.ScheduledStart.UTC().Format(icalTime))
+		}
+		if p := icalPriority(t.Priority); p > 0 {
+			line("PRIORITY", fmt.Sprint(p))
+		}
+		if cats := icalCategories(t, doc.Categories); cats != "" {
+			line("CATEGORIES", cats)
+		}
+		if t.OwnerEmail != nil {
+			line("ORGANIZER", "mailto:"+*t.OwnerEmail)
+		}
+		for _, email := range shareEmails(t) {
+			line("ATTENDEE", "mailto:"+email)
+		}
+		line("END", "VTODO")
+
+		if t.ScheduledStart != nil && t.ScheduledEnd != nil {
+			line("BEGIN", "VEVENT")
+			line("UID", fmt.Sprintf("slot-%d@todo-tui", t.ID))
+			line("DTSTAMP", stamp)
+			line("DTSTART", t.ScheduledStart.UTC().Format(icalTime))
+			line("DTEND", t.ScheduledEnd.UTC().Format(icalTime))
+			line("SUMMARY", icalEscape(t.Title))
+			line("RELATED-TO", fmt.Sprintf("task-%d@todo-tui", t.ID))
+			line("END", "VEVENT")
+		}
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// icalDescription combines notes and the subtask checklist
+func icalDescription(t api.Task) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(deref(t.Notes)))
+	subtasks := sortedSubtasks(t)
+	if len(subtasks) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		for i, st := range subtasks {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(checkbox(st.Status) + " " + st.Title)
+		}
+	}
+	return b.String()
+}
+
+// icalCategories lists the category and tags as escaped CATEGORIES values
+func icalCategories(t api.Task, categories []api.Category) string {
+	var values []string
+	if name := categoryName(t, categories); name != "" {
+		values = append(values, icalEscape(name))
+	}
+	for _, tag := range t.Tags {
+		values = append(values, icalEscape(tag))
+	}
+	return strings.Join(values, ",")
+}
+
+// icalPriority maps a 0-10 priority onto iCalendar's 1 (highest) to 9
+// scale; 0 means undefined
+func icalPriority(p int) int {
+	if p <= 0 {
+		return 0
+	}
+	v := 10 - p
+	if v < 1 {
+		v = 1
+	}
+	return v
+}
+
+// icalEscape escapes a TEXT value
+func icalEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 sequences
+func writeFolded(w *bufio.Writer, s string) {
+	const limit = 75
+	first := true
+	for len(s) > 0 {
+		max := limit
+		if !first {
+			max = limit - 1 // continuation lines start with a space
+		}
+		n := len(s)
+		if n > max {
+			n = max
+			for n > 0 && !utf8Start(s[n]) {
+				n--
+			}
+		}
+		if !first {
+			w.WriteString(" ")
+		}
+		w.WriteString(s[:n])
+		w.WriteString("\r\n")
+		s = s[n:]
+		first = false
+	}
+}
+
+// utf8Start reports whether b begins a UTF-8 sequence
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// uncategorized heads the Markdown section for tasks without a category
+const uncategorized = "Uncategorized"
+
+// writeMarkdown writes a checklist grouped by category
+func writeMarkdown(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Tasks")
+
+	groups := make(map[string][]api.Task)
+	var names []string
+	for _, t := range doc.Tasks {
+		name := categoryName(t, doc.Categories)
+		if name == "" {
+			name = uncategorized
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], t)
+	}
+	// Categories alphabetically, uncategorized last
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == uncategorized || names[j] == uncategorized {
+			return names[j] == uncategorized && names[i] != uncategorized
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		fmt.Fprintf(bw, "\n## %s\n\n", name)
+		for _, t := range groups[name] {
+			fmt.Fprintf(bw, "- %s %s", checkbox(t.Status), t.Title)
+			if details := markdownDetails(t); details != "" {
+				fmt.Fprintf(bw, " (%s)", details)
+			}
+			for _, tag := range t.Tags {
+				fmt.Fprintf(bw, " #%s", strings.ReplaceAll(tag, " ", "-"))
+			}
+			fmt.Fprintln(bw)
+			for _, st := range sortedSubtasks(t) {
+				fmt.Fprintf(bw, "  - %s %s\n", checkbox(st.Status), st.Title)
+			}
+			if notes := strings.TrimSpace(deref(t.Notes)); notes != "" {
+				for _, line := range strings.Split(notes, "\n") {
+					fmt.Fprintf(bw, "  > %s\n", line)
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// markdownDetails summarises priority, dates and sharing for a checklist item
+func markdownDetails(t api.Task) string {
+	var parts []string
+	if t.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("P%d", t.Priority))
+	}
+	if t.DueAt != nil {
+		parts = append(parts, "due "+t.DueAt.Format("2006-01-02 15:04"))
+	}
+	if t.ScheduledStart != nil {
+		parts = append(parts, "scheduled "+t.ScheduledStart.Format("2006-01-02 15:04"))
+	}
+	if emails := shareEmails(t); len(emails) > 0 {
+		parts = append(parts, "shared with "+strings.Join(emails, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeTodoTxt writes one line per task in the todo.txt format. Subtasks
+// become their own lines tagged with parent:<id>. Notes are not exported.
+func writeTodoTxt(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range doc.Tasks {
+		var fields []string
+		pri := todoPriority(t.Priority)
+		if t.Status == "done" {
+			fields = append(fields, "x")
+		} else if pri != "" {
+			fields = append(fields, "("+pri+")")
+		}
+		fields = append(fields, singleLine(t.Title))
+		if name := categoryName(t, doc.Categories); name != "" {
+			fields = append(fields, "+"+todoWord(name))
+		}
+		for _, tag := range t.Tags {
+			fields = append(fields, "@"+todoWord(tag))
+		}
+		if t.DueAt != nil {
+			fields = append(fields, "due:"+t.DueAt.Format("2006-01-02"))
+		}
+		if t.ScheduledStart != nil {
+			fields = append(fields, "t:"+t.ScheduledStart.Format("2006-01-02"))
+		}
+		if t.Status == "done" && pri != "" {
+			fields = append(fields, "pri:"+pri)
+		}
+		for _, email := range shareEmails(t) {
+			fields = append(fields, "shared:"+email)
+		}
+		fields = append(fields, "id:"+strconv.Itoa(t.ID))
+		fmt.Fprintln(bw, strings.Join(fields, " "))
+
+		for _, st := range sortedSubtasks(t) {
+			prefix := ""
+			if st.Status == "done" {
+				prefix = "x "
+			}
+			fmt.Fprintf(bw, "%s%s parent:%d\n", prefix, singleLine(st.Title), t.ID)
+		}
+	}
+	return bw.Flush()
+}
+
+// todoPriority maps a 0-10 priority onto todo.txt letters
+func todoPriority(p int) string {
+	switch {
+	case p >= 8:
+		return "A"
+	case p >= 5:
+		return "B"
+	case p >= 3:
+		return "C"
+	case p > 0:
+		return "D"
+	}
+	return ""
+}
+
+// todoWord makes a project or context name a single word
+func todoWord(s string) string {
+	return strings.Join(strings.Fields(s), "-")
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
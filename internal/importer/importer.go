@@ -0,0 +1,150 @@
+// Package importer reads tasks from other tools and creates them through
+// the API. Files are parsed into Items, checked against the existing tasks
+// to build a Plan, and the Plan is then applied (or just printed for a dry
+// run).
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format identifies an import format
+type Format string
+
+const (
+	TodoTxt     Format = "todotxt"
+	Markdown    Format = "markdown"
+	CSV         Format = "csv"
+	Taskwarrior Format = "taskwarrior"
+	JSON        Format = "json"
+)
+
+// Item is a task read from an import file
+type Item struct {
+	Title     string
+	Notes     string
+	Done      bool
+	Priority  int
+	EffortMin int
+	DueAt     *time.Time
+	Category  string
+	Tags      []string
+	Subtasks  []Subtask
+}
+
+// Subtask is a checklist entry under an imported task
+type Subtask struct {
+	Title string
+	Done  bool
+}
+
+// ParseFormat parses a format name, accepting common aliases
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "todotxt", "todo.txt", "txt":
+		return TodoTxt, nil
+	case "markdown", "md":
+		return Markdown, nil
+	case "csv":
+		return CSV, nil
+	case "taskwarrior", "tw":
+		return Taskwarrior, nil
+	case "json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want todotxt, markdown, csv, taskwarrior or json)", s)
+}
+
+// Detect guesses the format of a file from its name and contents
+func Detect(path string, data []byte) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return TodoTxt, nil
+	case ".md", ".markdown":
+		return Markdown, nil
+	case ".csv":
+		return CSV, nil
+	case ".json":
+		// Taskwarrior exports an array; our own export is an object
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+			return Taskwarrior, nil
+		}
+		return JSON, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s; pass -format", path)
+}
+
+// Parse reads every item from r in the given format
+func Parse(r io.Reader, f Format) ([]Item, error) {
+	switch f {
+	case TodoTxt:
+		return parseTodoTxt(r)
+	case Markdown:
+		return parseMarkdown(r)
+	case CSV:
+		return parseCSV(r)
+	case Taskwarrior:
+		return parseTaskwarrior(r)
+	case JSON:
+		return parseJSON(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", f)
+}
+
+// letterPriority maps todo.txt style letters onto the 0-10 scale, matching
+// the letters written by the exporter
+func letterPriority(letter string) int {
+	switch strings.ToUpper(letter) {
+	case "A":
+		return 9
+	case "B":
+		return 6
+	case "C":
+		return 4
+	case "D":
+		return 2
+	case "":
+		return 0
+	}
+	return 1
+}
+
+// wordPriority maps named priorities (high/medium/low, H/M/L) onto the 0-10
+// scale
+func wordPriority(word string) (int, bool) {
+	switch strings.ToLower(word) {
+	case "h", "high", "urgent":
+		return 8, true
+	case "m", "medium", "normal":
+		return 5, true
+	case "l", "low":
+		return 2, true
+	}
+	return 0, false
+}
+
+// parseDate accepts the date layouts found in the supported formats
+func parseDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	// Taskwarrior writes UTC times in the iCalendar basic format
+	if t, err := time.ParseInLocation("20060102T150405Z", s, time.UTC); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognised date %q", s)
+}
@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/export"
+)
+
+func parse(t *testing.T, f Format, input string) []Item {
+	t.Helper()
+	items, err := Parse(strings.NewReader(input), f)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", f, err)
+	}
+	return items
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	items := parse(t, TodoTxt, `(A) 2026-01-05 Call Sam +Work +Q1 @phone due:2026-01-10 id:1
+x 2026-01-06 2026-01-02 Pay rent pri:B
+Read https://example.com/article
+Prepare agenda parent:1
+`)
+	if len(items) != 3 {
+		t.Fatalf("items = %+v, want three", items)
+	}
+	call := items[0]
+	if call.Title != "Call Sam" || call.Priority != 9 || call.Category != "Work" {
+		t.Errorf("first item = %+v", call)
+	}
+	if strings.Join(call.Tags, ",") != "Q1,phone" {
+		t.Errorf("tags = %v, want Q1,phone", call.Tags)
+	}
+	if call.DueAt == nil || call.DueAt.Format("2006-01-02") != "2026-01-10" {
+		t.Errorf("due = %v", call.DueAt)
+	}
+	if len(call.Subtasks) != 1 || call.Subtasks[0].Title != "Prepare agenda" {
+		t.Errorf("subtasks = %+v", call.Subtasks)
+	}
+	if rent := items[1]; !rent.Done || rent.Title != "Pay rent" || rent.Priority != 6 {
+		t.Errorf("done item = %+v", rent)
+	}
+	if items[2].Title != "Read https://example.com/article" {
+		t.Errorf("URL mangled: %q", items[2].Title)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	items := parse(t, Markdown, `# Tasks
+
+## Work
+
+- [ ] Write report (P9, due 2026-03-14 17:00) #q1
+  - [x] Outline
+  - [ ] Draft
+  > Use the Q1 numbers
+- [x] Send invoice (to ACME)
+
+## Uncategorized
+
+* [ ] Buy milk
+Some prose that is not a task.
+`)
+	if len(items) != 3 {
+		t.Fatalf("items = %+v, want three", items)
+	}
+	report := items[0]
+	if report.Title != "Write report" || report.Priority != 9 || report.Category != "Work" || report.Notes != "Use the Q1 numbers" {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Subtasks) != 2 || !report.Subtasks[0].Done || report.Subtasks[1].Done {
+		t.Errorf("subtasks = %+v", report.Subtasks)
+	}
+	if len(report.Tags) != 1 || report.Tags[0] != "q1" {
+		t.Errorf("tags = %v", report.Tags)
+	}
+	if items[1].Title != "Send invoice (to ACME)" || !items[1].Done {
+		t.Errorf("unrecognised parenthetical should stay in the title: %+v", items[1])
+	}
+	if items[2].Category != "" {
+		t.Errorf("uncategorized item has category %q", items[2].Category)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	items := parse(t, CSV, `Task,Project,Priority,Due Date,Labels,Status
+Call Sam,Work,high,2026-01-10,"phone, urgent",
+Pay rent,,3,,,done
+`)
+	if len(items) != 2 {
+		t.Fatalf("items = %+v", items)
+	}
+	if it := items[0]; it.Category != "Work" || it.Priority != 8 || len(it.Tags) != 2 || it.DueAt == nil {
+		t.Errorf("first row = %+v", it)
+	}
+	if it := items[1]; !it.Done || it.Priority != 3 {
+		t.Errorf("second row = %+v", it)
+	}
+}
+
+func TestParseCSVRequiresTitle(t *testing.T) {
+	if _, err := Parse(strings.NewReader("foo,bar\n1,2\n"), CSV); err == nil {
+		t.Error("expected an error without a title column")
+	}
+}
+
+func TestParseTaskwarrior(t *testing.T) {
+	items := parse(t, Taskwarrior, `[
+ {"description":"Fix bike","status":"pending","project":"Home","tags":["outside"],"due":"20260110T120000Z","priority":"M",
+  "annotations":[{"entry":"20260101T000000Z","description":"Check the chain"}]},
+ {"description":"Old thing","status":"deleted"},
+ {"description":"Filed taxes","status":"completed"}
+]`)
+	if len(items) != 2 {
+		t.Fatalf("items = %+v, want deleted task skipped", items)
+	}
+	bike := items[0]
+	want := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	if bike.Category != "Home" || bike.Priority != 5 || bike.Notes != "Check the chain" || !bike.DueAt.Equal(want) {
+		t.Errorf("bike = %+v", bike)
+	}
+	if !items[1].Done {
+		t.Error("completed task not marked done")
+	}
+}
+
+// Files written by the exporter read back with the same content
+func TestExportRoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 14, 0, 0, 0, 0, time.Local)
+	notes := "Line one\nLine two"
+	work := api.Category{ID: 3, Name: "Work"}
+	doc := export.NewDocument([]api.Task{{
+		ID: 1, Title: "Write report", Status: "open", Priority: 9, DueAt: &due, Notes: &notes,
+		CategoryID: &work.ID, Category: &work, Tags: []string{"q1"},
+		Subtasks: []api.Subtask{{Title: "Outline", Status: "done"}, {Title: "Draft", Status: "open", Sort: 1}},
+	}}, []api.Category{work})
+
+	for _, f := range []struct {
+		out export.Format
+		in  Format
+	}{{export.JSON, JSON}, {export.Markdown, Markdown}, {export.CSV, CSV}, {export.TodoTxt, TodoTxt}} {
+		var buf bytes.Buffer
+		if err := export.Write(&buf, f.out, doc); err != nil {
+			t.Fatal(err)
+		}
+		items := parse(t, f.in, buf.String())
+		if len(items) != 1 {
+			t.Errorf("%s: items = %+v", f.in, items)
+			continue
+		}
+		it := items[0]
+		if it.Title != "Write report" || it.Priority != 9 || it.Category != "Work" || it.DueAt == nil ||
+			it.DueAt.Format("2006-01-02") != "2026-03-14" || len(it.Subtasks) != 2 || !it.Subtasks[0].Done {
+			t.Errorf("%s: item = %+v", f.in, it)
+		}
+		if f.in != TodoTxt && it.Notes != notes {
+			t.Errorf("%s: notes = %q", f.in, it.Notes)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	cases := map[string]Format{"todo.txt": TodoTxt, "list.md": Markdown, "x.csv": CSV}
+	for path, want := range cases {
+		if got, _ := Detect(path, nil); got != want {
+			t.Errorf("Detect(%s) = %s, want %s", path, got, want)
+		}
+	}
+	if got, _ := Detect("tw.json", []byte(" [{}]")); got != Taskwarrior {
+		t.Errorf("array JSON detected as %s", got)
+	}
+	if got, _ := Detect("ours.json", []byte(`{"version":1}`)); got != JSON {
+		t.Errorf("object JSON detected as %s", got)
+	}
+}
+
+func TestBuildPlanSkipsDuplicates(t *testing.T) {
+	due := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	otherDay := due.AddDate(0, 0, 1)
+	existing := []api.Task{{ID: 1, Title: "Call Sam", DueAt: &due}}
+	items := []Item{
+		{Title: "call sam ", DueAt: &due},
+		{Title: "Call Sam", DueAt: &otherDay},
+		{Title: "Buy milk", Category: "Home"},
+		{Title: "Buy milk", Category: "Home"},
+		{Title: "Plan trip", Category: "work"},
+	}
+	plan := BuildPlan(items, existing, []api.Category{{ID: 5, Name: "Work"}})
+
+	var actions []Action
+	for _, s := range plan.Steps {
+		actions = append(actions, s.Action)
+	}
+	want := []Action{SkipDuplicate, Create, Create, SkipDuplicate, Create}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("actions = %v, want %v", actions, want)
+		}
+	}
+	if len(plan.NewCategories) != 1 || plan.NewCategories[0] != "Home" {
+		t.Errorf("new categories = %v, want [Home]", plan.NewCategories)
+	}
+
+	var buf bytes.Buffer
+	plan.WriteTable(&buf)
+	if !strings.Contains(buf.String(), "3 to create, 2 duplicates skipped") {
+		t.Errorf("table summary missing:\n%s", buf.String())
+	}
+}
+
+func TestApply(t *testing.T) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	srv.AddCategory(fakeserver.DemoEmail, "Work", "#45B7D1")
+
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	items := parse(t, Markdown, `## Work
+- [ ] Write report (P7) #q1
+  - [x] Outline
+## Home
+- [x] Fix tap
+`)
+	plan := BuildPlan(items, nil, srv.Categories())
+	res, err := Apply(client, plan)
+	if err != nil || len(res.Errors) > 0 {
+		t.Fatalf("Apply: %v %v", err, res.Errors)
+	}
+	if res.Created != 2 || res.CategoriesCreated != 1 {
+		t.Errorf("result = %+v", res)
+	}
+
+	tasks := srv.Tasks()
+	if len(tasks) != 2 {
+		t.Fatalf("server tasks = %+v", tasks)
+	}
+	report, tap := tasks[0], tasks[1]
+	if report.Category == nil || report.Category.Name != "Work" || report.Priority != 7 || len(report.Tags) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Subtasks) != 1 || report.Subtasks[0].Status != "done" {
+		t.Errorf("report subtasks = %+v", report.Subtasks)
+	}
+	if tap.Status != "done" || tap.Category == nil || tap.Category.Name != "Home" {
+		t.Errorf("tap = %+v", tap)
+	}
+
+	// Importing the same file again creates nothing
+	existing, _ := client.ListAllTasks("all")
+	if again := BuildPlan(items, existing, srv.Categories()); again.Count(Create) != 0 {
+		t.Errorf("second import would create %d tasks", again.Count(Create))
+	}
+}
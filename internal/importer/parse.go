@@ -0,0 +1,447 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/export"
+)
+
+// todo.txt
+
+var (
+	todoPriorityRe = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoDateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// parseTodoTxt reads todo.txt lines. The first +project becomes the
+// category and the rest, with @contexts, become tags. Lines carrying
+// parent:<id> are attached as subtasks of the line with that id:.
+func parseTodoTxt(r io.Reader) ([]Item, error) {
+	type child struct {
+		parent string
+		sub    Subtask
+	}
+	var items []Item
+	var children []child
+	byID := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var item Item
+		if fields[0] == "x" {
+			item.Done = true
+			fields = fields[1:]
+			// Completion and creation dates
+			for n := 0; n < 2 && len(fields) > 0 && todoDateRe.MatchString(fields[0]); n++ {
+				fields = fields[1:]
+			}
+		} else if m := todoPriorityRe.FindStringSubmatch(fields[0]); m != nil {
+			item.Priority = letterPriority(m[1])
+			fields = fields[1:]
+			if len(fields) > 0 && todoDateRe.MatchString(fields[0]) {
+				fields = fields[1:]
+			}
+		} else if todoDateRe.MatchString(fields[0]) {
+			fields = fields[1:]
+		}
+
+		var title []string
+		var id, parent string
+		for _, f := range fields {
+			switch {
+			case len(f) > 1 && f[0] == '+':
+				if item.Category == "" {
+					item.Category = f[1:]
+				} else {
+					item.Tags = append(item.Tags, f[1:])
+				}
+			case len(f) > 1 && f[0] == '@':
+				item.Tags = append(item.Tags, f[1:])
+			default:
+				key, value, ok := todoKeyValue(f)
+				if !ok {
+					title = append(title, f)
+					continue
+				}
+				switch key {
+				case "due":
+					due, err := parseDate(value)
+					if err != nil {
+						return nil, fmt.Errorf("line %d: %w", lineNo, err)
+					}
+					item.DueAt = due
+				case "pri":
+					item.Priority = letterPriority(value)
+				case "id":
+					id = value
+				case "parent":
+					parent = value
+				case "t", "rec", "shared":
+					// Threshold dates, recurrence and sharing are not imported
+				default:
+					title = append(title, f)
+				}
+			}
+		}
+		item.Title = strings.Join(title, " ")
+		if item.Title == "" {
+			continue
+		}
+
+		if parent != "" {
+			children = append(children, child{parent: parent, sub: Subtask{Title: item.Title, Done: item.Done}})
+			continue
+		}
+		if id != "" {
+			byID[id] = len(items)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, c := range children {
+		if i, ok := byID[c.parent]; ok {
+			items[i].Subtasks = append(items[i].Subtasks, c.sub)
+		} else {
+			items = append(items, Item{Title: c.sub.Title, Done: c.sub.Done})
+		}
+	}
+	return items, nil
+}
+
+// todoKeyValue splits a key:value token, ignoring URLs
+func todoKeyValue(f string) (string, string, bool) {
+	key, value, ok := strings.Cut(f, ":")
+	if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// Markdown
+
+var (
+	mdCheckboxRe = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+	mdHeadingRe  = regexp.MustCompile(`^(#+)\s+(.*)$`)
+	mdNoteRe     = regexp.MustCompile(`^\s+> ?(.*)$`)
+	mdDetailsRe  = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
+	mdPriorityRe = regexp.MustCompile(`^P(\d+)$`)
+)
+
+// parseMarkdown reads "- [ ]" checklists. Level two and deeper headings set
+// the category, indented items become subtasks and indented "> " lines
+// become notes. Details written by the exporter, "(P5, due 2026-01-02)",
+// and trailing #tags are picked up too.
+func parseMarkdown(r io.Reader) ([]Item, error) {
+	var items []Item
+	category := ""
+	current := -1
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+
+		if m := mdHeadingRe.FindStringSubmatch(line); m != nil {
+			category = strings.TrimSpace(m[2])
+			if len(m[1]) == 1 || category == "Uncategorized" {
+				category = ""
+			}
+			current = -1
+			continue
+		}
+
+		if m := mdCheckboxRe.FindStringSubmatch(line); m != nil {
+			done := m[2] != " "
+			text := strings.TrimSpace(m[3])
+			if len(m[1]) >= 2 && current >= 0 {
+				items[current].Subtasks = append(items[current].Subtasks, Subtask{Title: text, Done: done})
+				continue
+			}
+			item := markdownItem(text)
+			item.Done = done
+			item.Category = category
+			items = append(items, item)
+			current = len(items) - 1
+			continue
+		}
+
+		if m := mdNoteRe.FindStringSubmatch(line); m != nil && current >= 0 {
+			if items[current].Notes != "" {
+				items[current].Notes += "\n"
+			}
+			items[current].Notes += m[1]
+		}
+	}
+	return items, scanner.Err()
+}
+
+// markdownItem pulls tags and details off the end of a checklist line
+func markdownItem(text string) Item {
+	var item Item
+	words := strings.Fields(text)
+	for len(words) > 1 && len(words[len(words)-1]) > 1 && words[len(words)-1][0] == '#' {
+		item.Tags = append([]string{words[len(words)-1][1:]}, item.Tags...)
+		words = words[:len(words)-1]
+	}
+	item.Title = strings.Join(words, " ")
+
+	m := mdDetailsRe.FindStringSubmatch(item.Title)
+	if m == nil || m[1] == "" {
+		return item
+	}
+	recognised := false
+	for _, part := range strings.Split(m[2], ", ") {
+		switch {
+		case mdPriorityRe.MatchString(part):
+			p, _ := strconv.Atoi(mdPriorityRe.FindStringSubmatch(part)[1])
+			item.Priority = p
+			recognised = true
+		case strings.HasPrefix(part, "due "):
+			if due, err := parseDate(strings.TrimPrefix(part, "due ")); err == nil {
+				item.DueAt = due
+				recognised = true
+			}
+		case strings.HasPrefix(part, "scheduled "), strings.HasPrefix(part, "shared with "):
+			recognised = true
+		}
+	}
+	if recognised {
+		item.Title = m[1]
+	}
+	return item
+}
+
+// CSV
+
+// csvColumns maps each item field to the header names accepted for it
+var csvColumns = map[string][]string{
+	"title":    {"title", "name", "task", "content", "summary"},
+	"notes":    {"notes", "note", "description", "comments"},
+	"status":   {"status", "done", "completed"},
+	"priority": {"priority", "pri"},
+	"due":      {"due_at", "due", "due date", "due_date", "date"},
+	"category": {"category", "project", "list"},
+	"tags":     {"tags", "labels", "contexts"},
+	"subtasks": {"subtasks", "checklist"},
+	"effort":   {"effort_min", "effort", "estimate"},
+}
+
+// parseCSV reads a CSV file with a header row. Column names are matched
+// case-insensitively against csvColumns.
+func parseCSV(r io.Reader) ([]Item, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for field, aliases := range csvColumns {
+			if _, taken := index[field]; taken {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					index[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, fmt.Errorf("no title column in CSV header %v", records[0])
+	}
+
+	var items []Item
+	for n, rec := range records[1:] {
+		get := func(field string) string {
+			if i, ok := index[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		item := Item{Title: get("title"), Notes: get("notes"), Category: get("category")}
+		if item.Title == "" {
+			continue
+		}
+		switch strings.ToLower(get("status")) {
+		case "done", "completed", "x", "true", "yes", "1":
+			item.Done = true
+		}
+		if p := get("priority"); p != "" {
+			if v, err := strconv.Atoi(p); err == nil {
+				item.Priority = clampPriority(v)
+			} else if v, ok := wordPriority(p); ok {
+				item.Priority = v
+			} else {
+				item.Priority = letterPriority(p)
+			}
+		}
+		due, err := parseDate(get("due"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", n+2, err)
+		}
+		item.DueAt = due
+		item.Tags = splitList(get("tags"))
+		for _, s := range splitList(get("subtasks")) {
+			item.Subtasks = append(item.Subtasks, checklistEntry(s))
+		}
+		if e := get("effort"); e != "" {
+			item.EffortMin, _ = strconv.Atoi(e)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// splitList splits a ";" or "," separated cell
+func splitList(s string) []string {
+	sep := ","
+	if strings.Contains(s, ";") {
+		sep = ";"
+	}
+	var out []string
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// checklistEntry parses "[x] Title" or "[ ] Title"
+func checklistEntry(s string) Subtask {
+	switch {
+	case strings.HasPrefix(s, "[x] "), strings.HasPrefix(s, "[X] "):
+		return Subtask{Title: strings.TrimSpace(s[4:]), Done: true}
+	case strings.HasPrefix(s, "[ ] "):
+		return Subtask{Title: strings.TrimSpace(s[4:])}
+	}
+	return Subtask{Title: s}
+}
+
+func clampPriority(p int) int {
+	if p < 0 {
+		return 0
+	}
+	if p > 10 {
+		return 10
+	}
+	return p
+}
+
+// Taskwarrior
+
+// twTask is the subset of a Taskwarrior export that is imported
+type twTask struct {
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Due         string   `json:"due"`
+	Priority    string   `json:"priority"`
+	Annotations []struct {
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// parseTaskwarrior reads the output of "task export". Deleted tasks and
+// recurrence templates are skipped; annotations become notes.
+func parseTaskwarrior(r io.Reader) ([]Item, error) {
+	var tasks []twTask
+	if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("invalid Taskwarrior export: %w", err)
+	}
+
+	var items []Item
+	for _, t := range tasks {
+		if t.Status == "deleted" || t.Status == "recurring" || strings.TrimSpace(t.Description) == "" {
+			continue
+		}
+		item := Item{
+			Title:    strings.TrimSpace(t.Description),
+			Done:     t.Status == "completed",
+			Category: t.Project,
+			Tags:     t.Tags,
+		}
+		item.Priority, _ = wordPriority(t.Priority)
+		due, err := parseDate(t.Due)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item.Title, err)
+		}
+		item.DueAt = due
+		var notes []string
+		for _, a := range t.Annotations {
+			notes = append(notes, a.Description)
+		}
+		item.Notes = strings.Join(notes, "\n")
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Our own JSON export
+
+// parseJSON reads a lossless export written by the export package
+func parseJSON(r io.Reader) ([]Item, error) {
+	doc, err := export.ReadJSON(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	for _, c := range doc.Categories {
+		names[c.ID] = c.Name
+	}
+
+	var items []Item
+	for _, t := range doc.Tasks {
+		item := Item{
+			Title:     t.Title,
+			Done:      t.Status == "done",
+			Priority:  t.Priority,
+			EffortMin: t.EffortMin,
+			DueAt:     t.DueAt,
+			Tags:      t.Tags,
+		}
+		if t.Notes != nil {
+			item.Notes = *t.Notes
+		}
+		if t.Category != nil {
+			item.Category = t.Category.Name
+		} else if t.CategoryID != nil {
+			item.Category = names[*t.CategoryID]
+		}
+		subtasks := append([]api.Subtask(nil), t.Subtasks...)
+		sort.SliceStable(subtasks, func(i, j int) bool { return subtasks[i].Sort < subtasks[j].Sort })
+		for _, st := range subtasks {
+			item.Subtasks = append(item.Subtasks, Subtask{Title: st.Title, Done: st.Status == "done"})
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,236 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Action says what will happen to an imported item
+type Action int
+
+const (
+	Create Action = iota
+	SkipDuplicate
+)
+
+func (a Action) String() string {
+	if a == SkipDuplicate {
+		return "skip (duplicate)"
+	}
+	return "create"
+}
+
+// Step is one item of a plan together with its action
+type Step struct {
+	Item   Item
+	Action Action
+}
+
+// Plan is the result of checking parsed items against the server
+type Plan struct {
+	Steps []Step
+	// NewCategories lists categories that will be created, in first-use order
+	NewCategories []string
+	// categories maps lower-cased names of existing categories to their IDs
+	categories map[string]int
+}
+
+// CategoryColors are used in turn for categories created by an import
+var CategoryColors = []string{"#FF6B6B", "#4ECDC4", "#45B7D1", "#96CEB4", "#FFEAA7", "#DDA0DD", "#98D8C8", "#F7DC6F"}
+
+// dedupeKey identifies a task by its title and due date
+func dedupeKey(title string, due string) string {
+	return strings.ToLower(strings.TrimSpace(title)) + "\x00" + due
+}
+
+func itemKey(it Item) string {
+	due := ""
+	if it.DueAt != nil {
+		due = it.DueAt.Local().Format("2006-01-02")
+	}
+	return dedupeKey(it.Title, due)
+}
+
+func taskKey(t api.Task) string {
+	due := ""
+	if t.DueAt != nil {
+		due = t.DueAt.Local().Format("2006-01-02")
+	}
+	return dedupeKey(t.Title, due)
+}
+
+// BuildPlan decides which items to create. Items whose title and due date
+// match an existing task, or an earlier item in the same file, are skipped.
+func BuildPlan(items []Item, existing []api.Task, categories []api.Category) Plan {
+	plan := Plan{categories: make(map[string]int)}
+	for _, c := range categories {
+		plan.categories[strings.ToLower(c.Name)] = c.ID
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range existing {
+		seen[taskKey(t)] = true
+	}
+
+	newCats := make(map[string]bool)
+	for _, it := range items {
+		key := itemKey(it)
+		if seen[key] {
+			plan.Steps = append(plan.Steps, Step{Item: it, Action: SkipDuplicate})
+			continue
+		}
+		seen[key] = true
+		plan.Steps = append(plan.Steps, Step{Item: it, Action: Create})
+
+		name := strings.ToLower(it.Category)
+		if it.Category == "" || newCats[name] {
+			continue
+		}
+		if _, ok := plan.categories[name]; !ok {
+			newCats[name] = true
+			plan.NewCategories = append(plan.NewCategories, it.Category)
+		}
+	}
+	return plan
+}
+
+// Count returns the number of steps with the given action
+func (p Plan) Count(a Action) int {
+	n := 0
+	for _, s := range p.Steps {
+		if s.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTable prints the plan as a preview table
+func (p Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tTITLE\tSTATUS\tPRI\tDUE\tCATEGORY\tTAGS\tSUBTASKS")
+	for _, s := range p.Steps {
+		it := s.Item
+		status := "open"
+		if it.Done {
+			status = "done"
+		}
+		due := "-"
+		if it.DueAt != nil {
+			due = it.DueAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%d\n",
+			s.Action, truncate(it.Title, 40), status, it.Priority, due,
+			orDash(it.Category), orDash(strings.Join(it.Tags, ",")), len(it.Subtasks))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(p.NewCategories) > 0 {
+		fmt.Fprintf(w, "\nNew categories: %s\n", strings.Join(p.NewCategories, ", "))
+	}
+	_, err := fmt.Fprintf(w, "\n%d to create, %d duplicates skipped\n", p.Count(Create), p.Count(SkipDuplicate))
+	return err
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Result summarises an applied plan
+type Result struct {
+	Created           int
+	Skipped           int
+	CategoriesCreated int
+	// Errors holds per-item failures; the import carries on past them
+	Errors []error
+}
+
+// Apply creates the plan's categories, tasks and subtasks. It stops only if
+// a category cannot be created; failures for single tasks are collected in
+// the result.
+func Apply(client *api.Client, plan Plan) (Result, error) {
+	res := Result{Skipped: plan.Count(SkipDuplicate)}
+
+	for i, name := range plan.NewCategories {
+		cat, err := client.CreateCategory(name, CategoryColors[i%len(CategoryColors)])
+		if err != nil {
+			return res, fmt.Errorf("creating category %q: %w", name, err)
+		}
+		plan.categories[strings.ToLower(name)] = cat.ID
+		res.CategoriesCreated++
+	}
+
+	for _, s := range plan.Steps {
+		if s.Action != Create {
+			continue
+		}
+		if err := createItem(client, s.Item, plan.categories); err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("%s: %w", s.Item.Title, err))
+			continue
+		}
+		res.Created++
+	}
+	return res, nil
+}
+
+// createItem creates one task with its subtasks and completion state
+func createItem(client *api.Client, it Item, categories map[string]int) error {
+	req := api.TaskCreateRequest{Title: it.Title, DueAt: it.DueAt, Tags: it.Tags}
+	if it.Notes != "" {
+		notes := it.Notes
+		req.Notes = &notes
+	}
+	if it.Priority > 0 {
+		p := it.Priority
+		req.Priority = &p
+	}
+	if it.EffortMin > 0 {
+		e := it.EffortMin
+		req.EffortMin = &e
+	}
+	if id, ok := categories[strings.ToLower(it.Category)]; ok && it.Category != "" {
+		req.CategoryID = &id
+	}
+
+	task, err := client.CreateTask(req)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range it.Subtasks {
+		sub, err := client.CreateSubtask(task.ID, st.Title)
+		if err != nil {
+			return err
+		}
+		if st.Done {
+			done := "done"
+			if err := client.UpdateSubtask(sub.ID, api.SubtaskUpdateRequest{Status: &done}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if it.Done {
+		done := "done"
+		if _, err := client.UpdateTask(task.ID, api.TaskUpdateRequest{Status: &done}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,441 @@
+// Package keymap holds the TUI's key bindings. Every action has a name
+// used in keymap.json, default keys, a description for the help screen and
+// the screens it is active on; keys only conflict within a screen.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Context is a set of screens
+type Context uint8
+
+const (
+	// Browse is the task list
+	Browse Context = 1 << iota
+	// Detail is the task detail view
+	Detail
+	// Picker covers the category, template and export lists
+	Picker
+	// Review is the breakdown review
+	Review
+	// Help is the help screen
+	Help
+)
+
+var contextNames = []struct {
+	c    Context
+	name string
+}{
+	{Browse, "task list"},
+	{Detail, "task details"},
+	{Picker, "pickers"},
+	{Review, "breakdown review"},
+	{Help, "help"},
+}
+
+func (c Context) String() string {
+	var names []string
+	for _, n := range contextNames {
+		if c&n.c != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// KeyMap is the set of bindings the TUI matches key presses against
+type KeyMap struct {
+	Up, Down           key.Binding
+	PrevPage, NextPage key.Binding
+	PageUp, PageDown   key.Binding
+	First, Last        key.Binding
+	SwitchView         key.Binding
+	Open               key.Binding
+	Expand             key.Binding
+	Section            key.Binding
+
+	New               key.Binding
+	NewFromTemplate   key.Binding
+	Edit              key.Binding
+	EditNotes         key.Binding
+	EditNotesExternal key.Binding
+	EditDocument      key.Binding
+	ToggleDone        key.Binding
+	Delete            key.Binding
+	Category          key.Binding
+	NewCategory       key.Binding
+	Breakdown         key.Binding
+	UndoBreakdown     key.Binding
+	Repeat            key.Binding
+	Pin               key.Binding
+	Timer             key.Binding
+	Pomodoro          key.Binding
+	Reminders         key.Binding
+	Plan              key.Binding
+	Link              key.Binding
+	Defer             key.Binding
+
+	Theme      key.Binding
+	Sort       key.Binding
+	Group      key.Binding
+	Animations key.Binding
+	Stats      key.Binding
+
+	Export  key.Binding
+	Help    key.Binding
+	Logout  key.Binding
+	Refresh key.Binding
+	Quit    key.Binding
+
+	Select    key.Binding
+	Back      key.Binding
+	ToggleAll key.Binding
+	MoveUp    key.Binding
+	MoveDown  key.Binding
+	AddItem   key.Binding
+	Drop      key.Binding
+}
+
+// action describes one binding
+type action struct {
+	name    string
+	section string
+	desc    string
+	ctx     Context
+	keys    []string
+	field   func(*KeyMap) *key.Binding
+}
+
+// Help screen sections, in order
+const (
+	SectionNavigation = "Navigation"
+	SectionTasks      = "Task Management"
+	SectionDisplay    = "Display"
+	SectionOther      = "Other"
+	SectionLists      = "Lists and Review"
+)
+
+// Sections lists the help screen sections in order
+var Sections = []string{SectionNavigation, SectionTasks, SectionDisplay, SectionOther, SectionLists}
+
+var actions = []action{
+	{"up", SectionNavigation, "Move up", Browse | Picker | Review, []string{"up", "k"}, func(k *KeyMap) *key.Binding { return &k.Up }},
+	{"down", SectionNavigation, "Move down", Browse | Picker | Review, []string{"down", "j"}, func(k *KeyMap) *key.Binding { return &k.Down }},
+	{"prev_page", SectionNavigation, "Previous page", Browse, []string{"left", "h"}, func(k *KeyMap) *key.Binding { return &k.PrevPage }},
+	{"next_page", SectionNavigation, "Next page", Browse, []string{"right", "l"}, func(k *KeyMap) *key.Binding { return &k.NextPage }},
+	{"page_up", SectionNavigation, "Jump a page up", Browse, []string{"pgup"}, func(k *KeyMap) *key.Binding { return &k.PageUp }},
+	{"page_down", SectionNavigation, "Jump a page down", Browse, []string{"pgdown"}, func(k *KeyMap) *key.Binding { return &k.PageDown }},
+	{"first", SectionNavigation, "First task", Browse, []string{"home"}, func(k *KeyMap) *key.Binding { return &k.First }},
+	{"last", SectionNavigation, "Last task", Browse, []string{"end"}, func(k *KeyMap) *key.Binding { return &k.Last }},
+	{"switch_view", SectionNavigation, "Cycle views (Open/Completed/Shared/Today)", Browse, []string{"tab"}, func(k *KeyMap) *key.Binding { return &k.SwitchView }},
+	{"open", SectionNavigation, "Open task details", Browse, []string{"enter"}, func(k *KeyMap) *key.Binding { return &k.Open }},
+	{"expand", SectionNavigation, "Expand/collapse task", Browse, []string{"v"}, func(k *KeyMap) *key.Binding { return &k.Expand }},
+	{"section", SectionNavigation, "Collapse/expand the section", Browse, []string{"z"}, func(k *KeyMap) *key.Binding { return &k.Section }},
+
+	{"new", SectionTasks, "New task", Browse, []string{"n"}, func(k *KeyMap) *key.Binding { return &k.New }},
+	{"new_from_template", SectionTasks, "New task from a template", Browse, []string{"T"}, func(k *KeyMap) *key.Binding { return &k.NewFromTemplate }},
+	{"edit", SectionTasks, "Edit task title", Browse | Detail | Review, []string{"e"}, func(k *KeyMap) *key.Binding { return &k.Edit }},
+	{"edit_notes", SectionTasks, "Edit task notes", Browse, []string{"E"}, func(k *KeyMap) *key.Binding { return &k.EditNotes }},
+	{"edit_notes_external", SectionTasks, "Edit task notes in $EDITOR", Browse | Detail, []string{"N"}, func(k *KeyMap) *key.Binding { return &k.EditNotesExternal }},
+	{"edit_document", SectionTasks, "Edit the whole task in $EDITOR", Browse | Detail, []string{"ctrl+e"}, func(k *KeyMap) *key.Binding { return &k.EditDocument }},
+	{"toggle_done", SectionTasks, "Toggle task done/open", Browse | Detail, []string{" "}, func(k *KeyMap) *key.Binding { return &k.ToggleDone }},
+	{"delete", SectionTasks, "Delete task", Browse, []string{"d"}, func(k *KeyMap) *key.Binding { return &k.Delete }},
+	{"category", SectionTasks, "Change category", Browse | Detail, []string{"c"}, func(k *KeyMap) *key.Binding { return &k.Category }},
+	{"new_category", SectionTasks, "Create new category", Browse | Picker, []string{"C"}, func(k *KeyMap) *key.Binding { return &k.NewCategory }},
+	{"breakdown", SectionTasks, "AI breakdown (review before keeping)", Browse | Detail, []string{"b"}, func(k *KeyMap) *key.Binding { return &k.Breakdown }},
+	{"undo_breakdown", SectionTasks, "Undo the last breakdown", Browse, []string{"u"}, func(k *KeyMap) *key.Binding { return &k.UndoBreakdown }},
+	{"repeat", SectionTasks, "Set how the task repeats", Browse | Detail, []string{"@"}, func(k *KeyMap) *key.Binding { return &k.Repeat }},
+	{"pin", SectionTasks, "Pin to Today, or unpin", Browse, []string{"p"}, func(k *KeyMap) *key.Binding { return &k.Pin }},
+	{"defer", SectionTasks, "Defer to tomorrow", Browse, []string{">"}, func(k *KeyMap) *key.Binding { return &k.Defer }},
+	{"timer", SectionTasks, "Start or stop the timer", Browse | Detail, []string{"w"}, func(k *KeyMap) *key.Binding { return &k.Timer }},
+	{"pomodoro", SectionTasks, "Start or stop a pomodoro timer", Browse | Detail, []string{"W"}, func(k *KeyMap) *key.Binding { return &k.Pomodoro }},
+	{"reminders", SectionTasks, "Toggle due date reminders", Browse | Detail, []string{"!"}, func(k *KeyMap) *key.Binding { return &k.Reminders }},
+	{"link", SectionTasks, "Link or unlink a task this one waits on", Browse | Detail, []string{"B"}, func(k *KeyMap) *key.Binding { return &k.Link }},
+	{"plan", SectionTasks, "Plan the coming days into time blocks", Browse, []string{"P"}, func(k *KeyMap) *key.Binding { return &k.Plan }},
+
+	{"theme", SectionDisplay, "Choose a theme", Browse, []string{"t"}, func(k *KeyMap) *key.Binding { return &k.Theme }},
+	{"sort", SectionDisplay, "Cycle sort modes", Browse, []string{"s"}, func(k *KeyMap) *key.Binding { return &k.Sort }},
+	{"group", SectionDisplay, "Cycle grouping (category/priority/due/owner)", Browse, []string{"m"}, func(k *KeyMap) *key.Binding { return &k.Group }},
+	{"animations", SectionDisplay, "Preview the completion animations", Browse, []string{"A"}, func(k *KeyMap) *key.Binding { return &k.Animations }},
+	{"stats", SectionDisplay, "Show statistics", Browse, []string{"S"}, func(k *KeyMap) *key.Binding { return &k.Stats }},
+
+	{"export", SectionOther, "Export tasks", Browse, []string{"x"}, func(k *KeyMap) *key.Binding { return &k.Export }},
+	{"help", SectionOther, "Toggle this help", Browse | Help, []string{"?"}, func(k *KeyMap) *key.Binding { return &k.Help }},
+	{"logout", SectionOther, "Logout", Browse, []string{"L"}, func(k *KeyMap) *key.Binding { return &k.Logout }},
+	{"refresh", SectionOther, "Refresh tasks", Browse, []string{"r", "R"}, func(k *KeyMap) *key.Binding { return &k.Refresh }},
+	{"quit", SectionOther, "Quit", Browse, []string{"q", "ctrl+c"}, func(k *KeyMap) *key.Binding { return &k.Quit }},
+
+	{"select", SectionLists, "Choose / keep", Picker | Review, []string{"enter"}, func(k *KeyMap) *key.Binding { return &k.Select }},
+	{"back", SectionLists, "Cancel/back", Detail | Picker | Review | Help, []string{"esc", "q"}, func(k *KeyMap) *key.Binding { return &k.Back }},
+	{"toggle_all", SectionLists, "Export: all tasks or this view", Picker, []string{"a"}, func(k *KeyMap) *key.Binding { return &k.ToggleAll }},
+	{"move_up", SectionLists, "Review: move subtask up", Review, []string{"K", "shift+up"}, func(k *KeyMap) *key.Binding { return &k.MoveUp }},
+	{"move_down", SectionLists, "Review: move subtask down", Review, []string{"J", "shift+down"}, func(k *KeyMap) *key.Binding { return &k.MoveDown }},
+	{"add_item", SectionLists, "Review: add a subtask", Review, []string{"a"}, func(k *KeyMap) *key.Binding { return &k.AddItem }},
+	{"drop", SectionLists, "Review: drop a subtask or time block", Review, []string{"d", "x"}, func(k *KeyMap) *key.Binding { return &k.Drop }},
+}
+
+// Presets change some defaults for people used to another editor's keys
+var Presets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"first":     {"g", "home"},
+		"last":      {"G", "end"},
+		"page_up":   {"ctrl+u", "pgup"},
+		"page_down": {"ctrl+d", "pgdown"},
+		"new":       {"o", "n"},
+		"edit":      {"i", "e"},
+	},
+	"emacs": {
+		"up":        {"ctrl+p", "up"},
+		"down":      {"ctrl+n", "down"},
+		"prev_page": {"ctrl+b", "left"},
+		"next_page": {"ctrl+f", "right"},
+		"page_up":   {"alt+v", "pgup"},
+		"page_down": {"ctrl+v", "pgdown"},
+		"first":     {"alt+<", "home"},
+		"last":      {"alt+>", "end"},
+		"back":      {"ctrl+g", "esc", "q"},
+	},
+}
+
+// Default returns the built-in bindings
+func Default() KeyMap {
+	var k KeyMap
+	for _, a := range actions {
+		*a.field(&k) = binding(a, a.keys)
+	}
+	return k
+}
+
+// New builds a keymap from a preset ("" for the default) and per-action
+// overrides, which replace the action's keys; an empty list unbinds it.
+// Unknown names, unknown keys and conflicting bindings are errors.
+func New(preset string, overrides map[string][]string) (KeyMap, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	changes, ok := Presets[preset]
+	if !ok {
+		return Default(), fmt.Errorf("unknown keymap preset %q (choose %s)", preset, strings.Join(PresetNames(), ", "))
+	}
+
+	k := Default()
+	var errs []string
+	apply := func(set map[string][]string) {
+		names := make([]string, 0, len(set))
+		for name := range set {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			a, ok := find(name)
+			if !ok {
+				errs = append(errs, fmt.Sprintf("unknown action %q", name))
+				continue
+			}
+			keys, err := normalize(set[name])
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			*a.field(&k) = binding(a, keys)
+		}
+	}
+	apply(changes)
+	apply(overrides)
+
+	for _, c := range k.Conflicts() {
+		errs = append(errs, c.String())
+	}
+	if len(errs) > 0 {
+		return Default(), fmt.Errorf("keymap: %s", strings.Join(errs, "; "))
+	}
+	return k, nil
+}
+
+// PresetNames returns the preset names, sorted
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func find(name string) (action, bool) {
+	for _, a := range actions {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return action{}, false
+}
+
+func binding(a action, keys []string) key.Binding {
+	b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(label(keys), a.desc))
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// Conflict is a key bound to more than one action on the same screen
+type Conflict struct {
+	Key     string
+	Actions []string
+	Context Context
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s is bound to both %s in the %s", Pretty(c.Key), strings.Join(c.Actions, " and "), c.Context)
+}
+
+// Conflicts finds keys that do two things on one screen
+func (k KeyMap) Conflicts() []Conflict {
+	var out []Conflict
+	for _, cn := range contextNames {
+		byKey := make(map[string][]string)
+		var order []string
+		for _, a := range actions {
+			if a.ctx&cn.c == 0 {
+				continue
+			}
+			for _, key := range a.field(&k).Keys() {
+				if _, ok := byKey[key]; !ok {
+					order = append(order, key)
+				}
+				byKey[key] = append(byKey[key], a.name)
+			}
+		}
+		for _, key := range order {
+			if len(byKey[key]) > 1 {
+				out = append(out, Conflict{Key: key, Actions: byKey[key], Context: cn.c})
+			}
+		}
+	}
+	return out
+}
+
+// Entry is a line of the help screen
+type Entry struct {
+	Keys string
+	Desc string
+}
+
+// HelpSection returns the help lines for a section, skipping unbound
+// actions
+func (k KeyMap) HelpSection(section string) []Entry {
+	var out []Entry
+	for _, a := range actions {
+		b := a.field(&k)
+		if a.section != section || !b.Enabled() {
+			continue
+		}
+		out = append(out, Entry{Keys: b.Help().Key, Desc: b.Help().Desc})
+	}
+	return out
+}
+
+// Key returns the first key of a binding for display, e.g. "Space"
+func Key(b key.Binding) string {
+	if keys := b.Keys(); len(keys) > 0 {
+		return Pretty(keys[0])
+	}
+	return ""
+}
+
+// Hint formats a status bar hint such as "e: Edit", or returns "" when the
+// binding has no keys
+func Hint(b key.Binding, label string) string {
+	if !b.Enabled() {
+		return ""
+	}
+	return Key(b) + ": " + label
+}
+
+// Hints joins the non-empty hints with " | "
+func Hints(hints ...string) string {
+	var parts []string
+	for _, h := range hints {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// label lists keys for the help screen, e.g. "Up, k"
+func label(keys []string) string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = Pretty(k)
+	}
+	return strings.Join(out, ", ")
+}
+
+// Pretty formats a key name for display: "ctrl+e" becomes "Ctrl+E", " "
+// becomes "Space" and single characters stay as they are
+func Pretty(k string) string {
+	if k == " " {
+		return "Space"
+	}
+	if len([]rune(k)) == 1 {
+		return k
+	}
+	if special, ok := prettyNames[k]; ok {
+		return special
+	}
+	parts := strings.Split(k, "+")
+	for i, p := range parts {
+		if special, ok := prettyNames[p]; ok {
+			parts[i] = special
+		} else if len([]rune(p)) == 1 {
+			parts[i] = strings.ToUpper(p)
+		} else {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+var prettyNames = map[string]string{
+	"pgup": "PgUp", "pgdown": "PgDown", "esc": "Esc", "enter": "Enter",
+	"tab": "Tab", "shift+tab": "Shift+Tab", "home": "Home", "end": "End",
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"backspace": "Backspace", "delete": "Delete", "<": "<", ">": ">",
+}
+
+// namedKeys are the multi-character key names Bubble Tea reports
+var namedKeys = map[string]bool{
+	"up": true, "down": true, "left": true, "right": true, "enter": true,
+	"esc": true, "tab": true, "shift+tab": true, "backspace": true,
+	"delete": true, "insert": true, "home": true, "end": true, "pgup": true,
+	"pgdown": true, "shift+up": true, "shift+down": true, "shift+left": true,
+	"shift+right": true, "ctrl+up": true, "ctrl+down": true,
+	"ctrl+left": true, "ctrl+right": true, "ctrl+home": true, "ctrl+end": true,
+}
+
+// normalize checks key names, accepting "space" for the space bar
+func normalize(keys []string) ([]string, error) {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		switch {
+		case k == "space":
+			k = " "
+		case len([]rune(k)) == 1, namedKeys[k]:
+		case strings.HasPrefix(k, "ctrl+") && len([]rune(k)) == 6,
+			strings.HasPrefix(k, "alt+") && len([]rune(k)) == 5:
+		case len(k) >= 2 && len(k) <= 3 && k[0] == 'f' && k[1] >= '1' && k[1] <= '9':
+		default:
+			return nil, fmt.Errorf("unknown key %q", k)
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
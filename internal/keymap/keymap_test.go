@@ -0,0 +1,74 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+func TestDefaultHasNoConflicts(t *testing.T) {
+	for _, name := range PresetNames() {
+		if _, err := New(name, nil); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	k, err := New("vim", map[string][]string{"delete": {"D"}, "undo_breakdown": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	press := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")}
+	if !key.Matches(press, k.Delete) {
+		t.Error("override not applied")
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")}, k.Last) {
+		t.Error("vim preset not applied")
+	}
+	if k.UndoBreakdown.Enabled() {
+		t.Error("empty override did not unbind")
+	}
+	for _, e := range k.HelpSection(SectionTasks) {
+		if strings.Contains(e.Desc, "Undo") {
+			t.Error("unbound action listed in help")
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	_, err := New("", map[string][]string{"delete": {"x"}})
+	if err == nil || !strings.Contains(err.Error(), "x is bound to both delete and export in the task list") {
+		t.Errorf("err = %v", err)
+	}
+
+	// The same key on different screens is fine
+	if _, err := New("", map[string][]string{"drop": {"x"}}); err != nil {
+		t.Error(err)
+	}
+
+	for _, bad := range []map[string][]string{
+		{"fly": {"f"}},
+		{"delete": {"hyper+d"}},
+	} {
+		if _, err := New("", bad); err == nil {
+			t.Errorf("%v accepted", bad)
+		}
+	}
+	if _, err := New("nano", nil); err == nil {
+		t.Error("unknown preset accepted")
+	}
+}
+
+func TestPretty(t *testing.T) {
+	for in, want := range map[string]string{" ": "Space", "ctrl+e": "Ctrl+E", "pgdown": "PgDown", "shift+up": "Shift+Up", "T": "T"} {
+		if got := Pretty(in); got != want {
+			t.Errorf("Pretty(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := Hints(Hint(Default().Edit, "Edit"), Hint(key.NewBinding(), "None"), Hint(Default().ToggleDone, "Done")); got != "e: Edit | Space: Done" {
+		t.Errorf("Hints = %q", got)
+	}
+}
@@ -0,0 +1,191 @@
+// Package markdown renders the small subset of Markdown people write in task
+// notes for the terminal: headings, lists, checkboxes, quotes, code, links
+// and emphasis. Anything it does not recognise is shown as typed.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles are applied to each kind of element
+type Styles struct {
+	Text    lipgloss.Style
+	Heading lipgloss.Style
+	Bold    lipgloss.Style
+	Italic  lipgloss.Style
+	Code    lipgloss.Style
+	Link    lipgloss.Style
+	Quote   lipgloss.Style
+	Marker  lipgloss.Style
+	Checked lipgloss.Style
+}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRe    = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	listRe    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	checkRe   = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	quoteRe   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	fenceRe   = regexp.MustCompile("^\\s*(```|~~~)")
+
+	inlineRe = regexp.MustCompile("`([^`]+)`" +
+		`|\[([^\]]+)\]\(([^)\s]+)\)` +
+		`|\*\*([^*]+)\*\*|__([^_]+)__` +
+		`|\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b` +
+		`|(https?://[^\s)]+)`)
+)
+
+// Render formats src, wrapping it to width columns. A width of zero or less
+// leaves long lines alone. Line breaks in src are kept.
+func Render(src string, width int, st Styles) string {
+	var out []string
+	var fence string
+	blank := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil && (fence == "" || m[1] == fence) {
+			if fence == "" {
+				fence = m[1]
+			} else {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			out = append(out, wrap("  ", st.Code.Render(line), width))
+			continue
+		}
+
+		if line == "" {
+			// Collapse runs of blank lines into one
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		switch {
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			text := m[2]
+			if len(m[1]) == 1 {
+				text = strings.ToUpper(text)
+			}
+			out = append(out, wrap("", st.Heading.Render(plain(text)), width))
+
+		case ruleRe.MatchString(line):
+			n := width
+			if n <= 0 {
+				n = 20
+			}
+			out = append(out, st.Marker.Render(strings.Repeat("─", n)))
+
+		case listRe.MatchString(line):
+			m := listRe.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(strings.ReplaceAll(m[1], "\t", "  "))/2*2)
+			marker, text := "•", m[3]
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				marker = m[2]
+			}
+			if c := checkRe.FindStringSubmatch(text); c != nil {
+				if c[1] == " " {
+					marker, text = "☐", inline(c[2], st)
+				} else {
+					marker, text = "☑", st.Checked.Render(plain(c[2]))
+				}
+			} else {
+				text = inline(text, st)
+			}
+			out = append(out, wrap(indent+st.Marker.Render(marker)+" ", text, width))
+
+		case quoteRe.MatchString(line):
+			m := quoteRe.FindStringSubmatch(line)
+			out = append(out, wrap(st.Marker.Render("│")+" ", st.Quote.Render(plain(m[1])), width))
+
+		default:
+			out = append(out, wrap("", inline(line, st), width))
+		}
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// inline styles code spans, links, emphasis and bare URLs within a line
+func inline(s string, st Styles) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range inlineRe.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			b.WriteString(st.Text.Render(s[last:m[0]]))
+		}
+		group := func(i int) string { return s[m[2*i]:m[2*i+1]] }
+		switch {
+		case m[2] >= 0:
+			b.WriteString(st.Code.Render(group(1)))
+		case m[4] >= 0:
+			text, url := group(2), group(3)
+			b.WriteString(st.Link.Render(text))
+			if text != url {
+				b.WriteString(st.Text.Render(" (" + url + ")"))
+			}
+		case m[8] >= 0:
+			b.WriteString(st.Bold.Render(group(4)))
+		case m[10] >= 0:
+			b.WriteString(st.Bold.Render(group(5)))
+		case m[12] >= 0:
+			b.WriteString(st.Italic.Render(group(6)))
+		case m[14] >= 0:
+			b.WriteString(st.Italic.Render(group(7)))
+		default:
+			b.WriteString(st.Link.Render(group(8)))
+		}
+		last = m[1]
+	}
+	if last < len(s) {
+		b.WriteString(st.Text.Render(s[last:]))
+	}
+	return b.String()
+}
+
+// plain strips inline markup, for elements that have a style of their own
+func plain(s string) string {
+	return inlineRe.ReplaceAllStringFunc(s, func(match string) string {
+		m := inlineRe.FindStringSubmatch(match)
+		for _, g := range []int{1, 2, 4, 5, 6, 7, 8} {
+			if m[g] != "" {
+				return m[g]
+			}
+		}
+		return match
+	})
+}
+
+// wrap fits text into width columns after prefix, indenting continuation
+// lines to line up with the first
+func wrap(prefix, text string, width int) string {
+	pw := lipgloss.Width(prefix)
+	if width <= 0 || width-pw < 1 {
+		return prefix + text
+	}
+	body := lipgloss.NewStyle().Width(width - pw).Render(text)
+	lines := strings.Split(body, "\n")
+	pad := strings.Repeat(" ", pw)
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	src := strings.Join([]string{
+		"# Plan",
+		"Call **Sam** about the `invoice`, see [the doc](https://example.com/doc).",
+		"",
+		"",
+		"- [ ] book room",
+		"- [x] send *agenda*",
+		"  - nested",
+		"1. first",
+		"> quoted _text_",
+		"```",
+		"go test ./...",
+		"```",
+		"---",
+	}, "\n")
+
+	want := strings.Join([]string{
+		"PLAN",
+		"Call Sam about the invoice, see the doc (https://example.com/doc).",
+		"",
+		"☐ book room",
+		"☑ send agenda",
+		"  • nested",
+		"1. first",
+		"│ quoted text",
+		"  go test ./...",
+		strings.Repeat("─", 70),
+	}, "\n")
+
+	if got := Render(src, 70, Styles{}); got != want {
+		t.Errorf("Render =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderWraps(t *testing.T) {
+	got := Render("- one two three four five", 12, Styles{})
+	want := "• one two\n  three four\n  five"
+	if got != want {
+		t.Errorf("Render =\n%q\nwant\n%q", got, want)
+	}
+
+	// Without a width, lines are left alone
+	if got := Render("just a line *not closed", 0, Styles{}); got != "just a line *not closed" {
+		t.Errorf("Render = %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/anim"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// loadAccessibility applies the motion and marker settings. It returns a
+// problem worth showing, if any.
+func (m *Model) loadAccessibility(cfg *config.Config) []string {
+	m.Animation = cfg.Animation
+	m.TextMarkers = cfg.TextMarkers || cfg.ScreenReader
+	if cfg.ScreenReader {
+		m.Animation = "off"
+	}
+
+	var problems []string
+	switch m.Animation {
+	case "", "random", "off":
+	default:
+		if _, ok := anim.Default.Get(m.Animation); !ok {
+			problems = append(problems, fmt.Sprintf("Unknown animation %q, picking at random", m.Animation))
+			m.Animation = "random"
+		}
+	}
+	if len(cfg.Animations) > 0 {
+		picker, err := anim.Default.Picker(cfg.Animations)
+		if err != nil {
+			problems = append(problems, "Ignoring animation weights: "+err.Error())
+		} else {
+			m.anims = picker
+		}
+	}
+	if m.reducedMotion() {
+		m.Spinner.Spinner = spinner.Spinner{Frames: []string{"..."}, FPS: time.Second}
+	}
+	return problems
+}
+
+// reducedMotion reports whether animations are turned off
+func (m Model) reducedMotion() bool {
+	return m.Animation == "off"
+}
+
+// nextAnim picks the completion animation to play, or returns nil when
+// animations are off
+func (m Model) nextAnim() anim.Animation {
+	switch m.Animation {
+	case "off":
+		return nil
+	case "", "random":
+		return m.anims.Next()
+	}
+	a, _ := anim.Default.Get(m.Animation)
+	return a
+}
+
+// priorityLevel names the band a priority falls in, which sets its color
+func priorityLevel(p int) string {
+	switch {
+	case p >= 8:
+		return "high"
+	case p >= 5:
+		return "med"
+	}
+	return "low"
+}
+
+// priorityBadge renders e.g. "P9", or "P9 high" with text markers
+func (m Model) priorityBadge(p int) string {
+	style := styles.PriorityLowStyle
+	switch priorityLevel(p) {
+	case "high":
+		style = styles.PriorityHighStyle
+	case "med":
+		style = styles.PriorityMedStyle
+	}
+	text := fmt.Sprintf("P%d", p)
+	if m.TextMarkers {
+		text += " " + priorityLevel(p)
+	}
+	return style.Render(text)
+}
+
+// categoryBadge renders a category in its color, or in brackets with text
+// markers
+func (m Model) categoryBadge(c *api.Category, t themes.Theme) string {
+	if m.TextMarkers {
+		return lipgloss.NewStyle().Foreground(t.Fg).Render("[" + c.Name + "]")
+	}
+	return lipgloss.NewStyle().
+		Foreground(t.BadgeFg).
+		Background(lipgloss.Color(c.Color)).
+		Padding(0, 1).
+		Render(c.Name)
+}
+
+// dueBadge renders a task's due date. Overdue dates blink unless motion is
+// reduced, and say so with text markers.
+func (m Model) dueBadge(task Task) string {
+	due := formatDue(*task.DueAt)
+	if !isOverdue(task) {
+		return styles.DueStyle.Render(due)
+	}
+	style := styles.OverdueStyle
+	if m.reducedMotion() || m.TextMarkers {
+		style = style.UnsetBlink()
+	}
+	if m.TextMarkers {
+		due = "OVERDUE " + due
+	}
+	return style.Render(due)
+}
+
+func isOverdue(task Task) bool {
+	return task.DueAt != nil && task.DueAt.Before(time.Now()) && task.Status != "done"
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+func TestTextMarkers(t *testing.T) {
+	h := newHarness(t)
+	h.m.TextMarkers = true
+	view := h.m.View()
+	for _, want := range []string{"P9 high", "P2 low", "[Work]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q", want)
+		}
+	}
+}
+
+func TestReducedMotion(t *testing.T) {
+	h := newHarness(t)
+	h.m.Animation = "off"
+
+	h.selectTitle("Buy milk")
+	h.queue("space")
+	if h.m.CurrentTask().IsAnimatingCheck {
+		t.Error("completion animated")
+	}
+	h.settle()
+
+	h.selectTitle("Fix tap")
+	h.queue("d", "y")
+	for _, task := range h.m.Tasks {
+		if task.Title == "Fix tap" {
+			t.Error("deleted task still shown while the request runs")
+		}
+	}
+	h.settle()
+}
+
+func TestAnimationSetting(t *testing.T) {
+	cfg := config.ForDataDir(t.TempDir())
+	client := api.NewClient(cfg)
+
+	cfg.Animation = "matrix"
+	m := NewModel(client, cfg)
+	if a := m.nextAnim(); a == nil || a.Name() != "matrix" {
+		t.Errorf("nextAnim = %v", a)
+	}
+
+	cfg.Animation = "fireworks"
+	m = NewModel(client, cfg)
+	if m.Animation != "random" || !strings.Contains(m.ErrorMsg, "fireworks") {
+		t.Errorf("animation %q, status %q", m.Animation, m.ErrorMsg)
+	}
+
+	cfg.Animation = "random"
+	cfg.Animations = map[string]int{"sparkle": 0, "glitch": 2}
+	m = NewModel(client, cfg)
+	if m.anims.Weight("sparkle") != 0 || m.anims.Weight("glitch") != 2 || m.anims.Weight("matrix") != 1 {
+		t.Errorf("weights sparkle %d, glitch %d, matrix %d",
+			m.anims.Weight("sparkle"), m.anims.Weight("glitch"), m.anims.Weight("matrix"))
+	}
+	for range 50 {
+		if a := m.nextAnim(); a == nil || a.Name() == "sparkle" {
+			t.Fatalf("nextAnim = %v with sparkle off", a)
+		}
+	}
+
+	cfg.Animations = map[string]int{"fireworks": 3}
+	m = NewModel(client, cfg)
+	if !strings.Contains(m.ErrorMsg, "fireworks") {
+		t.Errorf("status %q", m.ErrorMsg)
+	}
+}
+
+func TestAnimationPreview(t *testing.T) {
+	h := newHarness(t)
+	// The preview ticks until it is closed, so only settle after esc
+	h.queue("A")
+	if h.m.State != StateAnimations {
+		t.Fatalf("state = %v", h.m.State)
+	}
+	h.queue("down")
+	if h.m.animCursor != 1 || !strings.Contains(h.m.View(), "matrix") {
+		t.Errorf("cursor %d", h.m.animCursor)
+	}
+	h.press("esc")
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v after esc", h.m.State)
+	}
+}
+
+func TestPlainAnnouncesChanges(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Write report")
+	p := NewPlain(h.m)
+
+	next, cmd := p.Update(keyMsg("enter"))
+	p = next.(Plain)
+	if cmd == nil || !strings.HasPrefix(p.last.Screen, "Task details. Write report, open, priority 9, high, category Work") ||
+		!strings.Contains(p.last.Screen, "Subtask 2: Draft, done") {
+		t.Errorf("screen = %q", p.last.Screen)
+	}
+	if p.View() != "" {
+		t.Errorf("plain view draws %q", p.View())
+	}
+
+	next, _ = p.Update(keyMsg("esc"))
+	p = next.(Plain)
+	if !strings.Contains(p.last.Focus, "Write report") {
+		t.Errorf("focus = %q", p.last.Focus)
+	}
+}
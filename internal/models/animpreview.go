@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/anim"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// animPause is how long the preview shows a finished title before playing
+// the animation again
+const animPause = 600 * time.Millisecond
+
+// openAnimations shows the animation preview and starts it playing
+func (m *Model) openAnimations() tea.Cmd {
+	m.State = StateAnimations
+	m.animStart = time.Now()
+	return TickCmd()
+}
+
+// updateAnimations handles input on the animation preview
+func (m Model) updateAnimations(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Back):
+		m.State = StateBrowse
+
+	case key.Matches(msg, m.Keys.Up):
+		if m.animCursor > 0 {
+			m.animCursor--
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if m.animCursor < len(anim.Default.All())-1 {
+			m.animCursor++
+		}
+
+	case key.Matches(msg, m.Keys.Select):
+		m.animStart = time.Now()
+	}
+	return m, nil
+}
+
+// animWeight says how often an animation plays with the current settings
+func (m Model) animWeight(a anim.Animation) string {
+	switch m.Animation {
+	case "off":
+		return "off"
+	case "", "random":
+		if w := m.anims.Weight(a.Name()); w > 0 {
+			return fmt.Sprintf("weight %d", w)
+		}
+		return "off"
+	}
+	if strings.EqualFold(m.Animation, a.Name()) {
+		return "always"
+	}
+	return "off"
+}
+
+// viewAnimations lists the animations, each playing on a sample title
+func (m Model) viewAnimations(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// ANIMATIONS"))
+
+	sample := "Write the quarterly report"
+	if task := m.CurrentTask(); task != nil {
+		sample = task.Title
+	}
+
+	all := anim.Default.All()
+	containerHeight := m.Height - 7
+	rows := max(containerHeight-2, 1)
+	first := 0
+	if m.animCursor >= rows {
+		first = m.animCursor - rows + 1
+	}
+
+	elapsed := time.Since(m.animStart)
+	var list strings.Builder
+	for i := first; i < len(all) && i < first+rows; i++ {
+		a := all[i]
+		info := fmt.Sprintf("%-16s%5dms  %-10s", a.Name(), a.Duration().Milliseconds(), m.animWeight(a))
+		cycle := a.Duration() + animPause
+		at := elapsed % cycle
+		title := styles.StrikeStyle.Render(sample)
+		if at < a.Duration() {
+			title = a.Frame(sample, float64(at)/float64(a.Duration()), t)
+		}
+		if i == m.animCursor {
+			list.WriteString(lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("> " + info))
+		} else {
+			list.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Render("  " + info))
+		}
+		list.WriteString(title + "\n")
+	}
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1).
+		Render(list.String())
+
+	help := keymap.Hints(keymap.Hint(m.Keys.Select, "Replay"), keymap.Hint(m.Keys.Back, "Back"))
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
@@ -0,0 +1,462 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// The server writes AI subtasks straight onto the task. They are held back
+// for review: the task stays pending so refreshes and live events leave it
+// alone, and whatever the user drops or edits is corrected on the server
+// once they accept. Discarding deletes everything that was generated.
+
+// BreakdownProposedMsg carries the subtasks the AI generated for a task
+type BreakdownProposedMsg struct {
+	ID       int
+	Seq      int
+	Proposed []api.Subtask
+	Err      error
+}
+
+// BreakdownAppliedMsg is sent once an accepted or discarded breakdown has
+// been written back. Added lists the subtasks that were kept.
+type BreakdownAppliedMsg struct {
+	ID        int
+	Task      *api.Task
+	Added     []int
+	Discarded bool
+	Undone    bool
+	Err       error
+}
+
+// reviewItem is one proposed subtask; ID is zero for an item the user added
+type reviewItem struct {
+	ID    int
+	Title string
+	// Original is the generated title, to tell whether it was edited
+	Original string
+}
+
+// breakdownState is the breakdown under review
+type breakdownState struct {
+	TaskID int
+	Seq    int
+	Busy   bool
+	// Cancelled is set when the user leaves before the AI has answered
+	Cancelled bool
+	Items     []reviewItem
+	Dropped   []int
+	Cursor    int
+	Editing   bool
+	// Existing is how many subtasks the task had before the breakdown
+	Existing int
+}
+
+// lastBreakdown remembers an accepted breakdown so it can be undone
+type lastBreakdown struct {
+	TaskID     int
+	SubtaskIDs []int
+}
+
+// startBreakdown asks the AI for subtasks and opens the review screen
+func (m *Model) startBreakdown(t *Task) tea.Cmd {
+	if m.breakdown.TaskID != 0 {
+		return m.setError("A breakdown is already in progress")
+	}
+	if m.IsPending(t.ID) {
+		return m.setError("Wait for the task to finish saving")
+	}
+	m.beginEdit(t)
+	m.breakdownSeq++
+	m.breakdown = breakdownState{TaskID: t.ID, Seq: m.breakdownSeq, Busy: true, Existing: len(t.Subtasks)}
+	m.SelectedTaskID = t.ID
+	m.PreviousState = m.State
+	m.State = StateBreakdown
+
+	known := make(map[int]bool)
+	for _, st := range t.Subtasks {
+		known[st.ID] = true
+	}
+	id, seq := t.ID, m.breakdownSeq
+	fetch := func() tea.Msg {
+		if err := m.Client.BreakdownTask(id); err != nil {
+			return BreakdownProposedMsg{ID: id, Seq: seq, Err: err}
+		}
+		task, err := m.Client.GetTask(id)
+		if err != nil {
+			return BreakdownProposedMsg{ID: id, Seq: seq, Err: err}
+		}
+		var proposed []api.Subtask
+		for _, st := range task.Subtasks {
+			if !known[st.ID] {
+				proposed = append(proposed, st)
+			}
+		}
+		return BreakdownProposedMsg{ID: id, Seq: seq, Proposed: proposed}
+	}
+	return tea.Batch(m.Spinner.Tick, fetch)
+}
+
+// reviewCreated opens the review screen for a task created with a
+// breakdown request, whose subtasks all came from the AI
+func (m *Model) reviewCreated(task api.Task) {
+	t := m.TaskByID(task.ID)
+	if t == nil || len(task.Subtasks) == 0 || m.State != StateBrowse || m.breakdown.TaskID != 0 {
+		return
+	}
+	m.beginEdit(t)
+	m.breakdownSeq++
+	m.breakdown = breakdownState{TaskID: task.ID, Seq: m.breakdownSeq}
+	m.breakdown.propose(task.Subtasks)
+	m.SelectedTaskID = task.ID
+	m.PreviousState = StateBrowse
+	m.State = StateBreakdown
+}
+
+func (b *breakdownState) propose(subtasks []api.Subtask) {
+	b.Busy = false
+	b.Items = nil
+	for _, st := range subtasks {
+		b.Items = append(b.Items, reviewItem{ID: st.ID, Title: st.Title, Original: st.Title})
+	}
+}
+
+// handleBreakdownProposed fills the review screen with the AI's subtasks
+func (m *Model) handleBreakdownProposed(msg BreakdownProposedMsg) tea.Cmd {
+	if msg.Seq != m.breakdown.Seq {
+		return nil
+	}
+	if msg.Err != nil {
+		m.finishEdit(msg.ID, nil, msg.Err)
+		m.breakdown = breakdownState{}
+		if m.State == StateBreakdown {
+			m.State = m.PreviousState
+		}
+		return m.setError("Breakdown failed: " + msg.Err.Error())
+	}
+	if m.breakdown.Cancelled {
+		m.breakdown.propose(msg.Proposed)
+		return m.discardBreakdown()
+	}
+	m.breakdown.propose(msg.Proposed)
+	if len(m.breakdown.Items) == 0 {
+		m.finishEdit(msg.ID, nil, nil)
+		m.breakdown = breakdownState{}
+		m.State = m.PreviousState
+		return m.setError("The AI suggested no subtasks")
+	}
+	return nil
+}
+
+// updateBreakdown handles input on the review screen
+func (m Model) updateBreakdown(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.breakdown
+	if b.Busy {
+		if key.Matches(msg, m.Keys.Back) {
+			// The result is discarded when it arrives
+			b.Cancelled = true
+			m.State = m.PreviousState
+		}
+		return m, nil
+	}
+
+	if b.Editing {
+		switch msg.String() {
+		case "esc", "enter":
+			title := strings.TrimSpace(m.ReviewInput.Value())
+			b.Editing = false
+			m.ReviewInput.Blur()
+			item := &b.Items[b.Cursor]
+			if msg.String() == "enter" && title != "" {
+				item.Title = title
+			} else if item.Title == "" {
+				// A new item left empty is dropped
+				b.Items = append(b.Items[:b.Cursor], b.Items[b.Cursor+1:]...)
+				b.clampCursor()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.ReviewInput, cmd = m.ReviewInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.Keys.Up):
+		if b.Cursor > 0 {
+			b.Cursor--
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if b.Cursor < len(b.Items)-1 {
+			b.Cursor++
+		}
+
+	case key.Matches(msg, m.Keys.MoveUp):
+		if b.Cursor > 0 {
+			b.Items[b.Cursor-1], b.Items[b.Cursor] = b.Items[b.Cursor], b.Items[b.Cursor-1]
+			b.Cursor--
+		}
+
+	case key.Matches(msg, m.Keys.MoveDown):
+		if b.Cursor < len(b.Items)-1 {
+			b.Items[b.Cursor+1], b.Items[b.Cursor] = b.Items[b.Cursor], b.Items[b.Cursor+1]
+			b.Cursor++
+		}
+
+	case key.Matches(msg, m.Keys.Edit):
+		if b.Cursor < len(b.Items) {
+			b.Editing = true
+			m.ReviewInput.SetValue(b.Items[b.Cursor].Title)
+			m.ReviewInput.Focus()
+			m.ReviewInput.CursorEnd()
+			return m, textinput.Blink
+		}
+
+	case key.Matches(msg, m.Keys.AddItem):
+		b.Items = append(b.Items, reviewItem{})
+		b.Cursor = len(b.Items) - 1
+		b.Editing = true
+		m.ReviewInput.SetValue("")
+		m.ReviewInput.Focus()
+		return m, textinput.Blink
+
+	case key.Matches(msg, m.Keys.Drop):
+		if b.Cursor < len(b.Items) {
+			if id := b.Items[b.Cursor].ID; id != 0 {
+				b.Dropped = append(b.Dropped, id)
+			}
+			b.Items = append(b.Items[:b.Cursor], b.Items[b.Cursor+1:]...)
+			b.clampCursor()
+		}
+
+	case key.Matches(msg, m.Keys.Select):
+		m.State = m.PreviousState
+		m.Loading = true
+		return m, m.acceptBreakdown()
+
+	case key.Matches(msg, m.Keys.Back):
+		m.State = m.PreviousState
+		m.Loading = true
+		return m, m.discardBreakdown()
+	}
+	return m, nil
+}
+
+func (b *breakdownState) clampCursor() {
+	if b.Cursor >= len(b.Items) {
+		b.Cursor = len(b.Items) - 1
+	}
+	if b.Cursor < 0 {
+		b.Cursor = 0
+	}
+}
+
+// acceptBreakdown writes the reviewed list back: dropped subtasks are
+// deleted, edited ones renamed, added ones created and all put in order
+func (m *Model) acceptBreakdown() tea.Cmd {
+	b := m.breakdown
+	items := append([]reviewItem(nil), b.Items...)
+	m.breakdown = breakdownState{}
+	client := m.Client
+
+	return func() tea.Msg {
+		msg := BreakdownAppliedMsg{ID: b.TaskID}
+		for _, id := range b.Dropped {
+			if err := client.DeleteSubtask(id); err != nil {
+				msg.Err = err
+				return msg
+			}
+		}
+		for i, it := range items {
+			id := it.ID
+			if id == 0 {
+				sub, err := client.CreateSubtask(b.TaskID, it.Title)
+				if err != nil {
+					msg.Err = err
+					break
+				}
+				id = sub.ID
+			}
+			req := api.SubtaskUpdateRequest{}
+			sort := b.Existing + i
+			req.Sort = &sort
+			if it.ID != 0 && it.Title != it.Original {
+				title := it.Title
+				req.Title = &title
+			}
+			if err := client.UpdateSubtask(id, req); err != nil {
+				msg.Err = err
+				break
+			}
+			msg.Added = append(msg.Added, id)
+		}
+		task, err := client.GetTask(b.TaskID)
+		if msg.Err == nil {
+			msg.Err = err
+		}
+		msg.Task = task
+		return msg
+	}
+}
+
+// discardBreakdown deletes every generated subtask
+func (m *Model) discardBreakdown() tea.Cmd {
+	b := m.breakdown
+	m.breakdown = breakdownState{}
+	ids := append([]int(nil), b.Dropped...)
+	for _, it := range b.Items {
+		if it.ID != 0 {
+			ids = append(ids, it.ID)
+		}
+	}
+	return m.deleteSubtasks(b.TaskID, ids, false)
+}
+
+// deleteSubtasks removes subtasks from a task, either discarding a
+// breakdown under review or undoing an accepted one
+func (m Model) deleteSubtasks(taskID int, ids []int, undo bool) tea.Cmd {
+	client := m.Client
+	return func() tea.Msg {
+		msg := BreakdownAppliedMsg{ID: taskID, Discarded: !undo, Undone: undo}
+		for _, id := range ids {
+			if err := client.DeleteSubtask(id); err != nil {
+				msg.Err = err
+				break
+			}
+		}
+		task, err := client.GetTask(taskID)
+		if msg.Err == nil {
+			msg.Err = err
+		}
+		msg.Task = task
+		return msg
+	}
+}
+
+// handleBreakdownApplied settles the task and offers an undo
+func (m *Model) handleBreakdownApplied(msg BreakdownAppliedMsg) tea.Cmd {
+	m.Loading = false
+	if msg.Task != nil {
+		m.finishEdit(msg.ID, msg.Task, nil)
+	} else {
+		m.finishEdit(msg.ID, nil, msg.Err)
+	}
+	if msg.Err != nil {
+		return m.setError("Breakdown: " + msg.Err.Error())
+	}
+	if msg.Undone {
+		m.undo = lastBreakdown{}
+		return m.setSuccess("Breakdown undone")
+	}
+	if msg.Discarded {
+		return m.setSuccess("Breakdown discarded")
+	}
+	m.undo = lastBreakdown{TaskID: msg.ID, SubtaskIDs: msg.Added}
+	text := fmt.Sprintf("Added %d subtasks", len(msg.Added))
+	if m.Keys.UndoBreakdown.Enabled() {
+		text += fmt.Sprintf(" (%s to undo)", keymap.Key(m.Keys.UndoBreakdown))
+	}
+	return m.setSuccess(text)
+}
+
+// undoBreakdown removes the subtasks of the last accepted breakdown
+func (m *Model) undoBreakdown() tea.Cmd {
+	u := m.undo
+	t := m.TaskByID(u.TaskID)
+	if t == nil || len(u.SubtaskIDs) == 0 {
+		return m.setError("Nothing to undo")
+	}
+	m.beginEdit(t)
+	drop := make(map[int]bool)
+	for _, id := range u.SubtaskIDs {
+		drop[id] = true
+	}
+	var kept []api.Subtask
+	for _, st := range t.Subtasks {
+		if !drop[st.ID] {
+			kept = append(kept, st)
+		}
+	}
+	t.Subtasks = kept
+	m.Loading = true
+	return m.deleteSubtasks(u.TaskID, u.SubtaskIDs, true)
+}
+
+// viewBreakdown renders the review screen
+func (m Model) viewBreakdown(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// REVIEW BREAKDOWN"))
+
+	var s strings.Builder
+	if task := m.SelectedTask(); task != nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Bold(true).Render(task.Title))
+		s.WriteString("\n\n")
+	}
+
+	b := m.breakdown
+	k := m.Keys
+	move := ""
+	if k.MoveDown.Enabled() && k.MoveUp.Enabled() {
+		move = keymap.Key(k.MoveDown) + "/" + keymap.Key(k.MoveUp) + ": Move"
+	}
+	help := keymap.Hints(keymap.Hint(k.Select, "Accept"), keymap.Hint(k.Edit, "Edit"), keymap.Hint(k.AddItem, "Add"),
+		keymap.Hint(k.Drop, "Drop"), move, keymap.Hint(k.Back, "Discard"))
+	if b.Busy {
+		s.WriteString(m.Spinner.View() + " Breaking the task down...")
+		help = keymap.Hint(k.Back, "Cancel")
+	} else if len(b.Items) == 0 {
+		s.WriteString(lipgloss.NewStyle().Foreground(t.Dim).Render(fmt.Sprintf("All suggestions dropped. Press '%s' to add one.", keymap.Key(k.AddItem))))
+	}
+	for i, it := range b.Items {
+		if b.Editing && i == b.Cursor {
+			s.WriteString("  [ ] " + styles.InlineInputStyle.Render(m.ReviewInput.View()) + "\n")
+			continue
+		}
+		row := "  [ ] " + it.Title
+		switch {
+		case it.ID == 0:
+			row += " (new)"
+		case it.Title != it.Original:
+			row += " (edited)"
+		}
+		if i == b.Cursor {
+			s.WriteString(styles.ListSelectedStyle.Render(row))
+		} else {
+			s.WriteString(styles.ListItemStyle.Render(row))
+		}
+		s.WriteString("\n")
+	}
+	if b.Editing {
+		help = "Enter: Save | Esc: Cancel"
+	}
+
+	containerHeight := m.Height - 7
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1).
+		Render(s.String())
+
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
+
+// newSpinner creates the spinner shown while the AI is working
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot))
+}
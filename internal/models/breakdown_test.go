@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/keymap"
+)
+
+// subtaskTitles returns the server's subtask titles for a task, in order
+func (h *harness) subtaskTitles(title string) string {
+	st := h.serverTask(title)
+	if st == nil {
+		h.t.Fatalf("no server task %q", title)
+	}
+	var out []string
+	for _, s := range st.Subtasks {
+		out = append(out, s.Title)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestBreakdownReviewAndUndo(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	h.press("b")
+	if h.m.State != StateBreakdown || len(h.m.breakdown.Items) != 3 {
+		t.Fatalf("state = %v, items = %+v", h.m.State, h.m.breakdown.Items)
+	}
+
+	// Drop "Plan", edit "Do", add "Pay" and move it above "Review"
+	h.press("d", "e")
+	h.typeText(" today")
+	h.press("enter", "a")
+	h.typeText("Pay")
+	h.press("enter", "K", "enter")
+
+	if got := h.subtaskTitles("Buy milk"); got != "Do: Buy milk today,Pay,Review: Buy milk" {
+		t.Errorf("server subtasks = %s", got)
+	}
+	h.selectTitle("Buy milk")
+	if n := len(h.m.CurrentTask().Subtasks); n != 3 {
+		t.Errorf("local subtasks = %d, want 3", n)
+	}
+	if !strings.Contains(h.m.SuccessMsg, "u to undo") {
+		t.Errorf("status = %q", h.m.SuccessMsg)
+	}
+
+	h.press("u")
+	if got := h.subtaskTitles("Buy milk"); got != "" {
+		t.Errorf("after undo server subtasks = %s", got)
+	}
+	h.selectTitle("Buy milk")
+	if n := len(h.m.CurrentTask().Subtasks); n != 0 {
+		t.Errorf("after undo local subtasks = %d", n)
+	}
+}
+
+func TestBreakdownDiscard(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Write report")
+
+	h.press("b", "esc")
+
+	if got := h.subtaskTitles("Write report"); got != "Outline,Draft" {
+		t.Errorf("server subtasks = %s, want the original two", got)
+	}
+	if h.m.State != StateBrowse || h.m.IsPending(h.serverTask("Write report").ID) {
+		t.Errorf("state = %v, task still pending", h.m.State)
+	}
+}
+
+func TestBreakdownUndoHintFollowsKeymap(t *testing.T) {
+	for _, tt := range []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"U"}, "Added 3 subtasks (U to undo)"},
+		{[]string{}, "Added 3 subtasks"},
+	} {
+		h := newHarness(t)
+		keys, err := keymap.New("default", map[string][]string{"undo_breakdown": tt.keys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.m.Keys = keys
+		h.selectTitle("Buy milk")
+		h.press("b", "enter")
+		if h.m.SuccessMsg != tt.want {
+			t.Errorf("undo bound to %q: status = %q, want %q", tt.keys, h.m.SuccessMsg, tt.want)
+		}
+	}
+}
+
+func TestBreakdownAtCreation(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("n")
+	h.typeText("Plan trip")
+	h.press("enter", "ctrl+b", "enter")
+
+	if h.m.State != StateBreakdown || len(h.m.breakdown.Items) != 3 {
+		t.Fatalf("state = %v, items = %+v", h.m.State, h.m.breakdown.Items)
+	}
+	h.press("d", "enter")
+	if got := h.subtaskTitles("Plan trip"); got != "Do: Plan trip,Review: Plan trip" {
+		t.Errorf("server subtasks = %s", got)
+	}
+}
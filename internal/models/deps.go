@@ -0,0 +1,205 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/deps"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// knownTask is how a task was last seen
+type knownTask struct {
+	title string
+	open  bool
+}
+
+// startLink asks for the task that t waits on; the next Enter in the list
+// links or unlinks it
+func (m *Model) startLink(t *Task) tea.Cmd {
+	if m.Deps == nil {
+		return m.setError("Dependencies are not available")
+	}
+	m.linkFrom = t.ID
+	m.State = StateBrowse
+	return nil
+}
+
+// toggleLink makes the linking task wait on blocker, or stops it waiting
+func (m *Model) toggleLink(blocker *Task) tea.Cmd {
+	id := m.linkFrom
+	m.linkFrom = 0
+	title := m.taskTitle(id)
+	for _, b := range m.Deps.BlockedBy(id) {
+		if b == blocker.ID {
+			if err := m.Deps.Remove(id, b); err != nil {
+				return m.setError("Dependency not saved: " + err.Error())
+			}
+			return m.setSuccess(fmt.Sprintf("%s no longer waits on %s", title, blocker.Title))
+		}
+	}
+	if err := m.Deps.Add(id, blocker.ID); err != nil {
+		if errors.Is(err, deps.ErrCycle) {
+			return m.setError(fmt.Sprintf("%s already waits on %s", blocker.Title, title))
+		}
+		return m.setError("Dependency not saved: " + err.Error())
+	}
+	return m.setSuccess(fmt.Sprintf("%s now waits on %s", title, blocker.Title))
+}
+
+// linkHelp is the status bar while picking a blocker
+func (m Model) linkHelp() string {
+	return fmt.Sprintf("What does %s wait on? %s", m.taskTitle(m.linkFrom),
+		keymap.Hints(keymap.Hint(m.Keys.Open, "Link/unlink"), keymap.Hint(m.Keys.Back, "Cancel")))
+}
+
+// isOpen reports whether a task was open when last seen
+func (m Model) isOpen(id int) bool {
+	return m.known[id].open
+}
+
+// isBlocked reports whether a task waits on one that is still open
+func (m Model) isBlocked(id int) bool {
+	return m.Deps.Blocked(id, m.isOpen)
+}
+
+// taskTitle names a task by ID, from whatever has been loaded
+func (m Model) taskTitle(id int) string {
+	if k, ok := m.known[id]; ok {
+		return k.title
+	}
+	if t := m.TaskByID(id); t != nil {
+		return t.Title
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// trackStatus records which of the tasks are open; full means tasks is
+// every open task. It announces tasks a completed blocker has unblocked.
+func (m *Model) trackStatus(tasks []api.Task, full bool) tea.Cmd {
+	return m.watchBlocked(func() {
+		if m.known == nil {
+			m.known = make(map[int]knownTask, len(tasks))
+		}
+		if full {
+			// Tasks missing from a full list are done or deleted
+			for id, k := range m.known {
+				m.known[id] = knownTask{title: k.title}
+			}
+		}
+		for _, t := range tasks {
+			m.known[t.ID] = knownTask{title: t.Title, open: t.Status != "done"}
+		}
+	})
+}
+
+// forgetTask drops a deleted task and its links
+func (m *Model) forgetTask(id int) tea.Cmd {
+	return m.watchBlocked(func() {
+		delete(m.known, id)
+		if err := m.Deps.Forget(id); err != nil {
+			m.ErrorMsg = "Dependencies not saved: " + err.Error()
+		}
+	})
+}
+
+// watchBlocked makes a change and then notes the open tasks that are no
+// longer blocked, refreshing the Today list they now belong on
+func (m *Model) watchBlocked(change func()) tea.Cmd {
+	var before []int
+	for _, id := range m.Deps.Waiting() {
+		if m.isBlocked(id) {
+			before = append(before, id)
+		}
+	}
+	change()
+
+	var freed []string
+	for _, id := range before {
+		if m.isOpen(id) && !m.isBlocked(id) {
+			freed = append(freed, m.taskTitle(id))
+		}
+	}
+	if len(freed) == 0 {
+		return nil
+	}
+	sort.Strings(freed)
+	cmd := m.setSuccess("Unblocked: " + strings.Join(freed, ", "))
+	if m.ViewMode == ViewToday {
+		cmd = tea.Batch(cmd, m.loadTasks())
+	}
+	return cmd
+}
+
+// withoutBlocked leaves out tasks that wait on open ones
+func (m Model) withoutBlocked(tasks []api.Task) []api.Task {
+	out := tasks[:0:0]
+	for _, t := range tasks {
+		if !m.isBlocked(t.ID) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// viewDeps renders the detail view's dependency tree: what the task waits
+// on, what those wait on, and what waits on it
+func (m Model) viewDeps(task Task, t themes.Theme) string {
+	tree := m.Deps.Tree(task.ID)
+	blocks := m.Deps.Blocks(task.ID)
+	if len(tree) == 0 && len(blocks) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	if len(tree) > 0 {
+		label := "Waits on:"
+		if m.isBlocked(task.ID) {
+			label = lipgloss.NewStyle().Foreground(t.Warning).Render("Blocked, waits on:")
+		}
+		s.WriteString(label + "\n")
+		var walk func(nodes []deps.Node, depth int)
+		walk = func(nodes []deps.Node, depth int) {
+			for _, n := range nodes {
+				s.WriteString(strings.Repeat("  ", depth+1) + m.depLine(n.ID, t) + "\n")
+				walk(n.Children, depth+1)
+			}
+		}
+		walk(tree, 0)
+	}
+	if len(blocks) > 0 {
+		s.WriteString("Blocks:\n")
+		for _, id := range blocks {
+			s.WriteString("  " + m.depLine(id, t) + "\n")
+		}
+	}
+	return s.String()
+}
+
+// depLine is one task in a dependency tree, with whether it is done
+func (m Model) depLine(id int, t themes.Theme) string {
+	if m.isOpen(id) {
+		return lipgloss.NewStyle().Foreground(t.Fg).Render("○ " + m.taskTitle(id))
+	}
+	return styles.HelpStyle.Render("✓ " + m.taskTitle(id))
+}
+
+// depsPlain describes a task's blockers for plain mode
+func (m Model) depsPlain(id int) string {
+	var open []string
+	for _, b := range m.Deps.BlockedBy(id) {
+		if m.isOpen(b) {
+			open = append(open, m.taskTitle(b))
+		}
+	}
+	if len(open) == 0 {
+		return ""
+	}
+	return "blocked by " + strings.Join(open, ", ")
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+	h.press("B")
+	if !strings.Contains(h.m.View(), "What does Fix tap wait on?") {
+		t.Errorf("no prompt:\n%s", h.m.View())
+	}
+	h.selectTitle("Buy milk")
+	h.press("enter")
+	if h.m.SuccessMsg != "Fix tap now waits on Buy milk" || h.m.State != StateBrowse {
+		t.Fatalf("toast = %q, state = %v", h.m.SuccessMsg, h.m.State)
+	}
+	if !h.m.isBlocked(h.serverTask("Fix tap").ID) || !strings.Contains(h.m.View(), "⊘") {
+		t.Errorf("Fix tap not shown blocked:\n%s", h.m.View())
+	}
+
+	// The reverse link would go round
+	h.press("B")
+	h.selectTitle("Fix tap")
+	h.press("enter")
+	if h.m.ErrorMsg != "Fix tap already waits on Buy milk" {
+		t.Errorf("cycle error = %q", h.m.ErrorMsg)
+	}
+
+	// Blocked tasks stay off Today until the blocker is done
+	h.selectTitle("Fix tap")
+	h.press("p")
+	h.selectTitle("Buy milk")
+	h.press("p", "tab", "tab", "tab")
+	if got := strings.Join(h.titles(), ", "); got != "Buy milk" {
+		t.Errorf("today = %s", got)
+	}
+	h.selectTitle("Buy milk")
+	h.press(" ")
+	if h.m.SuccessMsg != "Unblocked: Fix tap" {
+		t.Errorf("toast = %q", h.m.SuccessMsg)
+	}
+	if got := strings.Join(h.titles(), ", "); !strings.Contains(got, "Fix tap") {
+		t.Errorf("today after unblocking = %s", got)
+	}
+
+	h.selectTitle("Fix tap")
+	h.press("enter")
+	view := h.m.View()
+	if !strings.Contains(view, "Waits on:") || !strings.Contains(view, "✓ Buy milk") {
+		t.Errorf("detail view:\n%s", view)
+	}
+}
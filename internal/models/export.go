@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/export"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// ExportedMsg is sent when an export file has been written
+type ExportedMsg struct {
+	Path  string
+	Count int
+	Err   error
+}
+
+// exportTasks writes either the loaded view or every task to a new file in
+// the export directory
+func (m Model) exportTasks(format export.Format, all bool) tea.Cmd {
+	var tasks []api.Task
+	for _, t := range m.Tasks {
+		if t.ID > 0 {
+			tasks = append(tasks, t.Task)
+		}
+	}
+	categories := append([]api.Category(nil), m.Categories...)
+	dir := ""
+	if m.Config != nil {
+		dir = m.Config.ExportDir
+	}
+	client := m.Client
+
+	return func() tea.Msg {
+		doc := export.NewDocument(tasks, categories)
+		if all {
+			var err error
+			doc, err = export.Fetch(client, api.TaskListParams{Scope: "all"})
+			if err != nil {
+				return ExportedMsg{Err: err}
+			}
+		}
+		path := filepath.Join(dir, export.FileName(format, time.Now()))
+		if err := export.WriteFile(path, format, doc); err != nil {
+			return ExportedMsg{Err: err}
+		}
+		return ExportedMsg{Path: path, Count: len(doc.Tasks)}
+	}
+}
+
+// updateExport handles input in the export menu
+func (m Model) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Back):
+		m.State = StateBrowse
+
+	case key.Matches(msg, m.Keys.Up):
+		if m.ExportCursor > 0 {
+			m.ExportCursor--
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if m.ExportCursor < len(export.Formats)-1 {
+			m.ExportCursor++
+		}
+
+	case key.Matches(msg, m.Keys.ToggleAll):
+		m.ExportAll = !m.ExportAll
+
+	case key.Matches(msg, m.Keys.Select):
+		m.State = StateBrowse
+		m.Loading = true
+		return m, m.exportTasks(export.Formats[m.ExportCursor], m.ExportAll)
+	}
+	return m, nil
+}
+
+// viewExport renders the export menu
+func (m Model) viewExport(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// EXPORT"))
+
+	var s strings.Builder
+	s.WriteString("\n")
+	for i, f := range export.Formats {
+		row := fmt.Sprintf("  %s", f.Label())
+		if m.ExportCursor == i {
+			s.WriteString(styles.ListSelectedStyle.Render(row))
+		} else {
+			s.WriteString(styles.ListItemStyle.Render(row))
+		}
+		s.WriteString("\n")
+	}
+
+	scope := fmt.Sprintf("Current view (%s, %d tasks)", m.ViewModeString(), len(m.Tasks))
+	if m.ExportAll {
+		scope = "All tasks"
+	}
+	s.WriteString("\n")
+	s.WriteString(styles.InputLabelStyle.Render("  Scope: "))
+	s.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Render(scope))
+
+	containerHeight := m.Height - 7
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Render(s.String())
+
+	help := keymap.Hints(keymap.Hint(m.Keys.Select, "Export"), keymap.Hint(m.Keys.ToggleAll, "Toggle all tasks"),
+		keymap.Hint(m.Keys.Back, "Cancel"))
+	status := lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).
+		Render(styles.HelpStyle.Render(help))
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
@@ -0,0 +1,397 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// GroupBy picks how the task list is split into sections
+type GroupBy int
+
+const (
+	GroupNone GroupBy = iota
+	GroupCategory
+	GroupPriority
+	GroupDue
+	GroupOwner
+)
+
+// groupByNames are the names of the groupings in config.json, in the
+// order the key cycles through them
+var groupByNames = []string{"none", "category", "priority", "due", "owner"}
+
+// parseGroupBy returns the grouping with the given config name
+func parseGroupBy(name string) (GroupBy, bool) {
+	for i, n := range groupByNames {
+		if n == name {
+			return GroupBy(i), true
+		}
+	}
+	return GroupNone, name == ""
+}
+
+// groupByString returns a string representation of the grouping
+func (m Model) groupByString() string {
+	switch m.GroupBy {
+	case GroupCategory:
+		return "Category"
+	case GroupPriority:
+		return "Priority"
+	case GroupDue:
+		return "Due"
+	case GroupOwner:
+		return "Owner"
+	}
+	return "None"
+}
+
+// cycleGroupBy moves to the next grouping, expanding every section
+func (m *Model) cycleGroupBy() {
+	m.GroupBy = (m.GroupBy + 1) % GroupBy(len(groupByNames))
+	m.collapsed = nil
+	m.ApplySort()
+}
+
+// taskGroup is the section a task falls in: rank orders the sections and
+// label names them, and orders sections of the same rank
+type taskGroup struct {
+	rank  int
+	label string
+}
+
+func (g taskGroup) less(o taskGroup) bool {
+	if g.rank != o.rank {
+		return g.rank < o.rank
+	}
+	return g.label < o.label
+}
+
+// groupOf returns the section a task falls in under the current grouping
+func (m Model) groupOf(task Task, now time.Time) taskGroup {
+	switch m.GroupBy {
+	case GroupCategory:
+		if task.Category != nil {
+			return taskGroup{0, task.Category.Name}
+		}
+		return taskGroup{1, "No category"}
+	case GroupPriority:
+		switch priorityLevel(task.Priority) {
+		case "high":
+			return taskGroup{0, "High priority"}
+		case "med":
+			return taskGroup{1, "Medium priority"}
+		}
+		return taskGroup{2, "Low priority"}
+	case GroupDue:
+		return dueBucket(task, now)
+	case GroupOwner:
+		if task.IsOwner == nil || *task.IsOwner {
+			return taskGroup{0, "Mine"}
+		}
+		if task.OwnerEmail != nil && *task.OwnerEmail != "" {
+			return taskGroup{1, *task.OwnerEmail}
+		}
+		return taskGroup{2, "Unknown owner"}
+	}
+	return taskGroup{}
+}
+
+// dueBucket sorts a task by when it is due: Overdue, Today, This week
+// (the next seven days), Later or No date. Done tasks due before today
+// are Earlier rather than Overdue.
+func dueBucket(task Task, now time.Time) taskGroup {
+	if task.DueAt == nil {
+		return taskGroup{5, "No date"}
+	}
+	due := task.DueAt.In(now.Location())
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case due.Before(day):
+		if task.Status == "done" {
+			return taskGroup{0, "Earlier"}
+		}
+		return taskGroup{1, "Overdue"}
+	case due.Before(day.AddDate(0, 0, 1)):
+		if due.Before(now) && task.Status != "done" {
+			return taskGroup{1, "Overdue"}
+		}
+		return taskGroup{2, "Today"}
+	case due.Before(day.AddDate(0, 0, 7)):
+		return taskGroup{3, "This week"}
+	}
+	return taskGroup{4, "Later"}
+}
+
+// groupTasks orders m.Tasks section by section, keeping the sort order
+// within each
+func (m *Model) groupTasks() {
+	if m.GroupBy == GroupNone {
+		return
+	}
+	now := time.Now()
+	sort.SliceStable(m.Tasks, func(i, j int) bool {
+		return m.groupOf(m.Tasks[i], now).less(m.groupOf(m.Tasks[j], now))
+	})
+}
+
+// listRow is one line of the task list: a section header or a task. A
+// header's index is its first task's, which the cursor rests on while the
+// section is collapsed.
+type listRow struct {
+	header    bool
+	group     taskGroup
+	count     int
+	collapsed bool
+	index     int
+	// num is the task's number as shown in the list
+	num int
+	// cont marks a header repeated at the top of a page
+	cont bool
+}
+
+// stop reports whether the cursor can rest on the row
+func (r listRow) stop() bool {
+	return !r.header || r.collapsed
+}
+
+// listRows returns the rows of the task list in display order. Without a
+// grouping there is one row per task.
+func (m Model) listRows() []listRow {
+	if m.GroupBy == GroupNone {
+		rows := make([]listRow, len(m.Tasks))
+		for i := range m.Tasks {
+			rows[i] = listRow{index: i, num: i + 1}
+		}
+		return rows
+	}
+
+	// Tasks are grouped by sortTasks, but one added since may be out of
+	// place, so collect the sections rather than trusting the order
+	now := time.Now()
+	var groups []taskGroup
+	members := make(map[taskGroup][]int)
+	for i, task := range m.Tasks {
+		g := m.groupOf(task, now)
+		if _, ok := members[g]; !ok {
+			groups = append(groups, g)
+		}
+		members[g] = append(members[g], i)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].less(groups[j]) })
+
+	rows := make([]listRow, 0, len(m.Tasks)+len(groups))
+	num := 0
+	for _, g := range groups {
+		tasks := members[g]
+		collapsed := m.collapsed[g]
+		rows = append(rows, listRow{header: true, group: g, count: len(tasks), collapsed: collapsed, index: tasks[0]})
+		for _, i := range tasks {
+			num++
+			if !collapsed {
+				rows = append(rows, listRow{group: g, index: i, num: num})
+			}
+		}
+	}
+	return rows
+}
+
+// cursorRow returns the position in rows of the row the cursor is on: its
+// task, or the header of the collapsed section the task is in
+func (m Model) cursorRow(rows []listRow) int {
+	for i, r := range rows {
+		if r.index == m.Cursor && !r.header {
+			return i
+		}
+	}
+	if task := m.CurrentTask(); task != nil {
+		g := m.groupOf(*task, time.Now())
+		for i, r := range rows {
+			if r.header && r.group == g {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// onCollapsedHeader reports whether the cursor is on a collapsed section's
+// header rather than on a task
+func (m Model) onCollapsedHeader() bool {
+	if m.GroupBy == GroupNone || m.CurrentTask() == nil {
+		return false
+	}
+	rows := m.listRows()
+	return rows[m.cursorRow(rows)].header
+}
+
+// moveCursor moves the cursor to the delta'th row it can rest on from the
+// current one, stopping at either end of the list
+func (m *Model) moveCursor(delta int) {
+	rows := m.listRows()
+	if len(rows) == 0 {
+		return
+	}
+	i := m.cursorRow(rows)
+	for delta != 0 {
+		step := 1
+		if delta < 0 {
+			step = -1
+		}
+		next := i + step
+		for next >= 0 && next < len(rows) && !rows[next].stop() {
+			next += step
+		}
+		if next < 0 || next >= len(rows) {
+			break
+		}
+		i = next
+		delta -= step
+	}
+	m.Cursor = rows[i].index
+	m.EnsureCursorVisible()
+}
+
+// cursorToEnd moves the cursor to the first row it can rest on, or the
+// last
+func (m *Model) cursorToEnd(last bool) {
+	if last {
+		m.moveCursor(len(m.Tasks) + 1)
+	} else {
+		m.moveCursor(-len(m.Tasks) - 1)
+	}
+}
+
+// toggleSection collapses the section the cursor is in, or expands it
+func (m *Model) toggleSection() {
+	task := m.CurrentTask()
+	if m.GroupBy == GroupNone || task == nil {
+		return
+	}
+	g := m.groupOf(*task, time.Now())
+	if m.collapsed == nil {
+		m.collapsed = make(map[taskGroup]bool)
+	}
+	if m.collapsed[g] {
+		delete(m.collapsed, g)
+	} else {
+		m.collapsed[g] = true
+	}
+	m.EnsureCursorVisible()
+}
+
+// rowLines is how many lines a row takes in the list: one, plus the notes
+// and subtasks of an expanded task or the notes being edited
+func (m Model) rowLines(r listRow) int {
+	if r.header {
+		return 1
+	}
+	task := m.Tasks[r.index]
+	lines := 1
+	if r.index == m.Cursor {
+		switch m.State {
+		case StateEditing:
+			return lines
+		case StateEditingNotes:
+			lines += lipgloss.Height(m.NotesInput.View())
+		}
+	}
+	if task.Expanded {
+		if task.Notes != nil && *task.Notes != "" {
+			lines += lipgloss.Height(m.expandedNotes(task, m.listTextWidth()-3, m.CurrentTheme()))
+		}
+		lines += len(task.Subtasks)
+	}
+	return lines
+}
+
+// pageBounds splits rows into pages of about PageSize lines, as start and
+// end (exclusive) rows. A page that starts part way through a section has
+// a line for its repeated header, and a section's header isn't left at the
+// foot of a page without its tasks. A row taller than a page gets a page
+// to itself.
+func (m Model) pageBounds(rows []listRow) [][2]int {
+	// contLines is the repeated header above a page starting at row i
+	contLines := func(i int) int {
+		if m.GroupBy != GroupNone && !rows[i].header {
+			return 1
+		}
+		return 0
+	}
+
+	var pages [][2]int
+	start, used := 0, 0
+	for i := 0; i < len(rows); i++ {
+		lines := m.rowLines(rows[i])
+		if i > start && used+lines > m.PageSize {
+			brk := i
+			if prev := rows[i-1]; prev.header && !prev.collapsed && i-1 > start {
+				brk = i - 1
+			}
+			pages = append(pages, [2]int{start, brk})
+			start, used = brk, contLines(brk)
+			for j := brk; j < i; j++ {
+				used += m.rowLines(rows[j])
+			}
+		}
+		used += lines
+	}
+	return append(pages, [2]int{start, len(rows)})
+}
+
+// pageRows returns the rows on the current page. A page that starts part
+// way through a section repeats its header.
+func (m Model) pageRows() []listRow {
+	rows := m.listRows()
+	start, end := m.PageStart(), m.PageEnd()
+	if start >= len(rows) {
+		return nil
+	}
+	page := rows[start:end]
+	if m.GroupBy != GroupNone && !page[0].header {
+		for i := start - 1; i >= 0; i-- {
+			if rows[i].header {
+				cont := rows[i]
+				cont.cont = true
+				page = append([]listRow{cont}, page...)
+				break
+			}
+		}
+	}
+	return page
+}
+
+// renderHeader renders a section header with its task count
+func (m Model) renderHeader(r listRow, selected bool, t themes.Theme) string {
+	arrow := "▾"
+	if r.collapsed {
+		arrow = "▸"
+	}
+	text := lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render(arrow+" "+r.group.label) +
+		lipgloss.NewStyle().Foreground(t.Dim).Render(fmt.Sprintf(" (%d)", r.count))
+	if r.cont {
+		text += lipgloss.NewStyle().Foreground(t.Dim).Render(" continued")
+	}
+	if selected {
+		return styles.ListSelectedStyle.Render(text)
+	}
+	return styles.ListItemStyle.Render(text)
+}
+
+// groupPlain describes the section the cursor is in for plain mode
+func (m Model) groupPlain() string {
+	task := m.CurrentTask()
+	if m.GroupBy == GroupNone || task == nil {
+		return ""
+	}
+	rows := m.listRows()
+	r := rows[m.cursorRow(rows)]
+	if r.header {
+		return fmt.Sprintf("Section %s, %d tasks, collapsed.", r.group.label, r.count)
+	}
+	return fmt.Sprintf("In %s.", r.group.label)
+}
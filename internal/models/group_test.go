@@ -0,0 +1,143 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+)
+
+func TestGroupedList(t *testing.T) {
+	h := newHarness(t)
+	h.press("m")
+	if got := strings.Join(h.titles(), ", "); got != "Fix tap, Write report, Answer email, Buy milk" {
+		t.Errorf("grouped by category = %s", got)
+	}
+	view := h.m.View()
+	for _, want := range []string{"▾ Home (1)", "▾ Work (1)", "▾ No category (2)", "Category (m)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("no %q in:\n%s", want, view)
+		}
+	}
+
+	// Collapsing a section leaves its header for the cursor to rest on
+	h.selectTitle("Write report")
+	h.press("z")
+	view = h.m.View()
+	if !strings.Contains(view, "▸ Work (1)") || strings.Contains(view, "Write report") {
+		t.Errorf("Work not collapsed:\n%s", view)
+	}
+	if !h.m.onCollapsedHeader() || h.m.actionableTask() != nil {
+		t.Error("cursor should be on the Work header, with no task to act on")
+	}
+	h.press("down")
+	if got := h.m.CurrentTask().Title; got != "Answer email" {
+		t.Errorf("down from header = %s", got)
+	}
+	h.press("up", "up")
+	if got := h.m.CurrentTask().Title; got != "Fix tap" {
+		t.Errorf("up past header = %s", got)
+	}
+	h.press("down", "enter")
+	if h.m.State != StateBrowse || !strings.Contains(h.m.View(), "Write report") {
+		t.Errorf("enter on header should expand it, state %v", h.m.State)
+	}
+
+	// Pages count headers, and a section split across pages repeats its own
+	h.m.PageSize = 2
+	h.press("end")
+	if h.m.Page != 3 || h.m.CurrentTask().Title != "Buy milk" {
+		t.Errorf("end: page %d, task %s", h.m.Page, h.m.CurrentTask().Title)
+	}
+	view = h.m.View()
+	if !strings.Contains(view, "▾ No category (2) continued") || !strings.Contains(view, "4.") {
+		t.Errorf("last page:\n%s", view)
+	}
+	h.press("left")
+	if h.m.Page != 2 || h.m.CurrentTask().Title != "Answer email" {
+		t.Errorf("prev page: page %d, task %s", h.m.Page, h.m.CurrentTask().Title)
+	}
+
+	h.press("m")
+	if got := strings.Join(h.titles(), ", "); got != "Write report, Answer email, Fix tap, Buy milk" {
+		t.Errorf("grouped by priority = %s", got)
+	}
+}
+
+func TestDueBucket(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration, status string) Task {
+		due := now.Add(d)
+		return Task{Task: api.Task{DueAt: &due, Status: status}}
+	}
+	tests := []struct {
+		task Task
+		want string
+	}{
+		{Task{}, "No date"},
+		{at(-26*time.Hour, "open"), "Overdue"},
+		{at(-26*time.Hour, "done"), "Earlier"},
+		{at(-time.Hour, "open"), "Overdue"},
+		{at(-time.Hour, "done"), "Today"},
+		{at(11*time.Hour, "open"), "Today"},
+		{at(13*time.Hour, "open"), "This week"},
+		{at(6*24*time.Hour, "open"), "This week"},
+		{at(7*24*time.Hour, "open"), "Later"},
+	}
+	for _, tt := range tests {
+		if got := dueBucket(tt.task, now).label; got != tt.want {
+			t.Errorf("due %v (%s) = %s, want %s", tt.task.DueAt, tt.task.Status, got, tt.want)
+		}
+	}
+}
+
+func TestGroupedPagesCountLines(t *testing.T) {
+	h := newHarness(t)
+	h.press("m", "m")
+	h.m.PageSize = 4
+	h.selectTitle("Fix tap")
+	h.press("v")
+
+	// High: Write report; Medium: Answer email, Fix tap and its notes; the
+	// Medium section is split, and the Low header moves with its task
+	if got := h.m.TotalPages(); got != 3 {
+		t.Errorf("pages = %d, want 3", got)
+	}
+	h.selectTitle("Answer email")
+	h.press("down")
+	if h.m.Page != 1 || h.m.CurrentTask().Title != "Fix tap" {
+		t.Fatalf("down: page %d, task %s", h.m.Page, h.m.CurrentTask().Title)
+	}
+	view := h.m.View()
+	if !strings.Contains(view, "▾ Medium priority (2) continued") || !strings.Contains(view, "Call the plumber first") {
+		t.Errorf("page 2 lacks the repeated header or the notes:\n%s", view)
+	}
+	if strings.Contains(view, "Low priority") || strings.Contains(view, "Answer email") {
+		t.Errorf("page 2 shows rows from other pages:\n%s", view)
+	}
+	h.press("down")
+	if h.m.Page != 2 || h.m.CurrentTask().Title != "Buy milk" {
+		t.Errorf("down: page %d, task %s", h.m.Page, h.m.CurrentTask().Title)
+	}
+	h.press("up", "up")
+	if h.m.Page != 0 || h.m.CurrentTask().Title != "Answer email" {
+		t.Errorf("up: page %d, task %s", h.m.Page, h.m.CurrentTask().Title)
+	}
+}
+
+func TestSectionsWithTheSameLabel(t *testing.T) {
+	h := newHarness(t)
+	named := h.srv.AddCategory(fakeserver.DemoEmail, "No category", "#FF6B6B")
+	h.srv.UpdateTask(h.serverTask("Fix tap").ID, func(t *api.Task) { t.CategoryID = &named.ID })
+	h.press("r", "m")
+
+	// A category named like the section of tasks without one folds apart
+	h.selectTitle("Buy milk")
+	h.press("z")
+	view := h.m.View()
+	if !strings.Contains(view, "▸ No category (2)") || !strings.Contains(view, "▾ No category (1)") || !strings.Contains(view, "Fix tap") {
+		t.Errorf("both sections collapsed:\n%s", view)
+	}
+}
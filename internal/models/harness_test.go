@@ -0,0 +1,274 @@
+package models
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// cmdTimeout bounds how long the harness waits for the next command result.
+// It is longer than every animation but shorter than the textinput cursor
+// blink, so blink commands are dropped instead of stalling each keystroke.
+const cmdTimeout = 400 * time.Millisecond
+
+func TestMain(m *testing.M) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	hasDarkBackground = func() bool { return true }
+	styles.Init()
+	os.Exit(m.Run())
+}
+
+// harness drives a Model against a fake server. Commands run concurrently,
+// as they do under Bubble Tea, and their messages are fed back into Update
+// until the model settles.
+type harness struct {
+	t       *testing.T
+	srv     *fakeserver.Server
+	client  *api.Client
+	m       Model
+	quit    bool
+	results chan tea.Msg
+	pending int
+}
+
+// newHarness starts a fake server holding the fixture data, logs in and
+// runs the model's Init commands
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	seedFixtures(srv)
+
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	cfg.RefreshInterval = 0
+	cfg.LiveUpdates = false
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	h := &harness{
+		t:       t,
+		srv:     srv,
+		client:  client,
+		m:       NewModel(client, cfg),
+		results: make(chan tea.Msg, 256),
+	}
+	h.send(tea.WindowSizeMsg{Width: 100, Height: 30})
+	h.run(h.m.Init())
+	return h
+}
+
+// seedFixtures stores a small deterministic data set. Due dates are left
+// out so rendered output does not depend on the current time.
+func seedFixtures(srv *fakeserver.Server) {
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	work := srv.AddCategory(fakeserver.DemoEmail, "Work", "#45B7D1")
+	home := srv.AddCategory(fakeserver.DemoEmail, "Home", "#96CEB4")
+	notes := "Call the plumber first"
+
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Write report", Priority: 9, CategoryID: &work.ID,
+		Subtasks: []api.Subtask{{Title: "Outline"}, {Title: "Draft", Status: "done"}}})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Fix tap", Priority: 5, CategoryID: &home.ID, Notes: &notes})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Buy milk", Priority: 2})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Answer email", Priority: 7})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Old chore", Status: "done"})
+}
+
+// send delivers a message to the model and waits for it to settle
+func (h *harness) send(msg tea.Msg) {
+	h.t.Helper()
+	h.deliver(msg)
+	h.settle()
+}
+
+// run executes a command and waits for the model to settle
+func (h *harness) run(cmd tea.Cmd) {
+	h.t.Helper()
+	h.start(cmd)
+	h.settle()
+}
+
+// deliver passes a message to Update and starts the returned command
+func (h *harness) deliver(msg tea.Msg) {
+	next, cmd := h.m.Update(msg)
+	h.m = next.(Model)
+	h.start(cmd)
+}
+
+func (h *harness) start(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	h.pending++
+	go func() { h.results <- cmd() }()
+}
+
+// settle processes command results until none are outstanding. Commands
+// still running after cmdTimeout without any other activity are abandoned.
+func (h *harness) settle() {
+	for h.pending > 0 {
+		select {
+		case msg := <-h.results:
+			h.pending--
+			switch msg := msg.(type) {
+			case nil:
+			case tea.BatchMsg:
+				for _, c := range msg {
+					h.start(c)
+				}
+			case tea.QuitMsg:
+				h.quit = true
+			default:
+				h.deliver(msg)
+			}
+		case <-time.After(cmdTimeout):
+			h.pending = 0
+		}
+	}
+}
+
+// press sends a sequence of keys such as "j", "enter" or "ctrl+c"
+func (h *harness) press(keys ...string) {
+	h.t.Helper()
+	for _, k := range keys {
+		h.send(keyMsg(k))
+	}
+}
+
+// queue delivers keys without waiting for their commands, so later keys
+// arrive while earlier requests and animations are still in flight. Call
+// settle to let everything finish.
+func (h *harness) queue(keys ...string) {
+	h.t.Helper()
+	for _, k := range keys {
+		h.deliver(keyMsg(k))
+	}
+}
+
+// typeText sends text as a single paste-style key event
+func (h *harness) typeText(s string) {
+	h.t.Helper()
+	h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+}
+
+// titles returns the task titles in display order
+func (h *harness) titles() []string {
+	var out []string
+	for _, t := range h.m.Tasks {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+// serverTask returns the server's copy of the task with the given title
+func (h *harness) serverTask(title string) *api.Task {
+	for _, t := range h.srv.Tasks() {
+		if t.Title == title {
+			return &t
+		}
+	}
+	return nil
+}
+
+// selectTitle moves the cursor onto the task with the given title
+func (h *harness) selectTitle(title string) {
+	h.t.Helper()
+	for i, t := range h.m.Tasks {
+		if t.Title == title {
+			h.m.Cursor = i
+			h.m.EnsureCursorVisible()
+			return
+		}
+	}
+	h.t.Fatalf("task %q not in list %v", title, h.titles())
+}
+
+var namedKeys = map[string]tea.KeyType{
+	"enter":  tea.KeyEnter,
+	"esc":    tea.KeyEsc,
+	"tab":    tea.KeyTab,
+	"space":  tea.KeySpace,
+	"up":     tea.KeyUp,
+	"down":   tea.KeyDown,
+	"left":   tea.KeyLeft,
+	"right":  tea.KeyRight,
+	"pgup":   tea.KeyPgUp,
+	"pgdown": tea.KeyPgDown,
+	"ctrl+c": tea.KeyCtrlC,
+	"ctrl+r": tea.KeyCtrlR,
+	"ctrl+b": tea.KeyCtrlB,
+	"ctrl+j": tea.KeyCtrlJ,
+	"ctrl+e": tea.KeyCtrlE,
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	if t, ok := namedKeys[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// assertGolden compares got with testdata/<name>.golden, rewriting the file
+// when -update is set
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got = normalizeView(got)
+
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("view does not match %s (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// normalizeView strips trailing spaces so golden files stay readable
+func normalizeView(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// themeIndex returns the index of the named theme
+func themeIndex(t *testing.T, name string) int {
+	t.Helper()
+	for i, th := range themes.All {
+		if th.Name == name {
+			return i
+		}
+	}
+	t.Fatalf("unknown theme %q", name)
+	return 0
+}
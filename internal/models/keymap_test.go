@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/keymap"
+)
+
+func TestRemappedKeys(t *testing.T) {
+	h := newHarness(t)
+	keys, err := keymap.New("vim", map[string][]string{"delete": {"D"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.m.Keys = keys
+
+	h.press("G")
+	if h.m.Cursor != len(h.m.Tasks)-1 {
+		t.Errorf("cursor = %d after G, want the last task", h.m.Cursor)
+	}
+	h.press("d")
+	if h.m.State != StateBrowse {
+		t.Errorf("d still deletes, state = %v", h.m.State)
+	}
+	h.press("D")
+	if h.m.State != StateConfirmDelete {
+		t.Errorf("D does not delete, state = %v", h.m.State)
+	}
+	if !strings.Contains(h.m.viewMain(h.m.CurrentTheme()), "Del (D)") {
+		t.Error("status bar does not show the new key")
+	}
+}
+
+func TestBadKeymapFallsBack(t *testing.T) {
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.Keymap.Bindings = map[string][]string{"delete": {"x"}}
+	m := NewModel(api.NewClient(cfg), cfg)
+	if !strings.Contains(m.ErrorMsg, "x is bound to both delete and export") {
+		t.Errorf("ErrorMsg = %q", m.ErrorMsg)
+	}
+	if keymap.Key(m.Keys.Delete) != "d" {
+		t.Error("defaults not used")
+	}
+}
+
+func TestLoadProblemsAreAllShown(t *testing.T) {
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.Keymap.Bindings = map[string][]string{"delete": {"x"}}
+	cfg.Theme = "nosuch"
+	cfg.Animation = "fireworks"
+	cfg.GroupBy = "colour"
+	m := NewModel(api.NewClient(cfg), cfg)
+	for _, want := range []string{`Unknown theme "nosuch"`, `Unknown animation "fireworks"`, `Unknown group_by "colour"`, "bound to both"} {
+		if !strings.Contains(m.ErrorMsg, want) {
+			t.Errorf("no %q in %q", want, m.ErrorMsg)
+		}
+	}
+	if m.GroupBy != GroupNone {
+		t.Errorf("group by = %v", m.GroupBy)
+	}
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// While the server's event stream is connected, changes are applied as they
+// arrive and background polling is paused. If the stream drops, polling
+// resumes until it reconnects; a server without a stream is simply polled.
+
+// StreamStartedMsg carries a new event subscription. Seq is the session it
+// was opened for, matching refreshSeq.
+type StreamStartedMsg struct {
+	Sub *api.Subscription
+	Seq int
+}
+
+// StreamUpdateMsg is an event or status change from the event stream
+type StreamUpdateMsg struct {
+	Update api.StreamUpdate
+	Seq    int
+}
+
+// startEvents opens the event stream, or returns nil if live updates are off
+func (m Model) startEvents() tea.Cmd {
+	if m.Config == nil || !m.Config.LiveUpdates {
+		return nil
+	}
+	client := m.Client
+	seq := m.refreshSeq
+	return func() tea.Msg {
+		return StreamStartedMsg{Sub: client.Subscribe(api.DefaultStreamOptions), Seq: seq}
+	}
+}
+
+// listenEvents waits for the next update on the subscription
+func listenEvents(sub *api.Subscription, seq int) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-sub.C
+		if !ok {
+			return nil
+		}
+		return StreamUpdateMsg{Update: u, Seq: seq}
+	}
+}
+
+// stopEvents closes the event stream, if one is open
+func (m *Model) stopEvents() {
+	if m.events != nil {
+		m.events.Close()
+		m.events = nil
+	}
+	m.Live = false
+}
+
+// Close releases resources held by the model
+func (m Model) Close() {
+	m.stopEvents()
+}
+
+// handleStreamUpdate applies one update from the event stream
+func (m *Model) handleStreamUpdate(u api.StreamUpdate) tea.Cmd {
+	var cmds []tea.Cmd
+	if u.Event != nil {
+		cmds = append(cmds, m.applyEvent(*u.Event))
+	} else {
+		wasLive := m.Live
+		m.Live = u.Status == api.StreamConnected
+		switch {
+		case m.Live && m.streamDropped:
+			// Catch up on anything missed while disconnected
+			m.streamDropped = false
+			cmds = append(cmds, m.fetchTasks(true), m.loadCategories())
+		case wasLive && !m.Live:
+			m.streamDropped = true
+		}
+	}
+	if m.events != nil {
+		cmds = append(cmds, listenEvents(m.events, m.refreshSeq))
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyEvent merges a single change into the loaded data
+func (m *Model) applyEvent(e api.Event) tea.Cmd {
+	switch e.Type {
+	case api.EventTaskCreated, api.EventTaskUpdated:
+		cmd := m.applyTaskEvent(e)
+		return tea.Batch(cmd, m.trackStatus([]api.Task{*e.Task}, false))
+
+	case api.EventTaskDeleted:
+		if _, deleting := m.removed[e.ID]; deleting || m.IsPending(e.ID) {
+			break
+		}
+		forget := m.forgetTask(e.ID)
+		if t := m.TaskByID(e.ID); t != nil {
+			title := t.Title
+			m.removeTask(e.ID)
+			return tea.Batch(m.setSuccess(removedNote([]string{title})), forget)
+		}
+		return forget
+
+	case api.EventCategoryCreated, api.EventCategoryUpdated:
+		m.putCategory(*e.Category)
+
+	case api.EventCategoryDeleted:
+		m.removeCategory(e.ID)
+	}
+	return nil
+}
+
+// applyTaskEvent adds, updates or drops a task after a change elsewhere
+func (m *Model) applyTaskEvent(e api.Event) tea.Cmd {
+	task := *e.Task
+	if _, deleting := m.removed[task.ID]; deleting || m.IsPending(task.ID) {
+		// Our own request is in flight; its response settles the task
+		return nil
+	}
+
+	existing := m.TaskByID(task.ID)
+	if !m.inView(task) {
+		if existing != nil {
+			m.removeTask(task.ID)
+		}
+		return nil
+	}
+
+	highlight := "updated"
+	if existing == nil {
+		if e.Type == api.EventTaskCreated && m.hasPlaceholder(task.Title) {
+			// Our own create; TaskCreatedMsg swaps the placeholder
+			return nil
+		}
+		highlight = "new"
+	} else if reflect.DeepEqual(existing.Task, task) {
+		return nil
+	}
+
+	m.putTask(task)
+	t := m.TaskByID(task.ID)
+	t.Highlight = highlight
+	t.HighlightUntil = time.Now().Add(HighlightDuration)
+	m.ApplySort()
+	return tea.Tick(HighlightDuration, func(time.Time) tea.Msg {
+		return HighlightDoneMsg{}
+	})
+}
+
+// inView reports whether a task belongs in the current view mode
+func (m Model) inView(t api.Task) bool {
+	switch m.ViewMode {
+	case ViewCompleted:
+		return t.Status == "done"
+	case ViewShared:
+		return len(t.SharedWith) > 0 || (t.IsOwner != nil && !*t.IsOwner)
+	case ViewToday:
+		return m.inToday(t)
+	default:
+		return t.Status == "open"
+	}
+}
+
+// hasPlaceholder reports whether a task with the given title is still
+// being created
+func (m Model) hasPlaceholder(title string) bool {
+	for _, t := range m.Tasks {
+		if t.ID < 0 && t.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// putCategory adds or replaces a category and refreshes the copies
+// embedded in loaded tasks
+func (m *Model) putCategory(cat api.Category) {
+	found := false
+	for i := range m.Categories {
+		if m.Categories[i].ID == cat.ID {
+			m.Categories[i] = cat
+			found = true
+		}
+	}
+	if !found {
+		m.Categories = append(m.Categories, cat)
+	}
+	for i := range m.Tasks {
+		if t := &m.Tasks[i]; t.CategoryID != nil && *t.CategoryID == cat.ID {
+			c := cat
+			t.Category = &c
+		}
+	}
+}
+
+// removeCategory drops a category and clears it from loaded tasks
+func (m *Model) removeCategory(id int) {
+	for i := range m.Categories {
+		if m.Categories[i].ID == id {
+			m.Categories = append(m.Categories[:i], m.Categories[i+1:]...)
+			break
+		}
+	}
+	for i := range m.Tasks {
+		if t := &m.Tasks[i]; t.CategoryID != nil && *t.CategoryID == id {
+			t.CategoryID = nil
+			t.Category = nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+)
+
+// event delivers a stream event as the subscription would
+func (h *harness) event(e api.Event) {
+	h.t.Helper()
+	h.send(StreamUpdateMsg{Update: api.StreamUpdate{Event: &e, Status: api.StreamConnected}, Seq: h.m.refreshSeq})
+}
+
+// streamStatus delivers a stream status change
+func (h *harness) streamStatus(status api.StreamStatus) {
+	h.t.Helper()
+	h.send(StreamUpdateMsg{Update: api.StreamUpdate{Status: status}, Seq: h.m.refreshSeq})
+}
+
+func TestEventAddsAndUpdatesTasks(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+
+	added := h.srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Teammate task", Priority: 4})
+	h.event(api.Event{Type: api.EventTaskCreated, ID: added.ID, Task: &added})
+
+	if task := h.m.TaskByID(added.ID); task == nil || task.Highlight != "new" {
+		t.Errorf("added task = %+v, want highlighted as new", task)
+	}
+
+	added.Priority = 9
+	h.event(api.Event{Type: api.EventTaskUpdated, ID: added.ID, Task: &added})
+	if task := h.m.TaskByID(added.ID); task == nil || task.Priority != 9 {
+		t.Errorf("updated task = %+v, want priority 9", task)
+	}
+	if current := h.m.CurrentTask(); current == nil || current.Title != "Fix tap" {
+		t.Errorf("cursor moved to %+v", current)
+	}
+}
+
+func TestEventDropsTasksLeavingView(t *testing.T) {
+	h := newHarness(t)
+	milk := *h.serverTask("Buy milk")
+	milk.Status = "done"
+
+	h.event(api.Event{Type: api.EventTaskUpdated, ID: milk.ID, Task: &milk})
+
+	if h.m.TaskByID(milk.ID) != nil {
+		t.Errorf("completed task still in open view: %v", h.titles())
+	}
+}
+
+func TestEventDeletesTask(t *testing.T) {
+	h := newHarness(t)
+	milk := h.serverTask("Buy milk")
+
+	h.event(api.Event{Type: api.EventTaskDeleted, ID: milk.ID})
+
+	if h.m.TaskByID(milk.ID) != nil {
+		t.Errorf("deleted task still listed: %v", h.titles())
+	}
+	if !strings.Contains(h.m.SuccessMsg, "Buy milk") {
+		t.Errorf("status = %q, want a note about Buy milk", h.m.SuccessMsg)
+	}
+}
+
+func TestEventIgnoredWhileEditPending(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+	h.srv.SetLatency(100 * time.Millisecond)
+	h.queue("space")
+
+	stale := *h.serverTask("Buy milk")
+	stale.Status = "open"
+	stale.Priority = 1
+	h.deliver(StreamUpdateMsg{Update: api.StreamUpdate{Event: &api.Event{Type: api.EventTaskUpdated, ID: stale.ID, Task: &stale}}, Seq: h.m.refreshSeq})
+
+	if task := h.m.TaskByID(stale.ID); task == nil || task.Status != "done" || task.Priority == 1 {
+		t.Errorf("pending task = %+v, want local edit kept", task)
+	}
+	h.settle()
+}
+
+func TestEventUpdatesCategory(t *testing.T) {
+	h := newHarness(t)
+	work := h.m.Categories[0]
+	work.Name = "Office"
+
+	h.event(api.Event{Type: api.EventCategoryUpdated, ID: work.ID, Category: &work})
+
+	task := h.m.TaskByID(h.serverTask("Write report").ID)
+	if task.Category == nil || task.Category.Name != "Office" {
+		t.Errorf("task category = %+v, want Office", task.Category)
+	}
+
+	h.event(api.Event{Type: api.EventCategoryDeleted, ID: work.ID})
+	if task := h.m.TaskByID(task.ID); task.CategoryID != nil {
+		t.Errorf("task still in deleted category %d", *task.CategoryID)
+	}
+	if len(h.m.Categories) != 1 {
+		t.Errorf("categories = %+v, want one left", h.m.Categories)
+	}
+}
+
+func TestPollingPausedWhileLive(t *testing.T) {
+	h := newHarness(t)
+	h.streamStatus(api.StreamConnected)
+	if !h.m.Live {
+		t.Fatal("model not live after connect")
+	}
+
+	h.srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Missed while live"})
+	h.refresh()
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("refresh ran while live: %v", h.titles())
+	}
+
+	// A reconnect catches up on changes made while the stream was down
+	h.streamStatus(api.StreamDisconnected)
+	h.streamStatus(api.StreamConnecting)
+	h.streamStatus(api.StreamConnected)
+	if len(h.m.Tasks) != 5 {
+		t.Errorf("reconnect did not catch up: %v", h.titles())
+	}
+}
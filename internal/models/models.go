@@ -1,12 +1,27 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"github.com/blackraven/todo-tui/internal/anim"
 	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/deps"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/recur"
+	"github.com/blackraven/todo-tui/internal/schedule"
+	"github.com/blackraven/todo-tui/internal/stats"
+	"github.com/blackraven/todo-tui/internal/templates"
 	"github.com/blackraven/todo-tui/internal/themes"
+	"github.com/blackraven/todo-tui/internal/timelog"
+	"github.com/blackraven/todo-tui/internal/today"
 )
 
 // AppState represents the current state of the application
@@ -25,6 +40,15 @@ const (
 	StateCategoryCreate
 	StateHelp
 	StateConfirmDelete
+	StateExport
+	StateRecurrence
+	StateTemplates
+	StateTemplatePrompt
+	StateBreakdown
+	StateThemePicker
+	StateAnimations
+	StateStats
+	StatePlan
 )
 
 // ViewMode represents which list view is active
@@ -34,6 +58,7 @@ const (
 	ViewOpen ViewMode = iota
 	ViewCompleted
 	ViewShared
+	ViewToday
 )
 
 // SortMode represents the current sort order
@@ -54,46 +79,10 @@ const (
 	FieldPassword
 )
 
-// Animation type constants
+// Animation and highlight timing
 const (
-	AnimSparkle = iota
-	AnimMatrix
-	AnimWipeRight
-	AnimWipeLeft
-	AnimRainbow
-	AnimWave
-	AnimBinary
-	AnimDissolve
-	AnimFlip
-	AnimPulse
-	AnimTypewriter
-	AnimParticle
-	AnimRedact
-	AnimChaos
-	AnimConverge
-	AnimBounce
-	AnimSpin
-	AnimZipper
-	AnimEraser
-	AnimGlitch
-	AnimMoons
-	AnimBraille
-	AnimHex
-	AnimReverse
-	AnimCaseFlip
-	AnimWide
-	AnimTraffic
-	AnimCenterStrike
-	AnimLoading
-	AnimSlider
-
-	AnimCount = 30
-)
-
-// Animation duration constants
-const (
-	CheckAnimDuration  = 290 * time.Millisecond
 	DeleteAnimDuration = 200 * time.Millisecond
+	HighlightDuration  = 3 * time.Second
 	FPS                = 60
 )
 
@@ -104,10 +93,14 @@ type Task struct {
 	// UI States
 	Expanded bool
 
+	// Change highlighting after a background refresh
+	Highlight      string // "new" or "updated"
+	HighlightUntil time.Time
+
 	// Animation States
 	IsAnimatingCheck bool
 	IsDeleting       bool
-	AnimType         int
+	Anim             anim.Animation
 	AnimStart        time.Time
 }
 
@@ -117,35 +110,61 @@ type Category = api.Category
 // TickMsg is sent on animation tick
 type TickMsg struct{}
 
-// TasksLoadedMsg is sent when tasks are loaded from API
+// TasksLoadedMsg is sent when tasks are loaded from API. View is the view
+// mode the load was for; Background marks a load started by auto-refresh.
 type TasksLoadedMsg struct {
-	Tasks []api.Task
-	Err   error
+	Tasks      []api.Task
+	Err        error
+	View       ViewMode
+	Background bool
+}
+
+// RefreshTickMsg triggers a background refresh. Seq identifies the polling
+// loop so a loop left over from an earlier session stops.
+type RefreshTickMsg struct {
+	Seq int
 }
 
+// HighlightDoneMsg is sent when change highlights should fade
+type HighlightDoneMsg struct{}
+
 // CategoriesLoadedMsg is sent when categories are loaded from API
 type CategoriesLoadedMsg struct {
 	Categories []api.Category
 	Err        error
 }
 
-// TaskCreatedMsg is sent when a task is created
+// TaskCreatedMsg is sent when a task is created. TempID is the placeholder
+// that stood in for the task while the request was in flight.
 type TaskCreatedMsg struct {
-	Task *api.Task
-	Err  error
+	TempID int
+	Task   *api.Task
+	Err    error
+	// Breakdown is set if AI subtasks were requested and need review
+	Breakdown bool
 }
 
 // TaskUpdatedMsg is sent when a task is updated
 type TaskUpdatedMsg struct {
+	ID   int
 	Task *api.Task
 	Err  error
 }
 
 // TaskDeletedMsg is sent when a task is deleted
 type TaskDeletedMsg struct {
+	ID  int
 	Err error
 }
 
+// AnimFinishedMsg is sent when a task's check or delete animation has run
+// its course. Start identifies the animation so a stale message for an
+// earlier run is ignored.
+type AnimFinishedMsg struct {
+	ID    int
+	Start time.Time
+}
+
 // LoginMsg is sent after login attempt
 type LoginMsg struct {
 	Err error
@@ -158,16 +177,23 @@ type RegisterMsg struct {
 
 // Model is the main application model
 type Model struct {
-	// API client
+	// API client and configuration
 	Client *api.Client
+	Config *config.Config
 
 	// Application state
 	State         AppState
 	PreviousState AppState
 	ViewMode      ViewMode
 	SortMode      SortMode
+	GroupBy       GroupBy
 	ThemeIndex    int
-	LastAnim      int
+
+	// Themes are the built-in themes followed by the user's, and
+	// ColorProfile what the terminal can show
+	Themes       []themes.Theme
+	ColorProfile termenv.Profile
+	themeOrig    int
 
 	// Data
 	Tasks      []Task
@@ -177,6 +203,8 @@ type Model struct {
 	// UI state
 	Cursor         int
 	CategoryCursor int
+	ExportCursor   int
+	ExportAll      bool
 	Width          int
 	Height         int
 
@@ -184,19 +212,25 @@ type Model struct {
 	Page     int
 	PageSize int
 
+	// collapsed holds the list sections folded away
+	collapsed map[taskGroup]bool
+
 	// Input fields
 	EmailInput    textinput.Model
 	PasswordInput textinput.Model
 	TitleInput    textinput.Model
-	NotesInput    textinput.Model
+	NotesInput    textarea.Model
 	CategoryInput textinput.Model
+	RecurInput    textinput.Model
+	TemplateInput textinput.Model
+	ReviewInput   textinput.Model
 	FocusedField  InputField
 
 	// Temporary storage
-	TempTitle       string
-	TempNotes       string
-	EditingTaskID   int
-	SelectedTaskIdx int
+	TempTitle      string
+	TempNotes      string
+	EditingTaskID  int
+	SelectedTaskID int
 
 	// Status messages
 	ErrorMsg   string
@@ -204,10 +238,92 @@ type Model struct {
 
 	// Loading state
 	Loading bool
+	Spinner spinner.Model
+
+	// CreateBreakdown asks the AI for subtasks when the new task is created
+	CreateBreakdown bool
+
+	// Templates are the task templates shown in the picker
+	Templates      []templates.Template
+	TemplateCursor int
+
+	// Values entered so far for the chosen template, and the prompts left
+	templateVars    map[string]string
+	templatePrompts []string
+
+	// AI breakdown under review, and the last accepted one for undo
+	breakdown    breakdownState
+	breakdownSeq int
+	undo         lastBreakdown
+
+	// Recurrence holds the repeat rules of the user's tasks
+	Recurrence *recur.Store
+
+	// Pins holds the tasks pinned to the Today list
+	Pins *today.Pins
+
+	// Timer holds the running timer and the time logged on tasks;
+	// timerSeq and timerPhase track its clock
+	Timer      *timelog.Store
+	timerSeq   int
+	timerPhase timelog.Phase
+
+	// Deps holds which tasks wait on which. known has the title and
+	// status each task was last seen with, to tell whether a blocker is
+	// done; linkFrom is the task whose blocker is being picked.
+	Deps     *deps.Store
+	known    map[int]knownTask
+	linkFrom int
+
+	// plan is the schedule being reviewed on the planner
+	plan       *schedule.Plan
+	planCursor int
+
+	// Keys are the active key bindings
+	Keys keymap.KeyMap
+
+	// Animation is "random", "off" or the name of the one completion
+	// animation to play; TextMarkers spells out color-only signals
+	Animation   string
+	TextMarkers bool
+
+	// anims picks the completion animations when they are random;
+	// animStart is when the animation preview began and animCursor the
+	// row it has selected
+	anims      *anim.Picker
+	animStart  time.Time
+	animCursor int
+
+	// stats is the report on the statistics screen, nil while it loads
+	stats *stats.Report
+
+	// Live is true while the server's event stream is connected
+	Live bool
+
+	// taskIndex maps task IDs to their position in Tasks
+	taskIndex map[int]int
+
+	// refreshSeq identifies the active background polling loop
+	refreshSeq int
+
+	// Event stream subscription for the current session
+	events        *api.Subscription
+	streamDropped bool
+
+	// Optimistic update bookkeeping
+	pending    map[int]*pendingEdit
+	removed    map[int]removedTask
+	// touched holds the tasks edited here since the last refresh, and
+	// listView the view the tasks were loaded for; tasks leaving the list
+	// for either reason were not removed elsewhere
+	touched  map[int]bool
+	listView ViewMode
+	nextTempID int
+	toastSeq   int
 }
 
 // NewModel creates a new application model
-func NewModel(client *api.Client) Model {
+func NewModel(client *api.Client, cfg *config.Config) Model {
 	emailInput := textinput.New()
 	emailInput.Placeholder = "email@example.com"
 	emailInput.CharLimit = 100
@@ -224,16 +340,26 @@ func NewModel(client *api.Client) Model {
 	titleInput.CharLimit = 200
 	titleInput.Width = 60
 
-	notesInput := textinput.New()
-	notesInput.Placeholder = "Notes..."
-	notesInput.CharLimit = 1000
-	notesInput.Width = 60
+	notesInput := newNotesInput()
 
 	categoryInput := textinput.New()
 	categoryInput.Placeholder = "Category name..."
 	categoryInput.CharLimit = 50
 	categoryInput.Width = 40
 
+	recurInput := textinput.New()
+	recurInput.Placeholder = "weekly on mon,thu"
+	recurInput.CharLimit = 60
+	recurInput.Width = 40
+
+	templateInput := textinput.New()
+	templateInput.CharLimit = 200
+	templateInput.Width = 40
+
+	reviewInput := textinput.New()
+	reviewInput.CharLimit = 200
+	reviewInput.Width = 60
+
 	// Determine initial state based on token
 	initialState := StateLogin
 	if client.HasToken() && client.ValidateToken() {
@@ -247,6 +373,7 @@ func NewModel(client *api.Client) Model {
 
 	m := Model{
 		Client:        client,
+		Config:        cfg,
 		State:         initialState,
 		ViewMode:      ViewOpen,
 		SortMode:      SortCreated,
@@ -258,7 +385,59 @@ func NewModel(client *api.Client) Model {
 		TitleInput:    titleInput,
 		NotesInput:    notesInput,
 		CategoryInput: categoryInput,
+		RecurInput:    recurInput,
+		TemplateInput: templateInput,
+		ReviewInput:   reviewInput,
+		Spinner:       newSpinner(),
 		FocusedField:  FieldEmail,
+		Keys:          keymap.Default(),
+	}
+	m.anims, _ = anim.Default.Picker(nil)
+
+	if cfg != nil {
+		// Problems loading the local files and settings are shown together
+		var problems []string
+		store, err := recur.Load(cfg.DataDir)
+		if err != nil {
+			problems = append(problems, "Ignoring recurrence rules: "+err.Error())
+		}
+		m.Recurrence = store
+
+		pins, err := today.Load(cfg.DataDir)
+		if err != nil {
+			problems = append(problems, "Ignoring pins: "+err.Error())
+		}
+		m.Pins = pins
+
+		timer, err := timelog.Load(cfg.DataDir)
+		if err != nil {
+			problems = append(problems, "Timers are off until the time log is fixed: "+err.Error())
+		}
+		m.Timer = timer
+
+		links, err := deps.Load(cfg.DataDir)
+		if err != nil {
+			problems = append(problems, "Ignoring dependencies: "+err.Error())
+		}
+		m.Deps = links
+		if active, ok := timer.Active(); ok {
+			m.timerPhase = active.Phase(time.Now())
+		}
+
+		problems = append(problems, m.loadThemes(cfg)...)
+		problems = append(problems, m.loadAccessibility(cfg)...)
+		groupBy, ok := parseGroupBy(cfg.GroupBy)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("Unknown group_by %q, not grouping", cfg.GroupBy))
+		}
+		m.GroupBy = groupBy
+
+		keys, err := keymap.New(cfg.Keymap.Preset, cfg.Keymap.Bindings)
+		if err != nil {
+			problems = append(problems, "Ignoring "+config.KeymapFile+": "+err.Error())
+		}
+		m.Keys = keys
+		m.ErrorMsg = strings.Join(problems, "; ")
 	}
 
 	if initialState == StateLogin {
@@ -274,6 +453,9 @@ func (m Model) Init() tea.Cmd {
 		return tea.Batch(
 			m.loadTasks(),
 			m.loadCategories(),
+			m.refreshTickCmd(),
+			m.startEvents(),
+			m.timerTickCmd(),
 		)
 	}
 	return textinput.Blink
@@ -281,12 +463,18 @@ func (m Model) Init() tea.Cmd {
 
 // loadTasks creates a command to load tasks from the API
 func (m Model) loadTasks() tea.Cmd {
+	return m.fetchTasks(false)
+}
+
+// fetchTasks loads the tasks for the current view mode
+func (m Model) fetchTasks(background bool) tea.Cmd {
+	view := m.ViewMode
 	return func() tea.Msg {
 		params := api.TaskListParams{
 			Status: "open",
 			Scope:  "all",
 		}
-		switch m.ViewMode {
+		switch view {
 		case ViewCompleted:
 			params.Status = "done"
 		case ViewShared:
@@ -295,8 +483,20 @@ func (m Model) loadTasks() tea.Cmd {
 		}
 
 		tasks, err := m.Client.ListTasks(params)
-		return TasksLoadedMsg{Tasks: tasks, Err: err}
+		return TasksLoadedMsg{Tasks: tasks, Err: err, View: view, Background: background}
+	}
+}
+
+// refreshTickCmd schedules the next background refresh, or returns nil if
+// polling is disabled
+func (m Model) refreshTickCmd() tea.Cmd {
+	if m.Config == nil || m.Config.RefreshInterval <= 0 {
+		return nil
 	}
+	seq := m.refreshSeq
+	return tea.Tick(m.Config.RefreshInterval, func(time.Time) tea.Msg {
+		return RefreshTickMsg{Seq: seq}
+	})
 }
 
 // loadCategories creates a command to load categories from the API
@@ -309,10 +509,15 @@ func (m Model) loadCategories() tea.Cmd {
 
 // CurrentTheme returns the current theme
 func (m Model) CurrentTheme() themes.Theme {
-	if m.ThemeIndex >= 0 && m.ThemeIndex < len(themes.All) {
-		return themes.All[m.ThemeIndex]
+	list := m.Themes
+	if len(list) == 0 {
+		list = themes.All
+	}
+	t := list[0]
+	if m.ThemeIndex >= 0 && m.ThemeIndex < len(list) {
+		t = list[m.ThemeIndex]
 	}
-	return themes.All[0]
+	return themes.Degrade(t, m.ColorProfile)
 }
 
 // ValidateCursor ensures the cursor is within valid bounds
@@ -354,6 +559,8 @@ func (m Model) ViewModeString() string {
 		return "Completed"
 	case ViewShared:
 		return "Shared"
+	case ViewToday:
+		return "Today"
 	}
 	return "Unknown"
 }
@@ -365,48 +572,72 @@ func TickCmd() tea.Cmd {
 	})
 }
 
+// animFinishedCmd returns a command that reports the end of a task animation
+func animFinishedCmd(id int, start time.Time, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
+		return AnimFinishedMsg{ID: id, Start: start}
+	})
+}
+
+// Pages are counted in lines of the list, so a task expanded to show its
+// notes and subtasks takes more of a page than one that isn't
+
 // TotalPages returns the total number of pages
 func (m Model) TotalPages() int {
-	if len(m.Tasks) == 0 {
-		return 1
+	return len(m.pageBounds(m.listRows()))
+}
+
+// pageRange returns the start and end (exclusive) rows of the current page
+func (m Model) pageRange() (int, int) {
+	pages := m.pageBounds(m.listRows())
+	page := m.Page
+	if page >= len(pages) {
+		page = len(pages) - 1
 	}
-	pages := len(m.Tasks) / m.PageSize
-	if len(m.Tasks)%m.PageSize != 0 {
-		pages++
+	if page < 0 {
+		page = 0
 	}
-	return pages
+	return pages[page][0], pages[page][1]
 }
 
-// PageStart returns the starting index for the current page
+// PageStart returns the starting row for the current page
 func (m Model) PageStart() int {
-	return m.Page * m.PageSize
+	start, _ := m.pageRange()
+	return start
 }
 
-// PageEnd returns the ending index (exclusive) for the current page
+// PageEnd returns the ending row (exclusive) for the current page
 func (m Model) PageEnd() int {
-	end := (m.Page + 1) * m.PageSize
-	if end > len(m.Tasks) {
-		end = len(m.Tasks)
-	}
+	_, end := m.pageRange()
 	return end
 }
 
-// PageTasks returns the tasks for the current page
+// PageTasks returns the tasks shown on the current page
 func (m Model) PageTasks() []Task {
-	if len(m.Tasks) == 0 {
-		return nil
-	}
-	start := m.PageStart()
-	end := m.PageEnd()
-	if start >= len(m.Tasks) {
-		return nil
+	var tasks []Task
+	for _, r := range m.pageRows() {
+		if !r.header {
+			tasks = append(tasks, m.Tasks[r.index])
+		}
 	}
-	return m.Tasks[start:end]
+	return tasks
 }
 
-// CursorInPage returns the cursor position relative to the current page
+// CursorInPage returns the cursor's row relative to the current page
 func (m Model) CursorInPage() int {
-	return m.Cursor - m.PageStart()
+	return m.cursorRow(m.listRows()) - m.PageStart()
+}
+
+// cursorToPage moves the cursor to the first row it can rest on from the
+// start of the current page
+func (m *Model) cursorToPage() {
+	rows := m.listRows()
+	for i := m.PageStart(); i < len(rows); i++ {
+		if rows[i].stop() {
+			m.Cursor = rows[i].index
+			break
+		}
+	}
 }
 
 // ValidatePage ensures the page is within valid bounds
@@ -429,7 +660,13 @@ func (m *Model) EnsureCursorVisible() {
 		m.Page = 0
 		return
 	}
-	// Calculate which page the cursor should be on
-	m.Page = m.Cursor / m.PageSize
+	// Find the page the cursor's row is on
+	rows := m.listRows()
+	row := m.cursorRow(rows)
+	for i, p := range m.pageBounds(rows) {
+		if row >= p[0] && row < p[1] {
+			m.Page = i
+		}
+	}
 	m.ValidatePage()
 }
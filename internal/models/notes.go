@@ -0,0 +1,132 @@
+package models
+
+import (
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/editor"
+	"github.com/blackraven/todo-tui/internal/markdown"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// maxNotesHeight is how many lines the notes input grows to before it
+// scrolls
+const maxNotesHeight = 8
+
+// NotesEditedMsg is sent when the external editor exits. Inline edits
+// go back into the notes input; otherwise the task's notes are saved.
+type NotesEditedMsg struct {
+	ID     int
+	Notes  string
+	Inline bool
+	Err    error
+}
+
+// newNotesInput creates the multi-line notes input. Enter saves, so new
+// lines are inserted with Alt+Enter or Ctrl+J.
+func newNotesInput() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Notes... (Alt+Enter: new line, Ctrl+O: $EDITOR)"
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.CharLimit = 10000
+	ta.SetWidth(60)
+	ta.SetHeight(1)
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.KeyMap.InsertNewline.SetKeys("alt+enter", "ctrl+j")
+	return ta
+}
+
+// setNotesInput loads text into the notes input and focuses it
+func (m *Model) setNotesInput(text string) tea.Cmd {
+	m.NotesInput.SetValue(text)
+	m.fitNotesInput()
+	m.NotesInput.Focus()
+	return textarea.Blink
+}
+
+// fitNotesInput grows the notes input with its content
+func (m *Model) fitNotesInput() {
+	m.NotesInput.SetHeight(max(1, min(m.NotesInput.LineCount(), maxNotesHeight)))
+}
+
+// editNotesExternally opens text in $EDITOR. The terminal is handed over
+// until the editor exits.
+func editNotesExternally(id int, text string, inline bool) tea.Cmd {
+	path, err := editor.TempFile("todo-notes-*.md", text)
+	if err != nil {
+		return func() tea.Msg { return NotesEditedMsg{ID: id, Inline: inline, Err: err} }
+	}
+	cmd, err := editor.Command(path)
+	if err != nil {
+		editor.ReadBack(path)
+		return func() tea.Msg { return NotesEditedMsg{ID: id, Inline: inline, Err: err} }
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		notes, readErr := editor.ReadBack(path)
+		if err == nil {
+			err = readErr
+		}
+		return NotesEditedMsg{ID: id, Notes: notes, Inline: inline, Err: err}
+	})
+}
+
+// handleNotesEdited applies the text written in the external editor
+func (m *Model) handleNotesEdited(msg NotesEditedMsg) tea.Cmd {
+	if msg.Err != nil {
+		return m.setError("Editor: " + msg.Err.Error())
+	}
+	if msg.Inline {
+		if m.State == StateEditingNotes || m.State == StateCreatingNotes {
+			m.NotesInput.SetValue(msg.Notes)
+			m.fitNotesInput()
+		}
+		return nil
+	}
+	t := m.TaskByID(msg.ID)
+	if t == nil {
+		return nil
+	}
+	if t.Notes != nil && *t.Notes == msg.Notes || t.Notes == nil && msg.Notes == "" {
+		return nil
+	}
+	m.beginEdit(t)
+	notes := msg.Notes
+	t.Notes = &notes
+	return m.updateTaskNotes(msg.ID, notes)
+}
+
+// renderNotes formats notes as Markdown, wrapped to width. Notes of
+// completed tasks are dimmed.
+func renderNotes(notes string, width int, t themes.Theme, done bool) string {
+	text := lipgloss.NewStyle().Foreground(t.Fg)
+	st := markdown.Styles{
+		Text:    text,
+		Heading: text.Bold(true).Foreground(t.Accent),
+		Bold:    text.Bold(true),
+		Italic:  text.Italic(true),
+		Code:    text.Foreground(t.Secondary),
+		Link:    text.Underline(true).Foreground(t.Secondary),
+		Quote:   text.Italic(true).Foreground(t.Dim),
+		Marker:  lipgloss.NewStyle().Foreground(t.Dim),
+		Checked: styles.StrikeStyle,
+	}
+	if done {
+		dim := styles.StrikeStyle
+		st = markdown.Styles{
+			Text: dim, Heading: dim, Bold: dim, Italic: dim, Code: dim,
+			Link: dim, Quote: dim, Marker: st.Marker, Checked: dim,
+		}
+	}
+	return markdown.Render(notes, width, st)
+}
+
+// editNotes opens a task's notes in $EDITOR
+func (m Model) editNotes(t *Task) tea.Cmd {
+	notes := ""
+	if t.Notes != nil {
+		notes = *t.Notes
+	}
+	return editNotesExternally(t.ID, notes, false)
+}
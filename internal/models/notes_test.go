@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiLineNotes(t *testing.T) {
+	h := newHarness(t)
+	h.press("n")
+	h.typeText("Plan trip")
+	h.press("enter")
+	h.typeText("- book train")
+	h.press("ctrl+j")
+	h.typeText("- pack")
+	h.press("enter")
+
+	st := h.serverTask("Plan trip")
+	if st == nil || st.Notes == nil || *st.Notes != "- book train\n- pack" {
+		t.Fatalf("server task = %+v", st)
+	}
+
+	h.selectTitle("Plan trip")
+	h.press("enter")
+	if view := h.m.View(); !strings.Contains(view, "• book train") || !strings.Contains(view, "• pack") {
+		t.Errorf("detail view does not render the list:\n%s", view)
+	}
+}
+
+func TestNotesEditedExternally(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Write report")
+	id := h.m.CurrentTask().ID
+
+	h.send(NotesEditedMsg{ID: id, Notes: "# Outline\n\n- [x] intro"})
+	h.settle()
+	if st := h.serverTask("Write report"); st.Notes == nil || *st.Notes != "# Outline\n\n- [x] intro" {
+		t.Errorf("server notes = %v", st.Notes)
+	}
+
+	// Text from the editor goes back into the input while editing
+	h.press("E")
+	h.send(NotesEditedMsg{ID: id, Notes: "draft", Inline: true})
+	if got := h.m.NotesInput.Value(); got != "draft" || h.m.State != StateEditingNotes {
+		t.Errorf("input = %q in state %v", got, h.m.State)
+	}
+}
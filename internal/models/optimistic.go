@@ -0,0 +1,191 @@
+package models
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Mutations are applied to the local model as soon as they are requested.
+// The task as it was before the first in-flight request is kept so that a
+// failed request can put it back.
+
+// ToastDuration is how long status messages stay in the status bar
+const ToastDuration = 4 * time.Second
+
+// pendingEdit tracks in-flight updates for one task
+type pendingEdit struct {
+	prev     api.Task
+	inFlight int
+}
+
+// removedTask remembers where an optimistically deleted task was
+type removedTask struct {
+	task  Task
+	index int
+}
+
+// ToastExpiredMsg clears the status message it was scheduled for
+type ToastExpiredMsg struct {
+	Seq int
+}
+
+// IsPending returns true if the task has a request in flight
+func (m Model) IsPending(id int) bool {
+	if id < 0 {
+		return true
+	}
+	_, ok := m.pending[id]
+	return ok
+}
+
+// beginEdit snapshots a task before its first in-flight update
+func (m *Model) beginEdit(t *Task) {
+	if m.pending == nil {
+		m.pending = make(map[int]*pendingEdit)
+	}
+	p, ok := m.pending[t.ID]
+	if !ok {
+		p = &pendingEdit{prev: t.Task}
+		m.pending[t.ID] = p
+	}
+	p.inFlight++
+	m.markTouched(t.ID)
+}
+
+// markTouched notes that this client changed a task, so a refresh it leaves
+// doesn't report it removed elsewhere
+func (m *Model) markTouched(id int) {
+	if m.touched == nil {
+		m.touched = make(map[int]bool)
+	}
+	m.touched[id] = true
+}
+
+// finishEdit settles one in-flight update with the server's response. On
+// failure the task is rolled back to the last version the server confirmed.
+// The server's copy is only shown once nothing else is in flight, so a
+// later local edit is not briefly undone by an earlier response.
+func (m *Model) finishEdit(id int, server *api.Task, err error) {
+	t := m.TaskByID(id)
+	p, ok := m.pending[id]
+	if !ok {
+		if err == nil && server != nil && t != nil {
+			t.Task = *server
+		}
+		return
+	}
+
+	if err != nil {
+		if t != nil {
+			t.Task = p.prev
+		}
+		delete(m.pending, id)
+		return
+	}
+
+	p.inFlight--
+	if server != nil {
+		p.prev = *server
+	}
+	if p.inFlight <= 0 {
+		delete(m.pending, id)
+		if t != nil {
+			t.Task = p.prev
+		}
+	}
+}
+
+// removeOptimistically drops a task from the list, remembering it so a
+// failed delete can restore it
+func (m *Model) removeOptimistically(id int) {
+	i := m.indexOfTask(id)
+	if i < 0 {
+		return
+	}
+	if m.removed == nil {
+		m.removed = make(map[int]removedTask)
+	}
+	t := m.Tasks[i]
+	t.IsDeleting = false
+	m.removed[id] = removedTask{task: t, index: i}
+	m.removeTask(id)
+}
+
+// restoreRemoved puts an optimistically deleted task back where it was
+func (m *Model) restoreRemoved(id int) {
+	r, ok := m.removed[id]
+	if !ok {
+		return
+	}
+	delete(m.removed, id)
+	cursorID := m.cursorTaskID()
+	i := r.index
+	if i > len(m.Tasks) {
+		i = len(m.Tasks)
+	}
+	m.Tasks = append(m.Tasks[:i], append([]Task{r.task}, m.Tasks[i:]...)...)
+	m.reindex()
+	m.SelectTask(cursorID)
+}
+
+// addTemporaryTask inserts a placeholder for a task that is being created
+// and returns its temporary (negative) ID
+func (m *Model) addTemporaryTask(title, notes string) int {
+	m.nextTempID--
+	t := api.Task{ID: m.nextTempID, Title: title, Status: "open"}
+	if notes != "" {
+		t.Notes = &notes
+	}
+	m.Tasks = append([]Task{{Task: t}}, m.Tasks...)
+	m.reindex()
+	return t.ID
+}
+
+// actionableTask returns the task under the cursor unless it is still
+// being created and so has no server ID yet, or the cursor is on a
+// collapsed section
+func (m *Model) actionableTask() *Task {
+	if t := m.CurrentTask(); t != nil && t.ID > 0 && !m.onCollapsedHeader() {
+		return t
+	}
+	return nil
+}
+
+// categoryByID returns the loaded category with the given ID, or nil
+func (m *Model) categoryByID(id *int) *api.Category {
+	if id == nil {
+		return nil
+	}
+	for i := range m.Categories {
+		if m.Categories[i].ID == *id {
+			c := m.Categories[i]
+			return &c
+		}
+	}
+	return nil
+}
+
+// setError shows an error toast
+func (m *Model) setError(msg string) tea.Cmd {
+	m.ErrorMsg = msg
+	m.SuccessMsg = ""
+	return m.toastCmd()
+}
+
+// setSuccess shows a success toast
+func (m *Model) setSuccess(msg string) tea.Cmd {
+	m.SuccessMsg = msg
+	m.ErrorMsg = ""
+	return m.toastCmd()
+}
+
+// toastCmd schedules the current status message to be cleared
+func (m *Model) toastCmd() tea.Cmd {
+	m.toastSeq++
+	seq := m.toastSeq
+	return tea.Tick(ToastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{Seq: seq}
+	})
+}
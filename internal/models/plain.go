@@ -0,0 +1,288 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/anim"
+	"github.com/blackraven/todo-tui/internal/export"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/stats"
+)
+
+// Plain runs a Model for screen readers. Nothing is drawn except the text
+// being typed; instead each change worth hearing is printed as a line, so
+// the terminal holds a linear transcript.
+type Plain struct {
+	Model
+	last announcement
+}
+
+// announcement is what plain mode has said about the screen: what it is,
+// the item under the cursor and the latest status message
+type announcement struct {
+	Screen string
+	Focus  string
+	Toast  string
+}
+
+// NewPlain wraps a model for plain output
+func NewPlain(m Model) Plain {
+	return Plain{Model: m}
+}
+
+// Update passes the message on and prints whatever it changed
+func (p Plain) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := p.Model.Update(msg)
+	p.Model = next.(Model)
+
+	now := p.Model.describe()
+	var lines []string
+	if now.Screen != p.last.Screen {
+		lines = append(lines, now.Screen)
+		if now.Focus != "" {
+			lines = append(lines, now.Focus)
+		}
+	} else if now.Focus != p.last.Focus && now.Focus != "" {
+		lines = append(lines, now.Focus)
+	}
+	if now.Toast != p.last.Toast && now.Toast != "" {
+		lines = append(lines, now.Toast)
+	}
+	p.last = now
+	if len(lines) > 0 {
+		cmd = tea.Batch(cmd, tea.Println(strings.Join(lines, "\n")))
+	}
+	return p, cmd
+}
+
+// View shows only the field being typed in, if any
+func (p Plain) View() string {
+	m := p.Model
+	switch m.State {
+	case StateLogin, StateRegister:
+		if m.FocusedField == FieldPassword {
+			return "Password: " + strings.Repeat("*", len(m.PasswordInput.Value()))
+		}
+		return "Email: " + m.EmailInput.Value()
+	case StateCreating, StateEditing:
+		return "Title: " + m.TitleInput.Value()
+	case StateCreatingNotes, StateEditingNotes:
+		return "Notes: " + m.NotesInput.Value()
+	case StateCategoryCreate:
+		return "Category name: " + m.CategoryInput.Value()
+	case StateRecurrence:
+		return "Repeats: " + m.RecurInput.Value()
+	case StateTemplatePrompt:
+		if len(m.templatePrompts) > 0 {
+			return m.templatePrompts[0] + ": " + m.TemplateInput.Value()
+		}
+	case StateBreakdown:
+		if m.breakdown.Editing {
+			return "Subtask: " + m.ReviewInput.Value()
+		}
+	}
+	return ""
+}
+
+// describe puts the current screen into words
+func (m Model) describe() announcement {
+	var a announcement
+	if m.ErrorMsg != "" {
+		a.Toast = "Error: " + m.ErrorMsg
+	} else if m.SuccessMsg != "" {
+		a.Toast = m.SuccessMsg
+	}
+	k := m.Keys
+
+	switch m.State {
+	case StateLogin:
+		a.Screen = "Log in. Tab switches between email and password, Enter logs in, Ctrl+R registers instead."
+	case StateRegister:
+		a.Screen = "Register. Tab switches between email and password, Enter registers, Ctrl+R logs in instead."
+
+	case StateBrowse:
+		if m.Loading {
+			a.Screen = "Loading tasks."
+			break
+		}
+		a.Screen = fmt.Sprintf("%s tasks, %d, sorted by %s. Press %s for keys.",
+			m.ViewModeString(), len(m.Tasks), m.sortModeString(), keymap.Key(k.Help))
+		if m.ViewMode == ViewToday {
+			a.Screen += " " + m.todayPlain()
+		}
+		if m.GroupBy != GroupNone {
+			a.Screen += fmt.Sprintf(" Grouped by %s, %s collapses or expands a section.", strings.ToLower(m.groupByString()), keymap.Key(k.Section))
+		}
+		if m.linkFrom != 0 {
+			a.Screen += fmt.Sprintf(" Choosing what %s waits on: Enter links or unlinks the task, Esc cancels.", m.taskTitle(m.linkFrom))
+		}
+		if section := m.groupPlain(); section != "" && m.onCollapsedHeader() {
+			a.Focus = section
+		} else if t := m.CurrentTask(); t != nil {
+			a.Focus = fmt.Sprintf("%d of %d: %s", m.Cursor+1, len(m.Tasks), m.plainTask(*t))
+			if section != "" {
+				a.Focus += " " + section
+			}
+		} else {
+			a.Focus = "No tasks."
+		}
+
+	case StateCreating:
+		a.Screen = "New task. Type the title, Enter saves, Esc cancels."
+	case StateEditing:
+		a.Screen = "Edit the title. Enter saves, Esc cancels."
+	case StateCreatingNotes, StateEditingNotes:
+		a.Screen = "Notes. Enter saves, Ctrl+J starts a new line, Esc cancels."
+
+	case StateViewTask:
+		if t := m.SelectedTask(); t != nil {
+			a.Screen = "Task details. " + m.plainTask(*t)
+			if t.Notes != nil && *t.Notes != "" {
+				a.Screen += "\nNotes: " + *t.Notes
+			}
+			for i, st := range t.Subtasks {
+				a.Screen += fmt.Sprintf("\nSubtask %d: %s, %s", i+1, st.Title, plainStatus(st.Status))
+			}
+		}
+
+	case StateConfirmDelete:
+		if t := m.SelectedTask(); t != nil {
+			a.Screen = fmt.Sprintf("Delete %q? Press y to delete, n to keep it.", t.Title)
+		}
+
+	case StateCategorySelect:
+		a.Screen = "Choose a category. Enter selects, Esc cancels."
+		a.Focus = "None"
+		if m.CategoryCursor >= 0 && m.CategoryCursor < len(m.Categories) {
+			a.Focus = fmt.Sprintf("%d of %d: %s", m.CategoryCursor+1, len(m.Categories), m.Categories[m.CategoryCursor].Name)
+		}
+	case StateCategoryCreate:
+		a.Screen = "New category. Type the name, Enter creates it, Esc cancels."
+
+	case StateHelp:
+		var lines []string
+		for _, sec := range keymap.Sections {
+			for _, e := range k.HelpSection(sec) {
+				lines = append(lines, e.Keys+": "+e.Desc)
+			}
+		}
+		a.Screen = "Keys.\n" + strings.Join(lines, "\n")
+
+	case StateExport:
+		a.Screen = "Export. Enter exports, Esc cancels."
+		scope := "this view"
+		if m.ExportAll {
+			scope = "all tasks"
+		}
+		a.Focus = fmt.Sprintf("%s, %s", export.Formats[m.ExportCursor].Label(), scope)
+
+	case StateRecurrence:
+		a.Screen = "How the task repeats, for example every 2 days. Enter saves, empty to stop repeating."
+
+	case StateTemplates:
+		a.Screen = "New task from a template. Enter chooses, Esc cancels."
+		if m.TemplateCursor < len(m.Templates) {
+			a.Focus = m.Templates[m.TemplateCursor].Name
+		}
+	case StateTemplatePrompt:
+		a.Screen = "Fill in the template. Enter goes to the next value, Esc goes back."
+		if len(m.templatePrompts) > 0 {
+			a.Focus = m.templatePrompts[0]
+		}
+
+	case StateBreakdown:
+		b := m.breakdown
+		if b.Busy {
+			a.Screen = "Breaking the task down."
+			break
+		}
+		a.Screen = fmt.Sprintf("%d suggested subtasks. Enter keeps them, Esc discards them.", len(b.Items))
+		if b.Cursor < len(b.Items) {
+			a.Focus = fmt.Sprintf("%d of %d: %s", b.Cursor+1, len(b.Items), b.Items[b.Cursor].Title)
+		}
+
+	case StateThemePicker:
+		a.Screen = "Choose a theme. Enter keeps it, Esc cancels."
+		a.Focus = m.CurrentTheme().Name
+
+	case StateAnimations:
+		a.Screen = "Completion animations. Esc goes back."
+		if all := anim.Default.All(); m.animCursor < len(all) {
+			a.Focus = fmt.Sprintf("%d of %d: %s, %s", m.animCursor+1, len(all), all[m.animCursor].Name(), m.animWeight(all[m.animCursor]))
+		}
+
+	case StatePlan:
+		a.Screen = m.planPlain()
+		if m.plan != nil && m.planCursor < len(m.plan.Slots) {
+			s := m.plan.Slots[m.planCursor]
+			a.Focus = fmt.Sprintf("%d of %d: %s at %s", m.planCursor+1, len(m.plan.Slots), s.Task.Title, s.Start.Format("Monday 3:04 PM"))
+		}
+
+	case StateStats:
+		if m.stats == nil {
+			a.Screen = "Loading statistics."
+			break
+		}
+		var sb strings.Builder
+		if err := stats.WriteText(&sb, *m.stats); err == nil {
+			a.Screen = "Statistics. Esc goes back.\n" + strings.TrimSpace(sb.String())
+		}
+	}
+	return a
+}
+
+// plainTask describes a task in one line, spelling out everything the
+// list shows with color or symbols
+func (m Model) plainTask(t Task) string {
+	parts := []string{t.Title, plainStatus(t.Status)}
+	if t.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("priority %d, %s", t.Priority, priorityLevel(t.Priority)))
+	}
+	if t.Category != nil {
+		parts = append(parts, "category "+t.Category.Name)
+	}
+	if t.DueAt != nil {
+		due := "due " + t.DueAt.Format("Jan 2 3:04 PM")
+		if isOverdue(t) {
+			due += ", overdue"
+		}
+		parts = append(parts, due)
+	}
+	if r, ok := m.Recurrence.Get(t.ID); ok {
+		parts = append(parts, "repeats "+r.String())
+	}
+	if m.Pins.Has(t.ID) {
+		parts = append(parts, "pinned to today")
+	}
+	if t.NotificationsEnabled {
+		parts = append(parts, "reminders on")
+	}
+	if blocked := m.depsPlain(t.ID); blocked != "" {
+		parts = append(parts, blocked)
+	}
+	if line, _ := m.effortSummary(t); line != "" {
+		parts = append(parts, line)
+	}
+	if len(t.Subtasks) > 0 {
+		done := 0
+		for _, st := range t.Subtasks {
+			if st.Status == "done" {
+				done++
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%d of %d subtasks done", done, len(t.Subtasks)))
+	}
+	if t.IsHighlighted() {
+		parts = append(parts, t.Highlight)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plainStatus(status string) string {
+	if status == "done" {
+		return "done"
+	}
+	return "open"
+}
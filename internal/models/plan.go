@@ -0,0 +1,229 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/schedule"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// PlanLoadedMsg carries a proposed schedule
+type PlanLoadedMsg struct {
+	Plan schedule.Plan
+	Err  error
+}
+
+// PlanAppliedMsg is sent when the accepted slots have been written
+type PlanAppliedMsg struct {
+	Written, Total int
+	Err            error
+}
+
+// openPlan shows the planner and starts proposing a schedule
+func (m *Model) openPlan() tea.Cmd {
+	m.State = StatePlan
+	m.plan = nil
+	m.planCursor = 0
+	return m.loadPlan()
+}
+
+func (m Model) loadPlan() tea.Cmd {
+	client, opts := m.Client, m.planOptions()
+	return func() tea.Msg {
+		tasks, err := client.ListTasks(api.TaskListParams{Status: "open", Scope: "all"})
+		if err != nil {
+			return PlanLoadedMsg{Err: err}
+		}
+		return PlanLoadedMsg{Plan: schedule.Propose(tasks, time.Now(), opts)}
+	}
+}
+
+// planOptions are the working hours from the configuration
+func (m Model) planOptions() schedule.Options {
+	cfg := m.Config
+	if cfg == nil {
+		cfg = config.ForDataDir("")
+	}
+	return schedule.Options{Start: cfg.WorkStart, End: cfg.WorkEnd, Days: cfg.WorkDays, Ahead: cfg.PlanDays}
+}
+
+func (m *Model) handlePlanLoaded(msg PlanLoadedMsg) tea.Cmd {
+	if msg.Err != nil {
+		if m.State == StatePlan {
+			m.State = StateBrowse
+		}
+		return m.setError("Planning failed: " + msg.Err.Error())
+	}
+	m.plan = &msg.Plan
+	return nil
+}
+
+func (m *Model) handlePlanApplied(msg PlanAppliedMsg) tea.Cmd {
+	reload := m.loadTasks()
+	if msg.Err != nil {
+		return tea.Batch(m.setError(fmt.Sprintf("Scheduled %d of %d: %v", msg.Written, msg.Total, msg.Err)), reload)
+	}
+	return tea.Batch(m.setSuccess(fmt.Sprintf("Scheduled %d tasks", msg.Written)), reload)
+}
+
+// updatePlan handles input while reviewing a proposed schedule: slots can
+// be dropped, and Enter writes the rest
+func (m Model) updatePlan(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.plan == nil {
+		if key.Matches(msg, m.Keys.Back) {
+			m.State = StateBrowse
+		}
+		return m, nil
+	}
+	slots := m.plan.Slots
+	switch {
+	case key.Matches(msg, m.Keys.Back):
+		m.State = StateBrowse
+		m.plan = nil
+
+	case key.Matches(msg, m.Keys.Up):
+		if m.planCursor > 0 {
+			m.planCursor--
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if m.planCursor < len(slots)-1 {
+			m.planCursor++
+		}
+
+	case key.Matches(msg, m.Keys.Drop):
+		if m.planCursor < len(slots) {
+			plan := *m.plan
+			plan.Slots = append(append([]schedule.Slot(nil), slots[:m.planCursor]...), slots[m.planCursor+1:]...)
+			m.plan = &plan
+			m.planCursor = min(m.planCursor, max(len(plan.Slots)-1, 0))
+		}
+
+	case key.Matches(msg, m.Keys.Select):
+		m.State = StateBrowse
+		m.plan = nil
+		if len(slots) == 0 {
+			return m, nil
+		}
+		client := m.Client
+		return m, tea.Batch(m.setSuccess(fmt.Sprintf("Scheduling %d tasks...", len(slots))), func() tea.Msg {
+			n, err := schedule.Apply(client, slots)
+			return PlanAppliedMsg{Written: n, Total: len(slots), Err: err}
+		})
+	}
+	return m, nil
+}
+
+// viewPlan lists the proposed slots by day, then the tasks left out
+func (m Model) viewPlan(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// PLAN"))
+
+	containerHeight := m.Height - 7
+	body := lipgloss.NewStyle().Foreground(t.Accent).Render("Planning...")
+	if m.plan != nil {
+		lines, at := m.planLines(*m.plan, t)
+		rows := max(containerHeight-2, 1)
+		first := 0
+		if at >= rows {
+			first = at - rows + 1
+		}
+		body = strings.Join(lines[first:min(len(lines), first+rows)], "\n")
+	}
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1, 2).
+		Render(body)
+
+	help := keymap.Hints(keymap.Hint(m.Keys.Select, "Schedule"), keymap.Hint(m.Keys.Drop, "Drop"),
+		keymap.Hint(m.Keys.Back, "Cancel"))
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
+
+// planLines renders the plan and returns the index of the cursor's line
+func (m Model) planLines(p schedule.Plan, t themes.Theme) ([]string, int) {
+	label := styles.InputLabelStyle.Render
+	dim := styles.HelpStyle.Render
+	text := lipgloss.NewStyle().Foreground(t.Fg)
+
+	var lines []string
+	at := 0
+	if len(p.Slots) == 0 {
+		lines = append(lines, dim("Nothing to schedule: open tasks need an estimate and a free slot."))
+	}
+	day := ""
+	for i, s := range p.Slots {
+		if d := s.Start.Format("Monday, Jan 2"); d != day {
+			if day != "" {
+				lines = append(lines, "")
+			}
+			day = d
+			lines = append(lines, label(d))
+		}
+		line := fmt.Sprintf("%s-%s  %s", s.Start.Format("15:04"), s.End.Format("15:04"), s.Task.Title)
+		style := text
+		prefix := "  "
+		if i == m.planCursor {
+			style = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+			prefix = "> "
+			at = len(lines)
+		}
+		line = style.Render(prefix+line) + dim("  "+slotInfo(s.Task))
+		if s.Late {
+			line += lipgloss.NewStyle().Foreground(t.Warning).Render("  ends after it is due")
+		}
+		lines = append(lines, line)
+	}
+
+	if len(p.Skipped) > 0 {
+		lines = append(lines, "", label("Not scheduled"))
+		for _, s := range p.Skipped {
+			lines = append(lines, text.Render("  "+s.Task.Title)+dim("  "+s.Reason))
+		}
+	}
+	return lines, at
+}
+
+// slotInfo is the priority and due date shown beside a slot
+func slotInfo(t api.Task) string {
+	info := fmt.Sprintf("P%d", t.Priority)
+	if t.DueAt != nil {
+		info += ", due " + t.DueAt.Local().Format("Mon 3:04 PM")
+	}
+	return info
+}
+
+// planPlain describes the plan for plain mode
+func (m Model) planPlain() string {
+	if m.plan == nil {
+		return "Planning."
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Proposed schedule, %d slots. Enter schedules them, Esc cancels.", len(m.plan.Slots))
+	for _, s := range m.plan.Slots {
+		fmt.Fprintf(&sb, "\n%s to %s: %s", s.Start.Format("Monday 3:04 PM"), s.End.Format("3:04 PM"), s.Task.Title)
+		if s.Late {
+			sb.WriteString(", ends after it is due")
+		}
+	}
+	for _, s := range m.plan.Skipped {
+		fmt.Fprintf(&sb, "\nNot scheduled: %s, %s", s.Task.Title, s.Reason)
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+func TestPlanner(t *testing.T) {
+	h := newHarness(t)
+	// Work around the clock so the plan doesn't depend on when tests run
+	h.m.Config.WorkStart, h.m.Config.WorkEnd, h.m.Config.WorkDays = 0, 24*time.Hour, nil
+	h.srv.UpdateTask(h.serverTask("Fix tap").ID, func(t *api.Task) { t.EffortMin = 60 })
+	h.srv.UpdateTask(h.serverTask("Buy milk").ID, func(t *api.Task) { t.EffortMin = 30 })
+
+	h.press("P")
+	if h.m.State != StatePlan || h.m.plan == nil {
+		t.Fatalf("state = %v, plan = %v", h.m.State, h.m.plan)
+	}
+	if got := len(h.m.plan.Slots); got != 2 {
+		t.Fatalf("slots = %d", got)
+	}
+	view := h.m.View()
+	if !strings.Contains(view, "Fix tap") || !strings.Contains(view, "Not scheduled") || !strings.Contains(view, "no estimate") {
+		t.Errorf("view:\n%s", view)
+	}
+
+	// Drop the first slot and schedule the other
+	dropped := h.m.plan.Slots[0].Task.Title
+	kept := h.m.plan.Slots[1].Task.Title
+	h.press("d", "enter")
+	if h.m.State != StateBrowse {
+		t.Fatalf("state = %v", h.m.State)
+	}
+	if task := h.serverTask(kept); task.ScheduledStart == nil || !task.AutoScheduled {
+		t.Errorf("%s not scheduled: %+v", kept, task)
+	}
+	if task := h.serverTask(dropped); task.ScheduledStart != nil {
+		t.Errorf("dropped %s was scheduled", dropped)
+	}
+
+	// Its block is busy the next time round
+	h.press("P")
+	if len(h.m.plan.Slots) != 1 || h.m.plan.Slots[0].Task.Title != dropped {
+		t.Errorf("replan = %+v", h.m.plan.Slots)
+	}
+	h.press("esc")
+	h.selectTitle(kept)
+	h.press("enter")
+	if view := h.m.View(); !strings.Contains(view, "(planned)") {
+		t.Errorf("detail view:\n%s", view)
+	}
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/recur"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// TaskRecurredMsg is sent when a repeating task has been marked done.
+// Next is the new occurrence; Err is set if it could not be created.
+type TaskRecurredMsg struct {
+	ID   int
+	Task *api.Task
+	Next *api.Task
+	Err  error
+}
+
+// setTaskStatus updates a task's status, creating the next occurrence when
+// a repeating task is completed
+func (m Model) setTaskStatus(id int, status string) tea.Cmd {
+	if _, ok := m.Recurrence.Get(id); !ok || status != "done" {
+		return m.updateTaskStatus(id, status)
+	}
+	store := m.Recurrence
+	return func() tea.Msg {
+		task, err := m.Client.UpdateTask(id, api.TaskUpdateRequest{Status: &status})
+		if err != nil {
+			return TaskUpdatedMsg{ID: id, Err: err}
+		}
+		next, err := store.Complete(m.Client, *task, time.Now())
+		return TaskRecurredMsg{ID: id, Task: task, Next: next, Err: err}
+	}
+}
+
+// handleRecurred settles the completion and shows the next occurrence
+func (m *Model) handleRecurred(msg TaskRecurredMsg) tea.Cmd {
+	m.finishEdit(msg.ID, msg.Task, nil)
+	if msg.Next != nil && m.inView(*msg.Next) {
+		m.putTask(*msg.Next)
+	}
+	m.ApplySort()
+	if msg.Err != nil {
+		return m.setError("Next occurrence: " + msg.Err.Error())
+	}
+	if msg.Next != nil && msg.Next.DueAt != nil {
+		return m.setSuccess("Next occurrence due " + formatDue(*msg.Next.DueAt))
+	}
+	return nil
+}
+
+// editRecurrence opens the rule editor for a task
+func (m *Model) editRecurrence(t *Task) tea.Cmd {
+	m.SelectedTaskID = t.ID
+	m.PreviousState = m.State
+	m.State = StateRecurrence
+	m.RecurInput.SetValue("")
+	if r, ok := m.Recurrence.Get(t.ID); ok {
+		m.RecurInput.SetValue(r.String())
+	}
+	m.RecurInput.Focus()
+	m.RecurInput.CursorEnd()
+	return textinput.Blink
+}
+
+// updateRecurrence handles input in the rule editor
+func (m Model) updateRecurrence(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.State = m.PreviousState
+		m.RecurInput.Blur()
+		return m, nil
+
+	case "enter":
+		t := m.SelectedTask()
+		if t == nil || m.Recurrence == nil {
+			m.State = StateBrowse
+			return m, nil
+		}
+		val := strings.TrimSpace(m.RecurInput.Value())
+		if val == "" || val == "none" {
+			m.State = m.PreviousState
+			m.RecurInput.Blur()
+			if err := m.Recurrence.Delete(t.ID); err != nil {
+				return m, m.setError("Saving rule: " + err.Error())
+			}
+			return m, m.setSuccess("Task no longer repeats")
+		}
+		r, err := recur.Parse(val)
+		if err != nil {
+			return m, m.setError(err.Error())
+		}
+		r = r.Anchor(t.DueAt)
+		m.State = m.PreviousState
+		m.RecurInput.Blur()
+		if err := m.Recurrence.Set(t.ID, r); err != nil {
+			return m, m.setError("Saving rule: " + err.Error())
+		}
+		return m, m.setSuccess("Repeats " + r.String())
+	}
+
+	var cmd tea.Cmd
+	m.RecurInput, cmd = m.RecurInput.Update(msg)
+	return m, cmd
+}
+
+// viewRecurrence renders the rule editor with a preview of the next dates
+func (m Model) viewRecurrence(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// REPEAT"))
+
+	var s strings.Builder
+	if task := m.SelectedTask(); task != nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Bold(true).Render(task.Title))
+		s.WriteString("\n\n")
+	}
+	s.WriteString(styles.InputLabelStyle.Render("Repeat:") + "\n")
+	s.WriteString(m.RecurInput.View())
+	s.WriteString("\n\n")
+
+	dim := lipgloss.NewStyle().Foreground(t.Dim)
+	val := strings.TrimSpace(m.RecurInput.Value())
+	switch r, err := recur.Parse(val); {
+	case val == "" || val == "none":
+		s.WriteString(dim.Render("Does not repeat"))
+	case err != nil:
+		s.WriteString(styles.ErrorStyle.Render(err.Error()))
+	default:
+		s.WriteString(styles.InputLabelStyle.Render("Next:") + "\n")
+		var due *time.Time
+		if task := m.SelectedTask(); task != nil {
+			due = task.DueAt
+		}
+		r = r.Anchor(due)
+		now := time.Now()
+		for i := 0; i < 3; i++ {
+			next := r.Next(due, now)
+			s.WriteString("  " + next.Format("Mon Jan 2, 2006 3:04 PM") + "\n")
+			due, now = &next, next
+		}
+	}
+	s.WriteString("\n\n")
+	s.WriteString(dim.Render("daily · every 3 days · weekly on mon,thu · every 2 weeks on fri\n" +
+		"monthly on 15 · monthly on last · monthly on 2nd tue · every 10 days after done"))
+
+	containerHeight := m.Height - 7
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1).
+		Render(s.String())
+
+	help := "Enter: Save (empty to stop repeating) | Esc: Cancel"
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
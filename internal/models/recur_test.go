@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepeatingTaskCreatesNextOccurrence(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Write report")
+
+	h.press("@")
+	h.typeText("every 2 days")
+	h.press("enter")
+
+	id := h.m.CurrentTask().ID
+	if r, ok := h.m.Recurrence.Get(id); !ok || r.String() != "every 2 days" {
+		t.Fatalf("rule = %v, %v (status %q)", r, ok, h.m.ErrorMsg)
+	}
+
+	h.press("space")
+
+	var open, done int
+	for _, st := range h.srv.Tasks() {
+		if st.Title != "Write report" {
+			continue
+		}
+		if st.Status == "done" {
+			done++
+			continue
+		}
+		open++
+		if st.DueAt == nil || st.DueAt.Before(time.Now().AddDate(0, 0, 1)) {
+			t.Errorf("next due = %v, want two days out", st.DueAt)
+		}
+		if len(st.Subtasks) != 2 || st.Subtasks[1].Status != "open" {
+			t.Errorf("next subtasks = %+v", st.Subtasks)
+		}
+	}
+	if open != 1 || done != 1 {
+		t.Fatalf("server has %d open and %d done copies, want one of each", open, done)
+	}
+
+	h.selectTitle("Write report")
+	next := h.m.CurrentTask()
+	if next.ID == id || next.Status != "open" {
+		t.Errorf("list shows %+v, want the new occurrence", next.Task)
+	}
+	if _, ok := h.m.Recurrence.Get(next.ID); !ok {
+		t.Error("rule did not move to the new occurrence")
+	}
+}
+
+func TestRepeatRuleRejected(t *testing.T) {
+	h := newHarness(t)
+	h.press("@")
+	h.typeText("now and then")
+	h.press("enter")
+
+	if h.m.State != StateRecurrence || h.m.ErrorMsg == "" {
+		t.Errorf("state = %v, error = %q; want the editor to stay open with an error", h.m.State, h.m.ErrorMsg)
+	}
+	h.press("esc")
+	if h.m.State != StateBrowse {
+		t.Errorf("state after esc = %v", h.m.State)
+	}
+}
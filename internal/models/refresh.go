@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// applyRefresh merges a background reload of view into the list,
+// highlighting tasks that are new or changed and noting removed ones in the
+// status bar. Tasks loaded for another view are simply replaced.
+func (m *Model) applyRefresh(tasks []api.Task, view ViewMode) tea.Cmd {
+	touched := m.touched
+	sameView := m.listView == view
+	m.listView, m.touched = view, nil
+	for id := range touched {
+		// Still in flight, so not yet reflected in this reload
+		if m.IsPending(id) {
+			m.markTouched(id)
+		}
+	}
+	if !sameView {
+		m.setTasks(tasks)
+		return nil
+	}
+
+	previous := make(map[int]api.Task, len(m.Tasks))
+	for _, t := range m.Tasks {
+		if t.ID > 0 {
+			previous[t.ID] = t.Task
+		}
+	}
+
+	m.setTasks(tasks)
+
+	now := time.Now()
+	added, changed := 0, 0
+	for i := range m.Tasks {
+		t := &m.Tasks[i]
+		if t.ID < 0 || m.IsPending(t.ID) {
+			continue
+		}
+		prev, ok := previous[t.ID]
+		switch {
+		case !ok:
+			t.Highlight = "new"
+			added++
+		case !reflect.DeepEqual(prev, t.Task):
+			t.Highlight = "updated"
+			changed++
+		default:
+			continue
+		}
+		t.HighlightUntil = now.Add(HighlightDuration)
+	}
+	for _, t := range m.Tasks {
+		delete(previous, t.ID)
+	}
+	for id := range m.removed {
+		delete(previous, id)
+	}
+	// Tasks completed, moved or still being changed here left on purpose
+	for id := range previous {
+		if touched[id] || m.IsPending(id) {
+			delete(previous, id)
+		}
+	}
+	removed := len(previous)
+
+	var cmds []tea.Cmd
+	if added > 0 || changed > 0 {
+		cmds = append(cmds, tea.Tick(HighlightDuration, func(time.Time) tea.Msg {
+			return HighlightDoneMsg{}
+		}))
+	}
+	if removed > 0 {
+		var titles []string
+		for _, t := range previous {
+			titles = append(titles, t.Title)
+		}
+		sort.Strings(titles)
+		cmds = append(cmds, m.setSuccess(removedNote(titles)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// removedNote describes tasks that disappeared in a refresh
+func removedNote(titles []string) string {
+	if len(titles) == 1 {
+		return fmt.Sprintf("Removed elsewhere: %s", titles[0])
+	}
+	return fmt.Sprintf("%d tasks removed elsewhere: %s", len(titles), strings.Join(titles, ", "))
+}
+
+// IsHighlighted returns true while a refresh highlight is showing
+func (t Task) IsHighlighted() bool {
+	return t.Highlight != "" && time.Now().Before(t.HighlightUntil)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+)
+
+// refresh runs one background refresh cycle
+func (h *harness) refresh() {
+	h.t.Helper()
+	h.send(RefreshTickMsg{Seq: h.m.refreshSeq})
+}
+
+func TestRefreshHighlightsNewAndChangedTasks(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+	h.press("v")
+
+	added := h.srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Teammate task"})
+	milk := h.serverTask("Buy milk")
+	h.srv.UpdateTask(milk.ID, func(t *api.Task) { t.Priority = 8 })
+
+	h.refresh()
+
+	if task := h.m.TaskByID(added.ID); task == nil || !task.IsHighlighted() || task.Highlight != "new" {
+		t.Errorf("added task = %+v, want highlighted as new", task)
+	}
+	if task := h.m.TaskByID(milk.ID); task == nil || task.Highlight != "updated" || task.Priority != 8 {
+		t.Errorf("changed task = %+v, want highlighted as updated", task)
+	}
+	for _, task := range h.m.Tasks {
+		if task.Title == "Answer email" && task.IsHighlighted() {
+			t.Error("unchanged task was highlighted")
+		}
+	}
+	current := h.m.CurrentTask()
+	if current == nil || current.Title != "Fix tap" || !current.Expanded {
+		t.Errorf("cursor/expanded state lost: %+v", current)
+	}
+}
+
+func TestRefreshNotesRemovedTasks(t *testing.T) {
+	h := newHarness(t)
+	h.srv.DeleteTask(h.serverTask("Buy milk").ID)
+
+	h.refresh()
+
+	if len(h.m.Tasks) != 3 {
+		t.Errorf("tasks = %v, want three", h.titles())
+	}
+	if !strings.Contains(h.m.SuccessMsg, "Buy milk") {
+		t.Errorf("status = %q, want a note about Buy milk", h.m.SuccessMsg)
+	}
+}
+
+func TestRefreshAfterCompletingIsNotARemoval(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+	h.press(" ")
+
+	h.refresh()
+
+	if h.m.TaskByID(h.serverTask("Buy milk").ID) != nil {
+		t.Errorf("tasks = %v, want Buy milk gone from the open list", h.titles())
+	}
+	if strings.Contains(h.m.SuccessMsg, "removed elsewhere") || strings.Contains(h.m.SuccessMsg, "Removed elsewhere") {
+		t.Errorf("status = %q, want no removal note", h.m.SuccessMsg)
+	}
+}
+
+func TestStaleRefreshLoopStops(t *testing.T) {
+	h := newHarness(t)
+	h.srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Teammate task"})
+
+	h.send(RefreshTickMsg{Seq: h.m.refreshSeq - 1})
+
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("stale refresh tick reloaded tasks: %v", h.titles())
+	}
+}
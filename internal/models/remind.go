@@ -0,0 +1,27 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// toggleReminders turns a task's due date reminders on or off; the
+// reminder daemon sends them
+func (m *Model) toggleReminders(t *Task) tea.Cmd {
+	m.beginEdit(t)
+	t.NotificationsEnabled = !t.NotificationsEnabled
+	on := t.NotificationsEnabled
+
+	msg := "Reminders off"
+	switch {
+	case on && t.DueAt == nil:
+		msg = "Reminders on, once the task has a due date"
+	case on:
+		msg = "Reminders on"
+	}
+	id := t.ID
+	return tea.Batch(m.setSuccess(msg), func() tea.Msg {
+		task, err := m.Client.UpdateTask(id, api.TaskUpdateRequest{NotificationsEnabled: &on})
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
+	})
+}
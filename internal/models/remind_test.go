@@ -0,0 +1,27 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToggleReminders(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+	h.press("!")
+	if !h.serverTask("Fix tap").NotificationsEnabled {
+		t.Fatal("reminders not turned on")
+	}
+	if !strings.Contains(h.m.SuccessMsg, "once the task has a due date") {
+		t.Errorf("toast = %q", h.m.SuccessMsg)
+	}
+
+	h.press("enter")
+	if view := h.m.View(); !strings.Contains(view, "Reminders: on") {
+		t.Errorf("detail view:\n%s", view)
+	}
+	h.press("!")
+	if h.serverTask("Fix tap").NotificationsEnabled {
+		t.Error("reminders not turned off from the detail view")
+	}
+}
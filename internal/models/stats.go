@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/stats"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// StatsLoadedMsg is sent when the statistics have been computed
+type StatsLoadedMsg struct {
+	Report stats.Report
+	Err    error
+}
+
+// openStats shows the statistics screen and starts loading its numbers
+func (m *Model) openStats() tea.Cmd {
+	m.State = StateStats
+	m.stats = nil
+	return m.loadStats()
+}
+
+func (m Model) loadStats() tea.Cmd {
+	client := m.Client
+	return func() tea.Msg {
+		r, err := stats.Fetch(client)
+		return StatsLoadedMsg{Report: r, Err: err}
+	}
+}
+
+func (m *Model) handleStatsLoaded(msg StatsLoadedMsg) tea.Cmd {
+	if msg.Err != nil {
+		if m.State == StateStats {
+			m.State = StateBrowse
+		}
+		return m.setError("Statistics failed: " + msg.Err.Error())
+	}
+	m.stats = &msg.Report
+	return nil
+}
+
+// updateStats handles input on the statistics screen
+func (m Model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Back), key.Matches(msg, m.Keys.Stats):
+		m.State = StateBrowse
+	case key.Matches(msg, m.Keys.Refresh):
+		return m, m.loadStats()
+	}
+	return m, nil
+}
+
+// viewStats renders the statistics dashboard
+func (m Model) viewStats(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// STATS"))
+
+	body := lipgloss.NewStyle().Foreground(t.Accent).Render("Loading...")
+	if m.stats != nil {
+		body = m.statsBody(*m.stats, t)
+	}
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(m.Height - 7).
+		Padding(0, 1).
+		Render(body)
+
+	help := keymap.Hints(keymap.Hint(m.Keys.Refresh, "Refresh"), keymap.Hint(m.Keys.Back, "Back"))
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
+
+// statsBody lays out the report in two columns: completions on the left,
+// what is still open on the right
+func (m Model) statsBody(r stats.Report, t themes.Theme) string {
+	label := styles.InputLabelStyle.Render
+	text := lipgloss.NewStyle().Foreground(t.Fg).Render
+	dim := styles.HelpStyle.Render
+	bar := lipgloss.NewStyle().Foreground(t.Secondary).Render
+
+	var left strings.Builder
+	left.WriteString(label("Completed") + "\n")
+	daily := r.DailyCounts()
+	left.WriteString(lipgloss.NewStyle().Foreground(t.Accent).Render(stats.Sparkline(daily)) + " " +
+		text(fmt.Sprintf("%d in %d days", total(daily), len(daily))) + "\n\n")
+
+	top := 1
+	for _, n := range r.WeeklyCounts() {
+		top = max(top, n)
+	}
+	for _, c := range r.Weekly {
+		start, _ := time.Parse(time.DateOnly, c.Start)
+		left.WriteString(dim(start.Format("Jan 02")) + text(fmt.Sprintf(" %3d ", c.Done)) + bar(stats.Bar(c.Done, top, 20)) + "\n")
+	}
+
+	left.WriteString("\n")
+	if r.Measured > 0 {
+		left.WriteString(text("Average time to complete: " + stats.Duration(r.AvgCompletionHours)) + "\n")
+	} else {
+		left.WriteString(dim("No completion times yet") + "\n")
+	}
+	left.WriteString(text(fmt.Sprintf("Subtasks done: %d of %d (%.0f%%)", r.Subtasks.Done, r.Subtasks.Total, r.Subtasks.Rate*100)))
+
+	var right strings.Builder
+	right.WriteString(label("Open") + " " + text(fmt.Sprintf("%d, %d overdue, %d done", r.Open, r.Overdue, r.Done)) + "\n\n")
+	for _, c := range r.Categories {
+		overdue := dim(fmt.Sprintf("%3d overdue", c.Overdue))
+		if c.Overdue > 0 {
+			overdue = styles.OverdueStyle.UnsetBlink().Render(fmt.Sprintf("%3d overdue", c.Overdue))
+		}
+		right.WriteString(text(fmt.Sprintf("%-14.14s %3d open ", c.Name, c.Open)) + overdue + "\n")
+	}
+
+	right.WriteString("\n" + label("By priority") + "\n")
+	top = 1
+	for _, p := range r.Priorities {
+		top = max(top, p.Open+p.Done)
+	}
+	for _, p := range r.Priorities {
+		right.WriteString(m.priorityBadge(p.Priority) + text(fmt.Sprintf(" %3d open %3d done ", p.Open, p.Done)) +
+			bar(stats.Bar(p.Open+p.Done, top, 12)) + "\n")
+	}
+
+	width := max((m.Width-10)/2, 20)
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(width).Render(left.String()),
+		lipgloss.NewStyle().Width(width).Render(right.String()))
+}
+
+func total(ns []int) int {
+	sum := 0
+	for _, n := range ns {
+		sum += n
+	}
+	return sum
+}
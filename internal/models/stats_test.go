@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatsScreen(t *testing.T) {
+	h := newHarness(t)
+	h.press("S")
+	if h.m.State != StateStats || h.m.stats == nil {
+		t.Fatalf("state %v, stats %v", h.m.State, h.m.stats)
+	}
+	r := h.m.stats
+	if r.Open != 4 || r.Done != 1 || r.Subtasks.Total != 2 || r.Subtasks.Done != 1 {
+		t.Errorf("report = %+v", *r)
+	}
+	if r.Daily[len(r.Daily)-1].Done != 1 {
+		t.Errorf("today's completions = %d", r.Daily[len(r.Daily)-1].Done)
+	}
+
+	view := h.m.View()
+	t.Log("\n" + view)
+	for _, want := range []string{"// STATS", "1 in 14 days", "Work", "Subtasks done: 1 of 2 (50%)", "P9"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q", want)
+		}
+	}
+
+	// Completing a task shows up after a refresh
+	h.press("esc")
+	h.selectTitle("Buy milk")
+	h.press(" ")
+	h.settle()
+	h.press("S")
+	if h.m.stats.Done != 2 || h.m.stats.Open != 3 {
+		t.Errorf("after completing: open %d, done %d", h.m.stats.Open, h.m.stats.Done)
+	}
+	h.press("esc")
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v after esc", h.m.State)
+	}
+}
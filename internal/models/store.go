@@ -0,0 +1,130 @@
+package models
+
+import "github.com/blackraven/todo-tui/internal/api"
+
+// The task list is kept in display order in Model.Tasks. Asynchronous
+// results refer to tasks by ID, so every lookup goes through the index
+// below rather than through a slice position captured earlier.
+
+// reindex rebuilds the ID index after m.Tasks has been replaced or reordered
+func (m *Model) reindex() {
+	m.taskIndex = make(map[int]int, len(m.Tasks))
+	for i, t := range m.Tasks {
+		m.taskIndex[t.ID] = i
+	}
+}
+
+// indexOfTask returns the position of the task with the given ID, or -1
+func (m *Model) indexOfTask(id int) int {
+	if i, ok := m.taskIndex[id]; ok && i < len(m.Tasks) && m.Tasks[i].ID == id {
+		return i
+	}
+	// The index is stale; fall back to a scan and repair it
+	for i, t := range m.Tasks {
+		if t.ID == id {
+			m.reindex()
+			return i
+		}
+	}
+	return -1
+}
+
+// TaskByID returns the task with the given ID, or nil if it is not loaded
+func (m *Model) TaskByID(id int) *Task {
+	if i := m.indexOfTask(id); i >= 0 {
+		return &m.Tasks[i]
+	}
+	return nil
+}
+
+// SelectedTask returns the task chosen for the detail, category or delete
+// screens, or nil if it is no longer loaded
+func (m *Model) SelectedTask() *Task {
+	return m.TaskByID(m.SelectedTaskID)
+}
+
+// cursorTaskID returns the ID of the task under the cursor, or 0
+func (m *Model) cursorTaskID() int {
+	if t := m.CurrentTask(); t != nil {
+		return t.ID
+	}
+	return 0
+}
+
+// SelectTask moves the cursor onto the task with the given ID. It returns
+// false and leaves the cursor alone if the task is not loaded.
+func (m *Model) SelectTask(id int) bool {
+	i := m.indexOfTask(id)
+	if i < 0 {
+		return false
+	}
+	m.Cursor = i
+	m.EnsureCursorVisible()
+	return true
+}
+
+// setTasks replaces the task list with freshly loaded data, carrying over
+// per-task UI state and keeping the cursor on the same task where possible
+func (m *Model) setTasks(tasks []api.Task) {
+	cursorID := m.cursorTaskID()
+	oldTasks := m.Tasks
+	previous := make(map[int]Task, len(oldTasks))
+	for _, t := range oldTasks {
+		previous[t.ID] = t
+	}
+
+	m.Tasks = make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		if _, deleting := m.removed[t.ID]; deleting {
+			continue
+		}
+		task := Task{Task: t}
+		if old, ok := previous[t.ID]; ok {
+			// Local edits still in flight win over the loaded copy
+			if m.IsPending(t.ID) {
+				task.Task = old.Task
+			}
+			task.Expanded = old.Expanded
+			task.Highlight = old.Highlight
+			task.HighlightUntil = old.HighlightUntil
+			task.IsAnimatingCheck = old.IsAnimatingCheck
+			task.IsDeleting = old.IsDeleting
+			task.Anim = old.Anim
+			task.AnimStart = old.AnimStart
+		}
+		m.Tasks = append(m.Tasks, task)
+	}
+	// Keep placeholders for tasks that are still being created
+	for _, old := range oldTasks {
+		if old.ID < 0 {
+			m.Tasks = append(m.Tasks, old)
+		}
+	}
+	m.sortTasks()
+	m.SelectTask(cursorID)
+}
+
+// putTask updates a loaded task in place, or appends it if it is new
+func (m *Model) putTask(t api.Task) {
+	if existing := m.TaskByID(t.ID); existing != nil {
+		existing.Task = t
+		return
+	}
+	m.Tasks = append(m.Tasks, Task{Task: t})
+	m.reindex()
+}
+
+// removeTask drops the task with the given ID, keeping the cursor on the
+// task it pointed at unless that is the one being removed
+func (m *Model) removeTask(id int) {
+	i := m.indexOfTask(id)
+	if i < 0 {
+		return
+	}
+	cursorID := m.cursorTaskID()
+	m.Tasks = append(m.Tasks[:i], m.Tasks[i+1:]...)
+	m.reindex()
+	if cursorID == id || !m.SelectTask(cursorID) {
+		m.ValidateCursor()
+	}
+}
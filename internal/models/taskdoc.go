@@ -0,0 +1,113 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/editor"
+	"github.com/blackraven/todo-tui/internal/taskdoc"
+)
+
+// TaskDocEditedMsg is sent when the editor opened on a whole task exits.
+// Orig is the task the document was made from and Before the text the
+// editor was given.
+type TaskDocEditedMsg struct {
+	Orig   api.Task
+	Before string
+	Text   string
+	Err    error
+}
+
+// TaskDocFailedMsg is sent when saving a task document fails part way.
+// Task is the server's copy of the task afterwards, nil if it couldn't be
+// fetched, and Text the document, which is opened again.
+type TaskDocFailedMsg struct {
+	Orig api.Task
+	Task *api.Task
+	Text string
+	Err  error
+}
+
+// editTaskDoc opens a task as a document in $EDITOR
+func (m Model) editTaskDoc(t *Task) tea.Cmd {
+	return openTaskDoc(t.Task, taskdoc.Marshal(t.Task))
+}
+
+func openTaskDoc(orig api.Task, text string) tea.Cmd {
+	fail := func(err error) tea.Cmd {
+		return func() tea.Msg { return TaskDocEditedMsg{Orig: orig, Before: text, Err: err} }
+	}
+	path, err := editor.TempFile("todo-task-*.md", text)
+	if err != nil {
+		return fail(err)
+	}
+	cmd, err := editor.Command(path)
+	if err != nil {
+		editor.ReadBack(path)
+		return fail(err)
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		edited, readErr := editor.ReadBack(path)
+		if err == nil {
+			err = readErr
+		}
+		return TaskDocEditedMsg{Orig: orig, Before: text, Text: edited, Err: err}
+	})
+}
+
+// handleTaskDocEdited works out what changed in the document and saves it.
+// A document with mistakes, or one that fails to save, is opened again
+// with the problems noted at the top; leaving it unchanged, or empty, gives
+// up.
+func (m *Model) handleTaskDocEdited(msg TaskDocEditedMsg) tea.Cmd {
+	if msg.Err != nil {
+		return m.setError("Editor: " + msg.Err.Error())
+	}
+	if strings.TrimSpace(msg.Text) == "" || msg.Text == strings.TrimSuffix(msg.Before, "\n") {
+		return nil
+	}
+
+	doc, err := taskdoc.Parse(msg.Text)
+	var plan taskdoc.Plan
+	if err == nil {
+		plan, err = taskdoc.Diff(msg.Orig, doc)
+	}
+	if err != nil {
+		return openTaskDoc(msg.Orig, taskdoc.Annotate(msg.Text, err))
+	}
+	if plan.Empty() {
+		return nil
+	}
+
+	t := m.TaskByID(msg.Orig.ID)
+	if t == nil {
+		return m.setError("The task is no longer in the list")
+	}
+	m.beginEdit(t)
+	orig := msg.Orig
+	categories := append([]api.Category(nil), m.Categories...)
+	return tea.Batch(m.setSuccess("Saving "+plan.Summary()), func() tea.Msg {
+		task, err := taskdoc.Apply(m.Client, orig, plan, categories)
+		if err != nil {
+			fresh, _ := m.Client.GetTask(orig.ID)
+			return TaskDocFailedMsg{Orig: orig, Task: fresh, Text: msg.Text, Err: err}
+		}
+		return TaskUpdatedMsg{ID: orig.ID, Task: task}
+	})
+}
+
+// handleTaskDocFailed shows the task as the server has it after a save
+// that failed part way, and opens the document again with the error noted
+// at the top, to be compared with that copy next time
+func (m *Model) handleTaskDocFailed(msg TaskDocFailedMsg) tea.Cmd {
+	orig := msg.Orig
+	if msg.Task != nil {
+		orig = *msg.Task
+		m.finishEdit(orig.ID, msg.Task, nil)
+	} else {
+		m.finishEdit(orig.ID, nil, msg.Err)
+	}
+	return tea.Batch(m.setError("Update failed: "+msg.Err.Error()),
+		openTaskDoc(orig, taskdoc.Annotate(msg.Text, msg.Err)))
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/taskdoc"
+)
+
+func TestEditTaskDocument(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Write report")
+	orig := h.m.CurrentTask().Task
+	before := taskdoc.Marshal(orig)
+
+	text := strings.Replace(before, "title: Write report", "title: Write the report", 1)
+	text = strings.Replace(text, "priority: 9", "priority: 3", 1)
+	text += "- [ ] Proofread\n"
+	h.send(TaskDocEditedMsg{Orig: orig, Before: before, Text: text})
+
+	st := h.serverTask("Write the report")
+	if st == nil || st.Priority != 3 || len(st.Subtasks) != 3 {
+		t.Fatalf("server task = %+v", st)
+	}
+	if cur := h.m.CurrentTask(); cur.Title != "Write the report" || len(cur.Subtasks) != 3 || h.m.IsPending(cur.ID) {
+		t.Errorf("list task = %+v", cur.Task)
+	}
+}
+
+func TestEditTaskDocumentInvalid(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+	h := newHarness(t)
+	h.selectTitle("Write report")
+	orig := h.m.CurrentTask().Task
+	before := taskdoc.Marshal(orig)
+
+	text := strings.Replace(before, "title: Write report", "title: Write the report", 1)
+	text = strings.Replace(text, "status: open", "status: maybe", 1)
+	next, cmd := h.m.Update(TaskDocEditedMsg{Orig: orig, Before: before, Text: text})
+	h.m = next.(Model)
+	if cmd == nil {
+		t.Fatal("the editor was not opened again")
+	}
+	if h.serverTask("Write report") == nil {
+		t.Error("an invalid document was saved")
+	}
+}
+
+func TestEditTaskDocumentSaveFails(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+	h := newHarness(t)
+	h.selectTitle("Write report")
+	orig := h.m.CurrentTask().Task
+	before := taskdoc.Marshal(orig)
+	h.srv.InjectFault(fakeserver.Fault{Method: "POST", PathPrefix: fmt.Sprintf("/tasks/%d/subtasks", orig.ID), Status: 500, Count: 1})
+
+	// The title is saved before adding the subtask fails, and the list
+	// shows that rather than rolling back
+	text := strings.Replace(before, "title: Write report", "title: Write the report", 1)
+	text += "- [ ] Proofread\n"
+	h.send(TaskDocEditedMsg{Orig: orig, Before: before, Text: text})
+	if cur := h.m.CurrentTask(); cur.Title != "Write the report" || len(cur.Subtasks) != 2 || h.m.IsPending(cur.ID) {
+		t.Errorf("list task = %+v", cur.Task)
+	}
+	if !strings.Contains(h.m.ErrorMsg, "adding subtask") {
+		t.Errorf("error = %q", h.m.ErrorMsg)
+	}
+
+	// The document is opened again, even when the task can't be fetched
+	next, cmd := h.m.Update(TaskDocFailedMsg{Orig: orig, Text: text, Err: fmt.Errorf("offline")})
+	h.m = next.(Model)
+	if cmd == nil {
+		t.Fatal("the editor was not opened again")
+	}
+}
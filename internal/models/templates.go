@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/templates"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// openTemplates loads the templates and shows the picker
+func (m *Model) openTemplates() {
+	m.Templates = nil
+	if m.Config != nil {
+		list, err := templates.Load(m.Config.DataDir)
+		if err != nil {
+			m.ErrorMsg = "Templates: " + err.Error()
+		}
+		m.Templates = list
+	}
+	m.TemplateCursor = 0
+	m.State = StateTemplates
+}
+
+// updateTemplates handles input in the template picker
+func (m Model) updateTemplates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Back):
+		m.State = StateBrowse
+
+	case key.Matches(msg, m.Keys.Up):
+		if m.TemplateCursor > 0 {
+			m.TemplateCursor--
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if m.TemplateCursor < len(m.Templates)-1 {
+			m.TemplateCursor++
+		}
+
+	case key.Matches(msg, m.Keys.Select):
+		if m.TemplateCursor >= len(m.Templates) {
+			break
+		}
+		m.templateVars = make(map[string]string)
+		m.templatePrompts = m.Templates[m.TemplateCursor].Prompts()
+		if len(m.templatePrompts) == 0 {
+			return m, m.useTemplate()
+		}
+		m.State = StateTemplatePrompt
+		m.TemplateInput.SetValue("")
+		m.TemplateInput.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// updateTemplatePrompt asks for the template's variables one at a time
+func (m Model) updateTemplatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.TemplateInput.Blur()
+		m.State = StateTemplates
+		return m, nil
+
+	case "enter":
+		m.templateVars[m.templatePrompts[0]] = strings.TrimSpace(m.TemplateInput.Value())
+		m.templatePrompts = m.templatePrompts[1:]
+		m.TemplateInput.SetValue("")
+		if len(m.templatePrompts) > 0 {
+			return m, nil
+		}
+		m.TemplateInput.Blur()
+		return m, m.useTemplate()
+	}
+
+	var cmd tea.Cmd
+	m.TemplateInput, cmd = m.TemplateInput.Update(msg)
+	return m, cmd
+}
+
+// useTemplate shows the new task straight away and creates it, with its
+// subtasks, in the background
+func (m *Model) useTemplate() tea.Cmd {
+	m.State = StateBrowse
+	tmpl, err := m.Templates[m.TemplateCursor].Expand(m.templateVars, time.Now())
+	if err != nil {
+		return m.setError(err.Error())
+	}
+	if m.ViewMode != ViewOpen {
+		m.ViewMode = ViewOpen
+		m.Loading = true
+		return tea.Batch(m.loadTasks(), m.instantiate(0, tmpl))
+	}
+
+	tempID := m.addTemporaryTask(tmpl.Title, tmpl.Notes)
+	m.SelectTask(tempID)
+	m.EnsureCursorVisible()
+	return m.instantiate(tempID, tmpl)
+}
+
+func (m Model) instantiate(tempID int, tmpl templates.Template) tea.Cmd {
+	categories := append([]api.Category(nil), m.Categories...)
+	return func() tea.Msg {
+		task, err := templates.Instantiate(m.Client, tmpl, categories)
+		return TaskCreatedMsg{TempID: tempID, Task: task, Err: err}
+	}
+}
+
+// viewTemplates renders the template picker and the variable prompts
+func (m Model) viewTemplates(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// NEW FROM TEMPLATE"))
+
+	var s strings.Builder
+	dim := lipgloss.NewStyle().Foreground(t.Dim)
+	if len(m.Templates) == 0 {
+		dir := templates.DirName
+		if m.Config != nil {
+			dir = templates.Dir(m.Config.DataDir)
+		}
+		s.WriteString(dim.Render("No templates yet.\n\n" +
+			"Save a task as one with 'todo-tui template save <name> <task-id>',\n" +
+			"or add JSON files to " + dir))
+	}
+	for i, tmpl := range m.Templates {
+		row := fmt.Sprintf("  %-16s %s", tmpl.Name, tmpl.Title)
+		if n := len(tmpl.Subtasks); n > 0 {
+			row += fmt.Sprintf("  [%d subtasks]", n)
+		}
+		if m.TemplateCursor == i {
+			s.WriteString(styles.ListSelectedStyle.Render(row))
+		} else {
+			s.WriteString(styles.ListItemStyle.Render(row))
+		}
+		s.WriteString("\n")
+	}
+
+	help := "Enter: Create | Esc: Cancel"
+	if m.State == StateTemplatePrompt && len(m.templatePrompts) > 0 {
+		s.WriteString("\n")
+		s.WriteString(styles.InputLabelStyle.Render(m.templatePrompts[0]+":") + "\n")
+		s.WriteString(m.TemplateInput.View())
+		help = "Enter: Next | Esc: Back"
+	}
+
+	containerHeight := m.Height - 7
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1).
+		Render(s.String())
+
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
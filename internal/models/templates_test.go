@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/templates"
+)
+
+func TestNewTaskFromTemplate(t *testing.T) {
+	h := newHarness(t)
+	err := templates.Save(h.m.Config.DataDir, templates.Template{
+		Name:     "release",
+		Title:    "Release {{prompt:version}}",
+		Category: "Work",
+		Subtasks: []string{"Tag v{{prompt:version}}", "Publish notes"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.press("T", "enter")
+	if h.m.State != StateTemplatePrompt {
+		t.Fatalf("state = %v, want the version prompt", h.m.State)
+	}
+	h.typeText("1.4")
+	h.press("enter")
+
+	st := h.serverTask("Release 1.4")
+	if st == nil {
+		t.Fatalf("task not created; status %q", h.m.ErrorMsg)
+	}
+	if st.Category == nil || st.Category.Name != "Work" || len(st.Subtasks) != 2 || st.Subtasks[0].Title != "Tag v1.4" {
+		t.Errorf("server task = %+v", st)
+	}
+
+	h.selectTitle("Release 1.4")
+	if cur := h.m.CurrentTask(); cur.ID <= 0 || len(cur.Subtasks) != 2 {
+		t.Errorf("list task = %+v, want the created task with subtasks", cur.Task)
+	}
+}
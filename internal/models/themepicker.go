@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/keymap"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+// hasDarkBackground asks the terminal for its background color; tests
+// replace it
+var hasDarkBackground = lipgloss.HasDarkBackground
+
+// loadThemes adds the user's theme files to the built-ins and picks the
+// starting theme. It returns the problems worth showing, if any.
+func (m *Model) loadThemes(cfg *config.Config) []string {
+	m.Themes = themes.All
+	m.ColorProfile = lipgloss.ColorProfile()
+
+	var problems []string
+	custom, err := themes.Load(filepath.Join(cfg.DataDir, themes.Dir), themes.All)
+	if err != nil {
+		problems = append(problems, "Ignoring theme files: "+strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	m.Themes = themes.Merge(themes.All, custom)
+
+	idx := 0
+	if cfg.Theme != "" {
+		if idx = themes.Find(m.Themes, cfg.Theme); idx < 0 {
+			idx = 0
+			problems = append(problems, fmt.Sprintf("Unknown theme %q", cfg.Theme))
+		}
+	}
+	switch cfg.ThemeVariant {
+	case "light":
+		idx = themes.Variant(m.Themes, idx, true)
+	case "dark":
+		idx = themes.Variant(m.Themes, idx, false)
+	case "auto":
+		idx = themes.Variant(m.Themes, idx, !hasDarkBackground())
+	}
+	m.ThemeIndex = idx
+	styles.Update(m.CurrentTheme())
+	return problems
+}
+
+// openThemePicker shows the theme list, remembering the theme to go back to
+func (m *Model) openThemePicker() {
+	m.themeOrig = m.ThemeIndex
+	m.State = StateThemePicker
+}
+
+// updateThemePicker handles input in the theme picker. Moving the cursor
+// switches the theme straight away so the whole screen previews it.
+func (m Model) updateThemePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Back):
+		m.ThemeIndex = m.themeOrig
+		styles.Update(m.CurrentTheme())
+		m.State = StateBrowse
+
+	case key.Matches(msg, m.Keys.Up):
+		if m.ThemeIndex > 0 {
+			m.ThemeIndex--
+			styles.Update(m.CurrentTheme())
+		}
+
+	case key.Matches(msg, m.Keys.Down):
+		if m.ThemeIndex < len(m.Themes)-1 {
+			m.ThemeIndex++
+			styles.Update(m.CurrentTheme())
+		}
+
+	case key.Matches(msg, m.Keys.Select):
+		m.State = StateBrowse
+		name := m.CurrentTheme().Name
+		if m.Config != nil {
+			if err := m.Config.SaveTheme(name); err != nil {
+				return m, m.setError("Theme not saved: " + err.Error())
+			}
+		}
+		return m, m.setSuccess("Theme: " + name)
+	}
+	return m, nil
+}
+
+// viewThemePicker renders the theme list next to a sample of the theme
+// under the cursor
+func (m Model) viewThemePicker(t themes.Theme) string {
+	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
+		styles.HeaderStyle.Render("// THEME"))
+
+	containerHeight := m.Height - 7
+	rows := max(containerHeight-2, 1)
+	first := 0
+	if m.ThemeIndex >= rows {
+		first = m.ThemeIndex - rows + 1
+	}
+
+	var list strings.Builder
+	for i := first; i < len(m.Themes) && i < first+rows; i++ {
+		th := m.Themes[i]
+		name := th.Name
+		if th.Source != "" {
+			name += " *"
+		}
+		if i == m.ThemeIndex {
+			list.WriteString(lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("> " + name))
+		} else {
+			list.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Render("  " + name))
+		}
+		list.WriteString("\n")
+	}
+	listCol := lipgloss.NewStyle().Width(26).Render(list.String())
+
+	previewCol := lipgloss.NewStyle().Width(max(m.Width-36, 20)).Render(m.themePreview(t))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Width(m.Width - 4).
+		Height(containerHeight).
+		Padding(1).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, listCol, previewCol))
+
+	help := keymap.Hints(keymap.Hint(m.Keys.Select, "Keep"), keymap.Hint(m.Keys.Back, "Cancel")) + " | * from a file"
+	status := m.renderStatusBar(help)
+
+	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
+	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
+}
+
+// themePreview shows where a theme's colors end up
+func (m Model) themePreview(t themes.Theme) string {
+	var s strings.Builder
+	kind := "dark"
+	if t.Light {
+		kind = "light"
+	}
+	if t.Family != t.Name {
+		kind += ", variant of " + t.Family
+	}
+	s.WriteString(styles.InputLabelStyle.Render(t.Name) + " " + styles.HelpStyle.Render("("+kind+")") + "\n")
+	if t.Source != "" {
+		s.WriteString(styles.HelpStyle.Render(t.Source) + "\n")
+	}
+	s.WriteString("\n")
+
+	swatch := func(c lipgloss.Color, label string) string {
+		return lipgloss.NewStyle().Foreground(c).Render("██") + " " + label
+	}
+	s.WriteString(strings.Join([]string{swatch(t.Fg, "text"), swatch(t.Dim, "dim"),
+		swatch(t.Accent, "accent"), swatch(t.Secondary, "secondary")}, "  ") + "\n")
+	s.WriteString(strings.Join([]string{swatch(t.Success, "success"), swatch(t.Warning, "warning"),
+		swatch(t.Border, "border")}, "  ") + "\n\n")
+
+	badge := lipgloss.NewStyle().Background(lipgloss.Color("#45B7D1")).Foreground(t.BadgeFg).Padding(0, 1).Render("Work")
+	s.WriteString(styles.ListSelectedStyle.Render("[ ] Write report "+styles.PriorityHighStyle.Render("P9")) + "\n")
+	s.WriteString(styles.ListItemStyle.Render("[ ] Answer email "+styles.PriorityMedStyle.Render("P5")+" "+badge) + "\n")
+	s.WriteString(styles.ListItemStyle.Render("[ ] Buy milk "+styles.PriorityLowStyle.Render("P2")+" "+styles.DueStyle.Render("due tomorrow")) + "\n")
+	s.WriteString(styles.ListItemStyle.Render(styles.StrikeStyle.Render("[x] Old chore")) + "\n\n")
+	s.WriteString(styles.SuccessStyle.Render("Task completed") + "  " + styles.ErrorStyle.Render("Could not save"))
+	return s.String()
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/config"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+func TestThemePicker(t *testing.T) {
+	h := newHarness(t)
+	t.Cleanup(styles.Init)
+
+	h.press("t")
+	h.press("down")
+	if h.m.State != StateThemePicker || h.m.CurrentTheme().Name != "Nord" {
+		t.Fatalf("state %v, theme %s", h.m.State, h.m.CurrentTheme().Name)
+	}
+	h.press("esc")
+	if h.m.CurrentTheme().Name != "Catppuccin" {
+		t.Errorf("cancel left %s", h.m.CurrentTheme().Name)
+	}
+
+	h.press("t")
+	h.press("down")
+	h.press("down")
+	h.press("enter")
+	if h.m.State != StateBrowse || h.m.CurrentTheme().Name != "Gruvbox" {
+		t.Fatalf("state %v, theme %s", h.m.State, h.m.CurrentTheme().Name)
+	}
+	data, err := os.ReadFile(filepath.Join(h.m.Config.DataDir, config.FileName))
+	if err != nil || !strings.Contains(string(data), `"theme": "Gruvbox"`) {
+		t.Errorf("config.json = %s, %v", data, err)
+	}
+}
+
+func TestStartingTheme(t *testing.T) {
+	t.Cleanup(styles.Init)
+	dark := true
+	orig := hasDarkBackground
+	hasDarkBackground = func() bool { return dark }
+	t.Cleanup(func() { hasDarkBackground = orig })
+
+	cfg := config.ForDataDir(t.TempDir())
+	os.MkdirAll(filepath.Join(cfg.DataDir, themes.Dir), 0700)
+	os.WriteFile(filepath.Join(cfg.DataDir, themes.Dir, "mine.toml"), []byte("extends = \"Nord\"\naccent = \"#ff0000\"\n"), 0600)
+	client := api.NewClient(cfg)
+
+	cfg.Theme = "Nord"
+	dark = false
+	if m := NewModel(client, cfg); m.CurrentTheme().Name != "Nord Light" {
+		t.Errorf("light terminal got %s", m.CurrentTheme().Name)
+	}
+	cfg.ThemeVariant = "dark"
+	if m := NewModel(client, cfg); m.CurrentTheme().Name != "Nord" {
+		t.Errorf("dark variant got %s", m.CurrentTheme().Name)
+	}
+
+	cfg.Theme = "mine"
+	m := NewModel(client, cfg)
+	if th := m.CurrentTheme(); th.Name != "mine" || th.Accent != "#ff0000" {
+		t.Errorf("custom theme = %+v (status %q)", th, m.ErrorMsg)
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+	"github.com/blackraven/todo-tui/internal/timelog"
+)
+
+// TimerTickMsg redraws the running clock; Seq drops ticks from a timer
+// that has since been stopped or replaced
+type TimerTickMsg struct {
+	Seq int
+}
+
+// ringBell sounds the terminal bell; tests replace it
+var ringBell = func() { fmt.Fprint(os.Stdout, "\a") }
+
+// toggleTimer stops the timer if it is running on the task in the same
+// mode, and otherwise starts one there, stopping any other first
+func (m *Model) toggleTimer(t *Task, pomodoro bool) tea.Cmd {
+	if m.Timer == nil {
+		return m.setError("The time log is not available")
+	}
+	now := time.Now()
+	if active, ok := m.Timer.Active(); ok && active.TaskID == t.ID && (active.Pomodoro != nil) == pomodoro {
+		logged, stopped, err := m.Timer.Stop(now)
+		m.timerSeq++
+		if err != nil {
+			return m.setError("Time not saved: " + err.Error())
+		}
+		// It may have been stopped from the command line already, in
+		// which case start a new one
+		if stopped {
+			return m.setSuccess(fmt.Sprintf("Logged %s on %s", formatLogged(logged), t.Title))
+		}
+	}
+
+	timer := timelog.Timer{TaskID: t.ID, Title: t.Title, Start: now}
+	if pomodoro && m.Config != nil {
+		timer.Pomodoro = &timelog.Pomodoro{Work: m.Config.PomodoroWork, Break: m.Config.PomodoroBreak}
+	}
+	logged, err := m.Timer.Start(timer)
+	if err != nil {
+		return m.setError("Timer not saved: " + err.Error())
+	}
+	m.timerSeq++
+	m.timerPhase = timer.Phase(now)
+
+	msg := "Timer started on " + t.Title
+	if timer.Pomodoro != nil {
+		msg = fmt.Sprintf("Pomodoro started: %s of work on %s", formatEffort(int(timer.Pomodoro.Work/time.Minute)), t.Title)
+	}
+	if len(logged) > 0 && logged[0].TaskID != t.ID {
+		msg += fmt.Sprintf(" (logged %s on %s)", formatLogged(logged), logged[0].Title)
+	}
+	return tea.Batch(m.setSuccess(msg), m.timerTickCmd())
+}
+
+func (m Model) timerTickCmd() tea.Cmd {
+	seq := m.timerSeq
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return TimerTickMsg{Seq: seq}
+	})
+}
+
+// handleTimerTick keeps the clock ticking and rings the bell when a
+// pomodoro timer moves between work and break
+func (m *Model) handleTimerTick(msg TimerTickMsg) tea.Cmd {
+	active, ok := m.Timer.Active()
+	if msg.Seq != m.timerSeq || !ok {
+		return nil
+	}
+	cmds := []tea.Cmd{m.timerTickCmd()}
+	phase := active.Phase(time.Now())
+	if phase.Work != m.timerPhase.Work || phase.Cycle != m.timerPhase.Cycle {
+		cmds = append(cmds, func() tea.Msg { ringBell(); return nil })
+		if phase.Work {
+			cmds = append(cmds, m.setSuccess(fmt.Sprintf("Back to work: pomodoro %d on %s", phase.Cycle, active.Title)))
+		} else {
+			cmds = append(cmds, m.setSuccess(fmt.Sprintf("Pomodoro %d done, take a %s break",
+				phase.Cycle, formatEffort(int(active.Pomodoro.Break/time.Minute)))))
+		}
+	}
+	m.timerPhase = phase
+	return tea.Batch(cmds...)
+}
+
+// timerStatus is the running clock shown at the start of the status bar
+func (m Model) timerStatus() string {
+	active, ok := m.Timer.Active()
+	if !ok {
+		return ""
+	}
+	now := time.Now()
+	title := active.Title
+	if r := []rune(title); len(r) > 20 {
+		title = string(r[:19]) + "…"
+	}
+	if active.Pomodoro == nil {
+		return styles.SuccessStyle.Render("● "+timelog.Format(now.Sub(active.Start))) + " " + styles.HelpStyle.Render(title)
+	}
+	phase := active.Phase(now)
+	if !phase.Work {
+		return styles.HelpStyle.Render(fmt.Sprintf("◌ Break %s left · %s", timelog.Format(phase.Left), title))
+	}
+	return styles.SuccessStyle.Render(fmt.Sprintf("● Pomodoro %d %s left", phase.Cycle, timelog.Format(phase.Left))) +
+		" " + styles.HelpStyle.Render(title)
+}
+
+// effortSummary compares the time logged on a task with its estimate, or
+// returns "" if there is neither
+func (m Model) effortSummary(task Task) (string, bool) {
+	logged := m.Timer.Logged(task.ID, time.Now())
+	mins := int(logged / time.Minute)
+	switch {
+	case task.EffortMin > 0 && logged > 0:
+		line := fmt.Sprintf("%s logged of %s estimated (%d%%)", formatEffort(mins), formatEffort(task.EffortMin),
+			mins*100/task.EffortMin)
+		return line, mins > task.EffortMin
+	case task.EffortMin > 0:
+		return formatEffort(task.EffortMin) + " estimated, nothing logged", false
+	case logged > 0:
+		return formatEffort(mins) + " logged, no estimate", false
+	}
+	return "", false
+}
+
+// viewEffort renders the detail view's effort line
+func (m Model) viewEffort(task Task, t themes.Theme) string {
+	line, over := m.effortSummary(task)
+	if line == "" {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(t.Fg)
+	if over {
+		style = lipgloss.NewStyle().Foreground(t.Warning)
+		if m.TextMarkers {
+			line += ", over estimate"
+		}
+	}
+	return "Effort: " + style.Render(line) + "\n"
+}
+
+// formatLogged adds up sessions for a toast
+func formatLogged(sessions []timelog.Session) string {
+	var total time.Duration
+	for _, s := range sessions {
+		total += s.Duration()
+	}
+	if total < time.Minute {
+		return fmt.Sprintf("%ds", int(total.Seconds()))
+	}
+	return formatEffort(int(total / time.Minute))
+}
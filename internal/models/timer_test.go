@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/timelog"
+)
+
+func TestTimer(t *testing.T) {
+	h := newHarness(t)
+	h.srv.UpdateTask(h.serverTask("Fix tap").ID, func(t *api.Task) { t.EffortMin = 60 })
+	h.press("r")
+
+	// The clock ticks every second, so keys are queued and the timer is
+	// stopped before settling
+	h.selectTitle("Fix tap")
+	h.queue("w")
+	active, ok := h.m.Timer.Active()
+	if !ok || active.Title != "Fix tap" {
+		t.Fatalf("active = %+v, %v", active, ok)
+	}
+	if view := h.m.View(); !strings.Contains(view, "● 0:00") {
+		t.Errorf("no clock in the status bar:\n%s", view)
+	}
+
+	// Pressing it on another task moves the timer there
+	h.selectTitle("Buy milk")
+	h.queue("w")
+	if active, _ := h.m.Timer.Active(); active.Title != "Buy milk" {
+		t.Errorf("active after switching = %s", active.Title)
+	}
+	if !strings.Contains(h.m.SuccessMsg, "logged 0s on Fix tap") {
+		t.Errorf("switch toast = %q", h.m.SuccessMsg)
+	}
+	h.queue("w")
+	h.settle()
+	if _, ok := h.m.Timer.Active(); ok {
+		t.Error("timer still running")
+	}
+	if got := len(h.m.Timer.Sessions(0, time.Time{})); got != 2 {
+		t.Errorf("sessions = %d", got)
+	}
+
+	// Logged time shows against the estimate in the detail view
+	id := h.serverTask("Fix tap").ID
+	h.m.Timer.Start(timelog.Timer{TaskID: id, Title: "Fix tap", Start: time.Now().Add(-90 * time.Minute)})
+	h.m.Timer.Stop(time.Now())
+	h.selectTitle("Fix tap")
+	h.press("enter")
+	if view := h.m.View(); !strings.Contains(view, "1h 30m logged of 1h estimated (150%)") {
+		t.Errorf("detail view:\n%s", view)
+	}
+}
+
+func TestPomodoroBell(t *testing.T) {
+	h := newHarness(t)
+	rings := 0
+	orig := ringBell
+	ringBell = func() { rings++ }
+	t.Cleanup(func() { ringBell = orig })
+
+	h.selectTitle("Buy milk")
+	h.queue("W")
+	if !strings.Contains(h.m.SuccessMsg, "Pomodoro started: 25m of work") {
+		t.Fatalf("toast = %q", h.m.SuccessMsg)
+	}
+
+	// Wind the timer back so the next tick lands in the first break
+	active, _ := h.m.Timer.Active()
+	active.Start = time.Now().Add(-26 * time.Minute)
+	h.m.Timer.Start(active)
+	h.deliver(TimerTickMsg{Seq: h.m.timerSeq})
+	if !strings.Contains(h.m.SuccessMsg, "Pomodoro 1 done, take a 5m break") {
+		t.Errorf("toast = %q", h.m.SuccessMsg)
+	}
+	if view := h.m.View(); !strings.Contains(view, "◌ Break 4:") {
+		t.Errorf("no break in the status bar:\n%s", view)
+	}
+
+	h.queue("W")
+	h.settle()
+	if rings != 1 {
+		t.Errorf("bell rang %d times", rings)
+	}
+	if got := h.m.Timer.Logged(h.serverTask("Buy milk").ID, time.Now()); got != 25*time.Minute {
+		t.Errorf("logged = %s, want the work interval only", got)
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+	"github.com/blackraven/todo-tui/internal/today"
+)
+
+// togglePin pins a task to the Today list, or unpins it
+func (m *Model) togglePin(t *Task) tea.Cmd {
+	if m.Pins == nil {
+		return m.setError("Pins are not available")
+	}
+	pinned := !m.Pins.Has(t.ID)
+	if err := m.Pins.Set(t.ID, pinned); err != nil {
+		return m.setError("Pin not saved: " + err.Error())
+	}
+	if pinned {
+		return m.setSuccess("Pinned to Today")
+	}
+	return m.setSuccess("Unpinned")
+}
+
+// deferTask moves an open task's due date, and any block scheduled today,
+// to tomorrow and unpins it, so it drops off today's list
+func (m *Model) deferTask(t *Task) tea.Cmd {
+	if t.Status == "done" {
+		return nil
+	}
+	now := time.Now()
+	due := today.Tomorrow(t.Task, now)
+	req := api.TaskUpdateRequest{DueAt: &due}
+	req.ScheduledStart, req.ScheduledEnd = today.TomorrowBlock(t.Task, now)
+	m.beginEdit(t)
+	t.DueAt = &due
+	if req.ScheduledStart != nil {
+		t.ScheduledStart, t.ScheduledEnd = req.ScheduledStart, req.ScheduledEnd
+	}
+	var cmds []tea.Cmd
+	if m.Pins.Has(t.ID) {
+		if err := m.Pins.Set(t.ID, false); err != nil {
+			cmds = append(cmds, m.setError("Pin not saved: "+err.Error()))
+		}
+	}
+	if len(cmds) == 0 {
+		cmds = append(cmds, m.setSuccess("Deferred to "+due.Format("Mon Jan 2 3:04 PM")))
+	}
+	return tea.Batch(append(cmds, m.updateTaskFields(t.ID, req))...)
+}
+
+func (m Model) updateTaskFields(id int, req api.TaskUpdateRequest) tea.Cmd {
+	return func() tea.Msg {
+		task, err := m.Client.UpdateTask(id, req)
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
+	}
+}
+
+// inToday reports whether a task belongs on the Today list
+func (m Model) inToday(t api.Task) bool {
+	_, ok := today.Why(t, m.Pins, time.Now())
+	return ok && !m.isBlocked(t.ID)
+}
+
+// todaySummary renders the line above the Today list: how many tasks there
+// are and their estimated effort against the daily capacity
+func (m Model) todaySummary(t themes.Theme) string {
+	line, over := m.todayEffort()
+	style := lipgloss.NewStyle().Foreground(t.Fg)
+	if over {
+		style = lipgloss.NewStyle().Foreground(t.Warning)
+		if m.TextMarkers {
+			line = "OVER CAPACITY " + line
+		}
+	}
+	return styles.InputLabelStyle.Render("Today") + "  " + style.Render(line)
+}
+
+// todayPlain is the Today summary for plain mode
+func (m Model) todayPlain() string {
+	line, over := m.todayEffort()
+	if over {
+		line += ", over capacity"
+	}
+	return "Planned: " + line + "."
+}
+
+// todayEffort describes the Today list's estimated effort and reports
+// whether it is more than the daily capacity
+func (m Model) todayEffort() (string, bool) {
+	var tasks []api.Task
+	for _, task := range m.Tasks {
+		tasks = append(tasks, task.Task)
+	}
+	effort := today.Effort(tasks)
+	capacity := m.dailyCapacity()
+	if capacity == 0 {
+		return fmt.Sprintf("%d tasks, %s estimated", len(tasks), formatEffort(effort)), false
+	}
+	line := fmt.Sprintf("%d tasks, %s of %s", len(tasks), formatEffort(effort), formatEffort(capacity))
+	if effort > capacity {
+		line += fmt.Sprintf(", %s over", formatEffort(effort-capacity))
+	}
+	return line, effort > capacity
+}
+
+// dailyCapacity is the configured capacity in minutes, 0 if none
+func (m Model) dailyCapacity() int {
+	if m.Config == nil {
+		return 0
+	}
+	return int(m.Config.DailyCapacity / time.Minute)
+}
+
+// formatEffort renders minutes as e.g. "45m", "2h" or "1h 30m"
+func formatEffort(min int) string {
+	switch {
+	case min < 60:
+		return fmt.Sprintf("%dm", min)
+	case min%60 == 0:
+		return fmt.Sprintf("%dh", min/60)
+	}
+	return fmt.Sprintf("%dh %dm", min/60, min%60)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+func TestTodayView(t *testing.T) {
+	h := newHarness(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	h.srv.UpdateTask(h.serverTask("Answer email").ID, func(t *api.Task) {
+		t.DueAt = &yesterday
+		t.EffortMin = 30
+	})
+	h.srv.UpdateTask(h.serverTask("Write report").ID, func(t *api.Task) { t.EffortMin = 400 })
+	start := time.Now().Truncate(time.Minute)
+	end := start.Add(30 * time.Minute)
+	h.srv.UpdateTask(h.serverTask("Fix tap").ID, func(t *api.Task) {
+		t.ScheduledStart, t.ScheduledEnd = &start, &end
+	})
+
+	h.selectTitle("Buy milk")
+	h.press("p")
+	h.selectTitle("Write report")
+	h.press("p")
+	if !h.m.Pins.Has(h.serverTask("Buy milk").ID) {
+		t.Fatal("Buy milk not pinned")
+	}
+
+	h.press("tab", "tab", "tab")
+	if h.m.ViewMode != ViewToday {
+		t.Fatalf("view = %v", h.m.ViewMode)
+	}
+	if got := strings.Join(h.titles(), ", "); got != "Write report, Answer email, Fix tap, Buy milk" {
+		t.Errorf("today = %s", got)
+	}
+	view := h.m.View()
+	if !strings.Contains(view, "4 tasks, 7h 10m of 6h, 1h 10m over") || !strings.Contains(view, "⚑") {
+		t.Errorf("view:\n%s", view)
+	}
+
+	// Deferring moves the due date to tomorrow, keeping the time of day
+	h.selectTitle("Answer email")
+	h.press(">")
+	due := h.serverTask("Answer email").DueAt
+	want := time.Now().AddDate(0, 0, 1)
+	if due == nil || due.YearDay() != want.YearDay() || due.Hour() != yesterday.Hour() {
+		t.Errorf("deferred due = %v", due)
+	}
+
+	// and a block scheduled today with it, so the task leaves the list
+	h.selectTitle("Fix tap")
+	h.press(">")
+	task := h.serverTask("Fix tap")
+	if task.ScheduledStart == nil || !task.ScheduledStart.Equal(start.AddDate(0, 0, 1)) ||
+		task.ScheduledEnd == nil || !task.ScheduledEnd.Equal(end.AddDate(0, 0, 1)) {
+		t.Errorf("deferred block = %v to %v", task.ScheduledStart, task.ScheduledEnd)
+	}
+
+	// Unpinning and reloading drops a task from the list
+	h.selectTitle("Buy milk")
+	h.press("p", "r")
+	if got := strings.Join(h.titles(), ", "); got != "Write report" {
+		t.Errorf("after defer and unpin: %s", got)
+	}
+}
@@ -1,15 +1,17 @@
 package models
 
 import (
+	"fmt"
 	"math/rand"
 	"sort"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/blackraven/todo-tui/internal/api"
-	"github.com/blackraven/todo-tui/internal/styles"
-	"github.com/blackraven/todo-tui/internal/themes"
+	"github.com/blackraven/todo-tui/internal/today"
 )
 
 // Update handles all messages and updates the model
@@ -21,26 +23,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Width = msg.Width
 		m.Height = msg.Height
 		m.TitleInput.Width = msg.Width - 20
-		m.NotesInput.Width = msg.Width - 20
+		m.NotesInput.SetWidth(msg.Width - 20)
 		m.EmailInput.Width = min(40, msg.Width-20)
 		m.PasswordInput.Width = min(40, msg.Width-20)
 
 	case TasksLoadedMsg:
-		m.Loading = false
+		if msg.View != m.ViewMode {
+			// Result for a view that is no longer shown
+			break
+		}
+		if !msg.Background {
+			m.Loading = false
+		}
+		if msg.Err == nil {
+			cmds = append(cmds, m.trackStatus(msg.Tasks, msg.View == ViewOpen || msg.View == ViewToday))
+		}
+		if msg.View == ViewToday {
+			msg.Tasks = m.withoutBlocked(today.Select(msg.Tasks, m.Pins, time.Now()))
+		}
 		if msg.Err != nil {
 			m.ErrorMsg = msg.Err.Error()
 			// Check if unauthorized
 			if apiErr, ok := msg.Err.(*api.APIError); ok && apiErr.IsUnauthorized() {
 				m.State = StateLogin
+				m.refreshSeq++
+				m.stopEvents()
 				m.Client.ClearToken()
 			}
+		} else if msg.Background {
+			cmds = append(cmds, m.applyRefresh(msg.Tasks, msg.View))
 		} else {
 			m.ErrorMsg = ""
-			m.Tasks = make([]Task, len(msg.Tasks))
-			for i, t := range msg.Tasks {
-				m.Tasks[i] = Task{Task: t}
-			}
-			m.ApplySort()
+			m.setTasks(msg.Tasks)
+			m.listView, m.touched = msg.View, nil
 		}
 		m.ValidateCursor()
 		m.EnsureCursorVisible()
@@ -52,53 +67,112 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case TaskCreatedMsg:
 		m.Loading = false
+		// Swap the placeholder for the real task
+		cursorID := m.cursorTaskID()
+		m.removeTask(msg.TempID)
+		// A task can come back with an error if only its subtasks failed
+		if msg.Task != nil {
+			if cursorID == msg.TempID {
+				cursorID = msg.Task.ID
+			}
+			m.putTask(*msg.Task)
+			m.sortTasks()
+			m.trackStatus([]api.Task{*msg.Task}, false)
+		}
 		if msg.Err != nil {
-			m.ErrorMsg = msg.Err.Error()
+			cmds = append(cmds, m.setError("Create failed: "+msg.Err.Error()))
 		} else if msg.Task != nil {
-			m.SuccessMsg = "Task created"
-			newTask := Task{Task: *msg.Task}
-			m.Tasks = append(m.Tasks, newTask)
-			m.ApplySort()
-			m.Cursor = len(m.Tasks) - 1
-			m.ValidateCursor()
+			cmds = append(cmds, m.setSuccess("Task created"))
+		}
+		m.SelectTask(cursorID)
+		m.ValidateCursor()
+		if msg.Breakdown && msg.Err == nil && msg.Task != nil {
+			m.reviewCreated(*msg.Task)
 		}
-		m.State = StateBrowse
-		m.TitleInput.Blur()
-		m.NotesInput.Blur()
 
 	case TaskUpdatedMsg:
 		m.Loading = false
+		m.finishEdit(msg.ID, msg.Task, msg.Err)
 		if msg.Err != nil {
-			m.ErrorMsg = msg.Err.Error()
+			cmds = append(cmds, m.setError("Update failed: "+msg.Err.Error()))
 		} else if msg.Task != nil {
-			// Update the task in our list
-			for i := range m.Tasks {
-				if m.Tasks[i].ID == msg.Task.ID {
-					m.Tasks[i].Task = *msg.Task
-					break
-				}
-			}
-			m.ApplySort()
+			cmds = append(cmds, m.trackStatus([]api.Task{*msg.Task}, false))
 		}
-		if m.State == StateEditing || m.State == StateEditingNotes {
-			m.State = StateBrowse
-			m.TitleInput.Blur()
-			m.NotesInput.Blur()
+		m.ApplySort()
+
+	case BreakdownProposedMsg:
+		cmds = append(cmds, m.handleBreakdownProposed(msg))
+
+	case BreakdownAppliedMsg:
+		cmds = append(cmds, m.handleBreakdownApplied(msg))
+		m.ApplySort()
+
+	case spinner.TickMsg:
+		// Keep spinning only while the AI is working
+		if m.breakdown.Busy {
+			var cmd tea.Cmd
+			m.Spinner, cmd = m.Spinner.Update(msg)
+			cmds = append(cmds, cmd)
 		}
 
+	case NotesEditedMsg:
+		cmds = append(cmds, m.handleNotesEdited(msg))
+
+	case TaskDocEditedMsg:
+		cmds = append(cmds, m.handleTaskDocEdited(msg))
+
+	case TaskDocFailedMsg:
+		cmds = append(cmds, m.handleTaskDocFailed(msg))
+		m.ApplySort()
+
+	case TaskRecurredMsg:
+		m.Loading = false
+		cmds = append(cmds, m.handleRecurred(msg))
+
 	case TaskDeletedMsg:
 		m.Loading = false
 		if msg.Err != nil {
-			m.ErrorMsg = msg.Err.Error()
+			m.restoreRemoved(msg.ID)
+			cmds = append(cmds, m.setError("Delete failed: "+msg.Err.Error()))
 		} else {
-			// Remove the task from our list
-			if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
-				m.Tasks = append(m.Tasks[:m.SelectedTaskIdx], m.Tasks[m.SelectedTaskIdx+1:]...)
+			delete(m.removed, msg.ID)
+			m.removeTask(msg.ID)
+			if m.Recurrence != nil {
+				m.Recurrence.Delete(msg.ID)
 			}
+			if m.Pins.Has(msg.ID) {
+				m.Pins.Set(msg.ID, false)
+			}
+			cmds = append(cmds, m.forgetTask(msg.ID))
 		}
-		m.State = StateBrowse
 		m.ValidateCursor()
 
+	case TimerTickMsg:
+		cmds = append(cmds, m.handleTimerTick(msg))
+
+	case PlanLoadedMsg:
+		cmds = append(cmds, m.handlePlanLoaded(msg))
+
+	case PlanAppliedMsg:
+		cmds = append(cmds, m.handlePlanApplied(msg))
+
+	case StatsLoadedMsg:
+		cmds = append(cmds, m.handleStatsLoaded(msg))
+
+	case ExportedMsg:
+		m.Loading = false
+		if msg.Err != nil {
+			cmds = append(cmds, m.setError("Export failed: "+msg.Err.Error()))
+		} else {
+			cmds = append(cmds, m.setSuccess(fmt.Sprintf("Exported %d tasks to %s", msg.Count, msg.Path)))
+		}
+
+	case ToastExpiredMsg:
+		if msg.Seq == m.toastSeq {
+			m.ErrorMsg = ""
+			m.SuccessMsg = ""
+		}
+
 	case LoginMsg:
 		m.Loading = false
 		if msg.Err != nil {
@@ -107,8 +181,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.ErrorMsg = ""
 			m.SuccessMsg = "Login successful"
 			m.State = StateBrowse
-			// Load user data
-			cmds = append(cmds, m.loadTasks(), m.loadCategories())
+			// Load user data and start polling
+			m.refreshSeq++
+			cmds = append(cmds, m.loadTasks(), m.loadCategories(), m.refreshTickCmd(), m.startEvents())
 		}
 
 	case RegisterMsg:
@@ -119,37 +194,57 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.ErrorMsg = ""
 			m.SuccessMsg = "Registration successful"
 			m.State = StateBrowse
-			// Load user data
-			cmds = append(cmds, m.loadTasks(), m.loadCategories())
+			// Load user data and start polling
+			m.refreshSeq++
+			cmds = append(cmds, m.loadTasks(), m.loadCategories(), m.refreshTickCmd(), m.startEvents())
 		}
 
-	case TickMsg:
-		needsTick := false
-		for i := range m.Tasks {
-			t := &m.Tasks[i]
-
-			if t.IsDeleting {
-				if time.Since(t.AnimStart) > DeleteAnimDuration {
-					// Delete from API
-					m.SelectedTaskIdx = i
-					cmds = append(cmds, m.deleteTask(t.ID))
-				} else {
-					needsTick = true
-				}
-			}
+	case RefreshTickMsg:
+		if msg.Seq != m.refreshSeq || m.State == StateLogin || m.State == StateRegister {
+			break
+		}
+		if !m.Live {
+			cmds = append(cmds, m.fetchTasks(true), m.loadCategories())
+		}
+		cmds = append(cmds, m.refreshTickCmd())
 
-			if t.IsAnimatingCheck {
-				if time.Since(t.AnimStart) > CheckAnimDuration {
-					t.IsAnimatingCheck = false
-				} else {
-					needsTick = true
-				}
-			}
+	case StreamStartedMsg:
+		if msg.Seq != m.refreshSeq || m.State == StateLogin || m.State == StateRegister {
+			// The session ended while the stream was opening
+			msg.Sub.Close()
+			break
+		}
+		m.stopEvents()
+		m.events = msg.Sub
+		cmds = append(cmds, listenEvents(m.events, m.refreshSeq))
+
+	case StreamUpdateMsg:
+		if msg.Seq != m.refreshSeq {
+			break
 		}
-		if needsTick {
+		cmds = append(cmds, m.handleStreamUpdate(msg.Update))
+
+	case HighlightDoneMsg:
+		// Nothing to update; receiving the message redraws without the highlights
+
+	case TickMsg:
+		// Ticks only drive redraws; animations end via AnimFinishedMsg
+		if IsAnimating(m.Tasks) || m.State == StateAnimations {
 			cmds = append(cmds, TickCmd())
 		}
 
+	case AnimFinishedMsg:
+		t := m.TaskByID(msg.ID)
+		if t == nil || !t.AnimStart.Equal(msg.Start) {
+			break
+		}
+		t.IsAnimatingCheck = false
+		if t.IsDeleting {
+			// Drop the row now and delete from API
+			m.removeOptimistically(t.ID)
+			cmds = append(cmds, m.deleteTask(msg.ID))
+		}
+
 	case tea.KeyMsg:
 		// Clear status messages on key press
 		m.ErrorMsg = ""
@@ -171,6 +266,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateConfirmDelete(msg)
 		case StateHelp:
 			return m.updateHelp(msg)
+		case StateExport:
+			return m.updateExport(msg)
+		case StateRecurrence:
+			return m.updateRecurrence(msg)
+		case StateTemplates:
+			return m.updateTemplates(msg)
+		case StateTemplatePrompt:
+			return m.updateTemplatePrompt(msg)
+		case StateBreakdown:
+			return m.updateBreakdown(msg)
+		case StateThemePicker:
+			return m.updateThemePicker(msg)
+		case StateAnimations:
+			return m.updateAnimations(msg)
+		case StateStats:
+			return m.updateStats(msg)
+		case StatePlan:
+			return m.updatePlan(msg)
 		default:
 			return m.updateBrowse(msg)
 		}
@@ -241,8 +354,22 @@ func (m Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg.String() {
+	case "ctrl+b":
+		// Ask for an AI breakdown when the task is created
+		if m.State == StateCreating || m.State == StateCreatingNotes {
+			m.CreateBreakdown = !m.CreateBreakdown
+			return m, nil
+		}
+
+	case "ctrl+o":
+		// Continue the notes in $EDITOR
+		if m.State == StateCreatingNotes || m.State == StateEditingNotes {
+			return m, editNotesExternally(m.EditingTaskID, m.NotesInput.Value(), true)
+		}
+
 	case "esc":
 		m.State = StateBrowse
+		m.CreateBreakdown = false
 		m.TitleInput.Blur()
 		m.NotesInput.Blur()
 		m.TempTitle = ""
@@ -261,17 +388,20 @@ func (m Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Store title and move to notes
 			m.TempTitle = val
 			m.State = StateCreatingNotes
-			m.NotesInput.SetValue("")
-			m.NotesInput.Focus()
 			m.TitleInput.Blur()
-			return m, textinput.Blink
+			return m, m.setNotesInput("")
 		}
 
 		if m.State == StateCreatingNotes {
-			// Create the task
+			// Show the task straight away and create it in the background
 			notes := m.NotesInput.Value()
-			m.Loading = true
-			return m, m.createTask(m.TempTitle, notes)
+			tempID := m.addTemporaryTask(m.TempTitle, notes)
+			m.State = StateBrowse
+			m.NotesInput.Blur()
+			m.SelectTask(tempID)
+			breakdown := m.CreateBreakdown
+			m.CreateBreakdown = false
+			return m, m.createTask(tempID, m.TempTitle, notes, breakdown)
 		}
 
 		if m.State == StateEditing {
@@ -281,17 +411,24 @@ func (m Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.TitleInput.Blur()
 				return m, nil
 			}
-			if m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-				m.Loading = true
-				return m, m.updateTaskTitle(m.Tasks[m.Cursor].ID, val)
+			if t := m.TaskByID(m.EditingTaskID); t != nil {
+				m.beginEdit(t)
+				t.Title = val
+				m.State = StateBrowse
+				m.TitleInput.Blur()
+				m.ApplySort()
+				return m, m.updateTaskTitle(m.EditingTaskID, val)
 			}
 		}
 
 		if m.State == StateEditingNotes {
 			val := m.NotesInput.Value()
-			if m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-				m.Loading = true
-				return m, m.updateTaskNotes(m.Tasks[m.Cursor].ID, val)
+			if t := m.TaskByID(m.EditingTaskID); t != nil {
+				m.beginEdit(t)
+				t.Notes = &val
+				m.State = StateBrowse
+				m.NotesInput.Blur()
+				return m, m.updateTaskNotes(m.EditingTaskID, val)
 			}
 		}
 	}
@@ -301,6 +438,7 @@ func (m Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.TitleInput, cmd = m.TitleInput.Update(msg)
 	} else {
 		m.NotesInput, cmd = m.NotesInput.Update(msg)
+		m.fitNotesInput()
 	}
 
 	return m, cmd
@@ -310,69 +448,96 @@ func (m Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
-	switch msg.String() {
-	case "q", "ctrl+c":
+	// Picking the task another waits on
+	if m.linkFrom != 0 {
+		switch {
+		case key.Matches(msg, m.Keys.Back):
+			m.linkFrom = 0
+			return m, nil
+		case key.Matches(msg, m.Keys.Open):
+			if t := m.actionableTask(); t != nil {
+				return m, m.toggleLink(t)
+			}
+			return m, nil
+		}
+	}
+
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
 		return m, tea.Quit
 
-	case "up", "k":
-		if m.Cursor > 0 {
-			m.Cursor--
-			m.EnsureCursorVisible()
-		}
+	case key.Matches(msg, m.Keys.Up):
+		m.moveCursor(-1)
 
-	case "down", "j":
-		if m.Cursor < len(m.Tasks)-1 {
-			m.Cursor++
-			m.EnsureCursorVisible()
-		}
+	case key.Matches(msg, m.Keys.Down):
+		m.moveCursor(1)
 
-	case "left", "h":
+	case key.Matches(msg, m.Keys.PrevPage):
 		// Previous page
 		if m.Page > 0 {
 			m.Page--
-			m.Cursor = m.PageStart()
+			m.cursorToPage()
 		}
 
-	case "right", "l":
+	case key.Matches(msg, m.Keys.NextPage):
 		// Next page
 		if m.Page < m.TotalPages()-1 {
 			m.Page++
-			m.Cursor = m.PageStart()
+			m.cursorToPage()
 		}
 
-	case "pgup":
+	case key.Matches(msg, m.Keys.PageUp):
 		// Jump to previous page
 		if m.Page > 0 {
 			m.Page--
-			m.Cursor = m.PageStart()
+			m.cursorToPage()
 		}
 
-	case "pgdown":
+	case key.Matches(msg, m.Keys.PageDown):
 		// Jump to next page
 		if m.Page < m.TotalPages()-1 {
 			m.Page++
-			m.Cursor = m.PageStart()
+			m.cursorToPage()
 		}
 
-	case "tab":
+	case key.Matches(msg, m.Keys.First):
+		m.cursorToEnd(false)
+
+	case key.Matches(msg, m.Keys.Last):
+		m.cursorToEnd(true)
+
+	case key.Matches(msg, m.Keys.SwitchView):
 		// Cycle view modes
-		m.ViewMode = (m.ViewMode + 1) % 3
+		m.ViewMode = (m.ViewMode + 1) % 4
 		m.Cursor = 0
 		m.Page = 0
 		m.Loading = true
 		cmds = append(cmds, m.loadTasks())
 
-	case "t":
-		// Cycle themes
-		m.ThemeIndex = (m.ThemeIndex + 1) % len(themes.All)
-		styles.Update(themes.All[m.ThemeIndex])
+	case key.Matches(msg, m.Keys.Theme):
+		m.openThemePicker()
+
+	case key.Matches(msg, m.Keys.Animations):
+		cmds = append(cmds, m.openAnimations())
 
-	case "s":
+	case key.Matches(msg, m.Keys.Stats):
+		cmds = append(cmds, m.openStats())
+
+	case key.Matches(msg, m.Keys.Plan):
+		cmds = append(cmds, m.openPlan())
+
+	case key.Matches(msg, m.Keys.Sort):
 		// Cycle sort modes
 		m.SortMode = (m.SortMode + 1) % 4
 		m.ApplySort()
 
-	case "n":
+	case key.Matches(msg, m.Keys.Group):
+		m.cycleGroupBy()
+
+	case key.Matches(msg, m.Keys.Section):
+		m.toggleSection()
+
+	case key.Matches(msg, m.Keys.New):
 		// New task - appears at top of first page
 		m.State = StateCreating
 		m.TitleInput.SetValue("")
@@ -381,83 +546,123 @@ func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.Page = 0
 		return m, textinput.Blink
 
-	case "e":
+	case key.Matches(msg, m.Keys.Edit):
 		// Edit task title
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
+		if t := m.actionableTask(); t != nil {
 			m.State = StateEditing
-			m.EditingTaskID = m.Tasks[m.Cursor].ID
-			m.TitleInput.SetValue(m.Tasks[m.Cursor].Title)
+			m.EditingTaskID = t.ID
+			m.TitleInput.SetValue(t.Title)
 			m.TitleInput.Focus()
 			m.TitleInput.SetCursor(len(m.TitleInput.Value()))
 			return m, textinput.Blink
 		}
 
-	case "E":
+	case key.Matches(msg, m.Keys.EditNotes):
 		// Edit task notes
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
+		if t := m.actionableTask(); t != nil {
 			m.State = StateEditingNotes
-			m.EditingTaskID = m.Tasks[m.Cursor].ID
+			m.EditingTaskID = t.ID
 			notes := ""
-			if m.Tasks[m.Cursor].Notes != nil {
-				notes = *m.Tasks[m.Cursor].Notes
+			if t.Notes != nil {
+				notes = *t.Notes
 			}
-			m.NotesInput.SetValue(notes)
-			m.NotesInput.Focus()
-			m.NotesInput.SetCursor(len(m.NotesInput.Value()))
-			return m, textinput.Blink
+			return m, m.setNotesInput(notes)
+		}
+
+	case key.Matches(msg, m.Keys.EditNotesExternal):
+		// Edit task notes in $EDITOR
+		if t := m.actionableTask(); t != nil {
+			return m, m.editNotes(t)
+		}
+
+	case key.Matches(msg, m.Keys.EditDocument):
+		// Edit the whole task in $EDITOR
+		if t := m.actionableTask(); t != nil {
+			return m, m.editTaskDoc(t)
 		}
 
-	case "v":
-		// Expand/collapse task
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
+	case key.Matches(msg, m.Keys.Expand):
+		// Expand/collapse task, or the collapsed section the cursor is on
+		if m.onCollapsedHeader() {
+			m.toggleSection()
+		} else if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
 			m.Tasks[m.Cursor].Expanded = !m.Tasks[m.Cursor].Expanded
+			// The task's notes and subtasks may push it onto another page
+			m.EnsureCursorVisible()
 		}
 
-	case "enter":
-		// Open task detail view
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-			m.SelectedTaskIdx = m.Cursor
+	case key.Matches(msg, m.Keys.Open):
+		// Open task detail view, or the collapsed section the cursor is on
+		if m.onCollapsedHeader() {
+			m.toggleSection()
+		} else if t := m.actionableTask(); t != nil {
+			m.SelectedTaskID = t.ID
 			m.State = StateViewTask
 		}
 
-	case " ":
+	case key.Matches(msg, m.Keys.ToggleDone):
 		// Toggle task done/open
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-			t := &m.Tasks[m.Cursor]
+		if t := m.actionableTask(); t != nil {
 			newStatus := "done"
 			if t.Status == "done" {
 				newStatus = "open"
 			}
+			m.beginEdit(t)
+			t.Status = newStatus
 
 			// Start animation
-			if newStatus == "done" {
+			if a := m.nextAnim(); a != nil && newStatus == "done" {
 				t.IsAnimatingCheck = true
 				t.AnimStart = time.Now()
-				t.AnimType = RandomAnimType(m.LastAnim)
-				m.LastAnim = t.AnimType
-				cmds = append(cmds, TickCmd())
+				t.Anim = a
+				cmds = append(cmds, TickCmd(), animFinishedCmd(t.ID, t.AnimStart, a.Duration()))
 			}
 
 			// Update via API
-			cmds = append(cmds, m.updateTaskStatus(t.ID, newStatus))
+			cmds = append(cmds, m.setTaskStatus(t.ID, newStatus))
+		}
+
+	case key.Matches(msg, m.Keys.Pin):
+		if t := m.actionableTask(); t != nil {
+			cmds = append(cmds, m.togglePin(t))
 		}
 
-	case "d":
+	case key.Matches(msg, m.Keys.Defer):
+		if t := m.actionableTask(); t != nil {
+			cmds = append(cmds, m.deferTask(t))
+		}
+
+	case key.Matches(msg, m.Keys.Timer), key.Matches(msg, m.Keys.Pomodoro):
+		if t := m.actionableTask(); t != nil {
+			cmds = append(cmds, m.toggleTimer(t, key.Matches(msg, m.Keys.Pomodoro)))
+		}
+
+	case key.Matches(msg, m.Keys.Reminders):
+		if t := m.actionableTask(); t != nil {
+			cmds = append(cmds, m.toggleReminders(t))
+		}
+
+	case key.Matches(msg, m.Keys.Link):
+		if t := m.actionableTask(); t != nil {
+			cmds = append(cmds, m.startLink(t))
+		}
+
+	case key.Matches(msg, m.Keys.Delete):
 		// Delete task (with confirmation)
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-			m.SelectedTaskIdx = m.Cursor
+		if t := m.actionableTask(); t != nil && !t.IsDeleting {
+			m.SelectedTaskID = t.ID
 			m.State = StateConfirmDelete
 		}
 
-	case "c":
+	case key.Matches(msg, m.Keys.Category):
 		// Open category picker
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-			m.SelectedTaskIdx = m.Cursor
+		if t := m.actionableTask(); t != nil {
+			m.SelectedTaskID = t.ID
 			m.CategoryCursor = -1 // Start at "None"
 			// If task has a category, select it
-			if m.Tasks[m.Cursor].CategoryID != nil {
+			if t.CategoryID != nil {
 				for i, cat := range m.Categories {
-					if cat.ID == *m.Tasks[m.Cursor].CategoryID {
+					if cat.ID == *t.CategoryID {
 						m.CategoryCursor = i
 						break
 					}
@@ -466,28 +671,48 @@ func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.State = StateCategorySelect
 		}
 
-	case "C":
+	case key.Matches(msg, m.Keys.NewCategory):
 		// Create new category
 		m.State = StateCategoryCreate
 		m.CategoryInput.SetValue("")
 		m.CategoryInput.Focus()
 		return m, textinput.Blink
 
-	case "b":
-		// Trigger AI breakdown
-		if len(m.Tasks) > 0 && m.Cursor >= 0 && m.Cursor < len(m.Tasks) {
-			cmds = append(cmds, m.breakdownTask(m.Tasks[m.Cursor].ID))
+	case key.Matches(msg, m.Keys.Breakdown):
+		// AI breakdown, reviewed before it is kept
+		if t := m.actionableTask(); t != nil {
+			return m, m.startBreakdown(t)
 		}
 
-	case "?":
+	case key.Matches(msg, m.Keys.UndoBreakdown):
+		// Undo the last accepted breakdown
+		return m, m.undoBreakdown()
+
+	case key.Matches(msg, m.Keys.Repeat):
+		// Set how the task repeats
+		if t := m.actionableTask(); t != nil {
+			return m, m.editRecurrence(t)
+		}
+
+	case key.Matches(msg, m.Keys.NewFromTemplate):
+		// New task from a template
+		m.openTemplates()
+
+	case key.Matches(msg, m.Keys.Export):
+		// Export tasks
+		m.State = StateExport
+
+	case key.Matches(msg, m.Keys.Help):
 		// Show help
 		m.PreviousState = m.State
 		m.State = StateHelp
 
-	case "L":
+	case key.Matches(msg, m.Keys.Logout):
 		// Logout
 		m.Client.Logout()
 		m.State = StateLogin
+		m.refreshSeq++
+		m.stopEvents()
 		m.Tasks = nil
 		m.Categories = nil
 		m.User = nil
@@ -496,7 +721,7 @@ func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.EmailInput.Focus()
 		return m, textinput.Blink
 
-	case "r", "R":
+	case key.Matches(msg, m.Keys.Refresh):
 		// Refresh tasks
 		m.Loading = true
 		cmds = append(cmds, m.loadTasks(), m.loadCategories())
@@ -507,34 +732,37 @@ func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateCategorySelect handles input in category selection
 func (m Model) updateCategorySelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+	switch {
+	case key.Matches(msg, m.Keys.Back):
 		m.State = StateBrowse
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, m.Keys.Up):
 		if m.CategoryCursor > -1 {
 			m.CategoryCursor--
 		}
 
-	case "down", "j":
+	case key.Matches(msg, m.Keys.Down):
 		if m.CategoryCursor < len(m.Categories)-1 {
 			m.CategoryCursor++
 		}
 
-	case "enter":
+	case key.Matches(msg, m.Keys.Select):
 		// Apply selected category
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
+		if t := m.SelectedTask(); t != nil {
 			var categoryID *int
 			if m.CategoryCursor >= 0 && m.CategoryCursor < len(m.Categories) {
 				id := m.Categories[m.CategoryCursor].ID
 				categoryID = &id
 			}
+			m.beginEdit(t)
+			t.CategoryID = categoryID
+			t.Category = m.categoryByID(categoryID)
 			m.State = StateBrowse
-			return m, m.updateTaskCategory(m.Tasks[m.SelectedTaskIdx].ID, categoryID)
+			return m, m.updateTaskCategory(t.ID, categoryID)
 		}
 
-	case "C":
+	case key.Matches(msg, m.Keys.NewCategory):
 		// Create new category
 		m.State = StateCategoryCreate
 		m.CategoryInput.SetValue("")
@@ -574,40 +802,41 @@ func (m Model) updateCategoryCreate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateTaskDetail handles input in task detail view
 func (m Model) updateTaskDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "q":
+	switch {
+	case key.Matches(msg, m.Keys.Back):
 		m.State = StateBrowse
 		return m, nil
 
-	case "e":
+	case key.Matches(msg, m.Keys.Edit):
 		// Edit title
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
+		if t := m.SelectedTask(); t != nil {
 			m.State = StateEditing
-			m.Cursor = m.SelectedTaskIdx
-			m.EditingTaskID = m.Tasks[m.SelectedTaskIdx].ID
-			m.TitleInput.SetValue(m.Tasks[m.SelectedTaskIdx].Title)
+			m.SelectTask(t.ID)
+			m.EditingTaskID = t.ID
+			m.TitleInput.SetValue(t.Title)
 			m.TitleInput.Focus()
 			return m, textinput.Blink
 		}
 
-	case " ":
+	case key.Matches(msg, m.Keys.ToggleDone):
 		// Toggle done
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
-			t := &m.Tasks[m.SelectedTaskIdx]
+		if t := m.SelectedTask(); t != nil {
 			newStatus := "done"
 			if t.Status == "done" {
 				newStatus = "open"
 			}
-			return m, m.updateTaskStatus(t.ID, newStatus)
+			m.beginEdit(t)
+			t.Status = newStatus
+			return m, m.setTaskStatus(t.ID, newStatus)
 		}
 
-	case "c":
+	case key.Matches(msg, m.Keys.Category):
 		// Change category
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
+		if t := m.SelectedTask(); t != nil {
 			m.CategoryCursor = -1
-			if m.Tasks[m.SelectedTaskIdx].CategoryID != nil {
+			if t.CategoryID != nil {
 				for i, cat := range m.Categories {
-					if cat.ID == *m.Tasks[m.SelectedTaskIdx].CategoryID {
+					if cat.ID == *t.CategoryID {
 						m.CategoryCursor = i
 						break
 					}
@@ -616,10 +845,43 @@ func (m Model) updateTaskDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.State = StateCategorySelect
 		}
 
-	case "b":
+	case key.Matches(msg, m.Keys.Breakdown):
 		// Breakdown
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
-			return m, m.breakdownTask(m.Tasks[m.SelectedTaskIdx].ID)
+		if t := m.SelectedTask(); t != nil {
+			return m, m.startBreakdown(t)
+		}
+
+	case key.Matches(msg, m.Keys.Timer), key.Matches(msg, m.Keys.Pomodoro):
+		if t := m.SelectedTask(); t != nil && t.ID > 0 {
+			return m, m.toggleTimer(t, key.Matches(msg, m.Keys.Pomodoro))
+		}
+
+	case key.Matches(msg, m.Keys.Reminders):
+		if t := m.SelectedTask(); t != nil && t.ID > 0 {
+			return m, m.toggleReminders(t)
+		}
+
+	case key.Matches(msg, m.Keys.Link):
+		if t := m.SelectedTask(); t != nil && t.ID > 0 {
+			return m, m.startLink(t)
+		}
+
+	case key.Matches(msg, m.Keys.Repeat):
+		// Repeat
+		if t := m.SelectedTask(); t != nil {
+			return m, m.editRecurrence(t)
+		}
+
+	case key.Matches(msg, m.Keys.EditNotesExternal):
+		// Edit notes in $EDITOR
+		if t := m.SelectedTask(); t != nil && t.ID > 0 {
+			return m, m.editNotes(t)
+		}
+
+	case key.Matches(msg, m.Keys.EditDocument):
+		// Edit the whole task in $EDITOR
+		if t := m.SelectedTask(); t != nil && t.ID > 0 {
+			return m, m.editTaskDoc(t)
 		}
 	}
 
@@ -630,13 +892,20 @@ func (m Model) updateTaskDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y":
-		// Confirm delete - start animation
-		if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
-			m.Tasks[m.SelectedTaskIdx].IsDeleting = true
-			m.Tasks[m.SelectedTaskIdx].AnimStart = time.Now()
-			m.State = StateBrowse
-			return m, TickCmd()
+		// Confirm delete - start animation, or delete straight away when
+		// motion is reduced
+		m.State = StateBrowse
+		t := m.SelectedTask()
+		if t == nil {
+			break
+		}
+		if m.reducedMotion() {
+			m.removeOptimistically(t.ID)
+			return m, m.deleteTask(t.ID)
 		}
+		t.IsDeleting = true
+		t.AnimStart = time.Now()
+		return m, tea.Batch(TickCmd(), animFinishedCmd(t.ID, t.AnimStart, DeleteAnimDuration))
 
 	case "n", "N", "esc":
 		m.State = StateBrowse
@@ -647,8 +916,8 @@ func (m Model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateHelp handles input in help view
 func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "?", "esc", "q":
+	switch {
+	case key.Matches(msg, m.Keys.Help, m.Keys.Back):
 		m.State = m.PreviousState
 		if m.State == StateHelp {
 			m.State = StateBrowse
@@ -673,14 +942,17 @@ func (m Model) register(email, password string) tea.Cmd {
 	}
 }
 
-func (m Model) createTask(title, notes string) tea.Cmd {
+func (m Model) createTask(tempID int, title, notes string, breakdown bool) tea.Cmd {
 	return func() tea.Msg {
 		req := api.TaskCreateRequest{Title: title}
 		if notes != "" {
 			req.Notes = &notes
 		}
+		if breakdown {
+			req.GenerateSubtasks = &breakdown
+		}
 		task, err := m.Client.CreateTask(req)
-		return TaskCreatedMsg{Task: task, Err: err}
+		return TaskCreatedMsg{TempID: tempID, Task: task, Err: err, Breakdown: breakdown}
 	}
 }
 
@@ -688,7 +960,7 @@ func (m Model) updateTaskTitle(id int, title string) tea.Cmd {
 	return func() tea.Msg {
 		req := api.TaskUpdateRequest{Title: &title}
 		task, err := m.Client.UpdateTask(id, req)
-		return TaskUpdatedMsg{Task: task, Err: err}
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
 	}
 }
 
@@ -696,7 +968,7 @@ func (m Model) updateTaskNotes(id int, notes string) tea.Cmd {
 	return func() tea.Msg {
 		req := api.TaskUpdateRequest{Notes: &notes}
 		task, err := m.Client.UpdateTask(id, req)
-		return TaskUpdatedMsg{Task: task, Err: err}
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
 	}
 }
 
@@ -704,7 +976,7 @@ func (m Model) updateTaskStatus(id int, status string) tea.Cmd {
 	return func() tea.Msg {
 		req := api.TaskUpdateRequest{Status: &status}
 		task, err := m.Client.UpdateTask(id, req)
-		return TaskUpdatedMsg{Task: task, Err: err}
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
 	}
 }
 
@@ -712,26 +984,14 @@ func (m Model) updateTaskCategory(id int, categoryID *int) tea.Cmd {
 	return func() tea.Msg {
 		req := api.TaskUpdateRequest{CategoryID: categoryID}
 		task, err := m.Client.UpdateTask(id, req)
-		return TaskUpdatedMsg{Task: task, Err: err}
+		return TaskUpdatedMsg{ID: id, Task: task, Err: err}
 	}
 }
 
 func (m Model) deleteTask(id int) tea.Cmd {
 	return func() tea.Msg {
 		err := m.Client.DeleteTask(id)
-		return TaskDeletedMsg{Err: err}
-	}
-}
-
-func (m Model) breakdownTask(id int) tea.Cmd {
-	return func() tea.Msg {
-		err := m.Client.BreakdownTask(id)
-		if err != nil {
-			return TaskUpdatedMsg{Err: err}
-		}
-		// Refetch the task to get new subtasks
-		task, err := m.Client.GetTask(id)
-		return TaskUpdatedMsg{Task: task, Err: err}
+		return TaskDeletedMsg{ID: id, Err: err}
 	}
 }
 
@@ -751,15 +1011,32 @@ func (m Model) createCategory(name, color string) tea.Cmd {
 	}
 }
 
-// ApplySort sorts the tasks based on current sort mode
+// ApplySort sorts the tasks based on current sort mode, keeping the cursor
+// on the task it was on
 func (m *Model) ApplySort() {
+	cursorID := m.cursorTaskID()
+	m.sortTasks()
+	m.SelectTask(cursorID)
+}
+
+// sortTasks orders m.Tasks by the current sort mode and rebuilds the index
+func (m *Model) sortTasks() {
+	defer m.reindex()
+	defer m.groupTasks()
+
+	if m.ViewMode == ViewToday {
+		// Today keeps its plan order whatever the sort mode
+		sort.SliceStable(m.Tasks, func(i, j int) bool { return today.Less(m.Tasks[i].Task, m.Tasks[j].Task) })
+		return
+	}
+
 	switch m.SortMode {
 	case SortPriority:
-		sort.Slice(m.Tasks, func(i, j int) bool {
+		sort.SliceStable(m.Tasks, func(i, j int) bool {
 			return m.Tasks[i].Priority > m.Tasks[j].Priority
 		})
 	case SortDueDate:
-		sort.Slice(m.Tasks, func(i, j int) bool {
+		sort.SliceStable(m.Tasks, func(i, j int) bool {
 			if m.Tasks[i].DueAt == nil && m.Tasks[j].DueAt == nil {
 				return false
 			}
@@ -772,12 +1049,12 @@ func (m *Model) ApplySort() {
 			return m.Tasks[i].DueAt.Before(*m.Tasks[j].DueAt)
 		})
 	case SortAlphabetical:
-		sort.Slice(m.Tasks, func(i, j int) bool {
+		sort.SliceStable(m.Tasks, func(i, j int) bool {
 			return m.Tasks[i].Title < m.Tasks[j].Title
 		})
 	case SortCreated:
 		// Newest first (descending by ID)
-		sort.Slice(m.Tasks, func(i, j int) bool {
+		sort.SliceStable(m.Tasks, func(i, j int) bool {
 			return m.Tasks[i].ID > m.Tasks[j].ID
 		})
 	}
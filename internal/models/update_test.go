@@ -0,0 +1,547 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+)
+
+func TestInitialLoadSortsNewestFirst(t *testing.T) {
+	h := newHarness(t)
+
+	if h.m.State != StateBrowse {
+		t.Fatalf("state = %v, want StateBrowse", h.m.State)
+	}
+	want := []string{"Answer email", "Buy milk", "Fix tap", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+	if len(h.m.Categories) != 2 {
+		t.Errorf("categories = %d, want 2", len(h.m.Categories))
+	}
+}
+
+func TestCursorMovementStaysInBounds(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("k")
+	if h.m.Cursor != 0 {
+		t.Errorf("cursor after k at top = %d, want 0", h.m.Cursor)
+	}
+	h.press("j", "j", "down", "j", "j")
+	if h.m.Cursor != len(h.m.Tasks)-1 {
+		t.Errorf("cursor = %d, want %d", h.m.Cursor, len(h.m.Tasks)-1)
+	}
+	h.press("up")
+	if h.m.Cursor != len(h.m.Tasks)-2 {
+		t.Errorf("cursor after up = %d, want %d", h.m.Cursor, len(h.m.Tasks)-2)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	h := newHarness(t)
+	h.m.PageSize = 3
+
+	if view := h.m.View(); !strings.Contains(view, "Page 1/2 | Right: next") {
+		t.Errorf("no page hint in:\n%s", view)
+	}
+	h.press("l")
+	if h.m.Page != 1 || h.m.Cursor != 3 {
+		t.Errorf("after next page: page=%d cursor=%d, want 1 and 3", h.m.Page, h.m.Cursor)
+	}
+	if view := h.m.View(); !strings.Contains(view, "Page 2/2 | Left: prev") {
+		t.Errorf("no page hint in:\n%s", view)
+	}
+	h.press("l")
+	if h.m.Page != 1 {
+		t.Errorf("paged past the end: page=%d", h.m.Page)
+	}
+	h.press("h")
+	if h.m.Page != 0 || h.m.Cursor != 0 {
+		t.Errorf("after previous page: page=%d cursor=%d, want 0 and 0", h.m.Page, h.m.Cursor)
+	}
+}
+
+func TestCreateTaskWithNotes(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("n")
+	if h.m.State != StateCreating {
+		t.Fatalf("state = %v, want StateCreating", h.m.State)
+	}
+	h.typeText("Walk the dog")
+	h.press("enter")
+	if h.m.State != StateCreatingNotes {
+		t.Fatalf("state = %v, want StateCreatingNotes", h.m.State)
+	}
+	h.typeText("Before dinner")
+	h.press("enter")
+
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v, want StateBrowse", h.m.State)
+	}
+	st := h.serverTask("Walk the dog")
+	if st == nil {
+		t.Fatal("task was not created on the server")
+	}
+	if st.Notes == nil || *st.Notes != "Before dinner" {
+		t.Errorf("notes = %v, want %q", st.Notes, "Before dinner")
+	}
+	if h.m.Tasks[0].Title != "Walk the dog" {
+		t.Errorf("new task not listed first: %v", h.titles())
+	}
+}
+
+func TestCreateTaskCancelled(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("n")
+	h.typeText("Never mind")
+	h.press("esc")
+
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v, want StateBrowse", h.m.State)
+	}
+	if h.serverTask("Never mind") != nil {
+		t.Error("cancelled task was created")
+	}
+}
+
+func TestEditTitle(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	h.press("e")
+	h.typeText(" and bread")
+	h.press("enter")
+
+	if h.serverTask("Buy milk and bread") == nil {
+		t.Errorf("server titles do not include the edit")
+	}
+	if task := h.m.CurrentTask(); task == nil || task.Title != "Buy milk and bread" {
+		t.Errorf("current task = %+v", task)
+	}
+}
+
+func TestToggleDone(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+
+	h.press("space")
+
+	if st := h.serverTask("Fix tap"); st == nil || st.Status != "done" {
+		t.Fatalf("server task = %+v, want done", st)
+	}
+	for _, task := range h.m.Tasks {
+		if task.Title == "Fix tap" && task.Status != "done" {
+			t.Errorf("local status = %q, want done", task.Status)
+		}
+		if task.IsAnimatingCheck {
+			t.Errorf("animation still running for %q", task.Title)
+		}
+	}
+}
+
+func TestDeleteConfirmed(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	h.press("d")
+	if h.m.State != StateConfirmDelete {
+		t.Fatalf("state = %v, want StateConfirmDelete", h.m.State)
+	}
+	h.press("y")
+
+	if h.serverTask("Buy milk") != nil {
+		t.Error("task still exists on the server")
+	}
+	want := []string{"Answer email", "Fix tap", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestDeleteCancelled(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("d", "n")
+
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v, want StateBrowse", h.m.State)
+	}
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("tasks = %d, want 4", len(h.m.Tasks))
+	}
+}
+
+func TestChangeCategory(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	h.press("c")
+	if h.m.State != StateCategorySelect || h.m.CategoryCursor != -1 {
+		t.Fatalf("state=%v cursor=%d, want category select on None", h.m.State, h.m.CategoryCursor)
+	}
+	h.press("j", "j", "enter")
+
+	st := h.serverTask("Buy milk")
+	if st == nil || st.Category == nil || st.Category.Name != "Home" {
+		t.Fatalf("server category = %+v, want Home", st.Category)
+	}
+	if task := h.m.CurrentTask(); task == nil || task.Category == nil || task.Category.Name != "Home" {
+		t.Errorf("local category not updated: %+v", task)
+	}
+}
+
+func TestSortCycle(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("s")
+	if h.m.SortMode != SortPriority {
+		t.Fatalf("sort = %v, want SortPriority", h.m.SortMode)
+	}
+	want := []string{"Write report", "Answer email", "Fix tap", "Buy milk"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+
+	h.press("s", "s")
+	if h.m.SortMode != SortAlphabetical {
+		t.Fatalf("sort = %v, want SortAlphabetical", h.m.SortMode)
+	}
+	want = []string{"Answer email", "Buy milk", "Fix tap", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestTabSwitchesViews(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("tab")
+	if h.m.ViewMode != ViewCompleted {
+		t.Fatalf("view = %v, want ViewCompleted", h.m.ViewMode)
+	}
+	if got := h.titles(); !reflect.DeepEqual(got, []string{"Old chore"}) {
+		t.Errorf("completed titles = %v", got)
+	}
+}
+
+func TestBreakdownAddsSubtasks(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	// Generated subtasks are kept once the review is accepted
+	h.press("b", "enter")
+
+	task := h.m.CurrentTask()
+	if task == nil || len(task.Subtasks) != 3 {
+		t.Fatalf("subtasks = %+v, want 3", task)
+	}
+}
+
+func TestServerErrorIsShown(t *testing.T) {
+	h := newHarness(t)
+	h.srv.InjectFault(fakeserver.Fault{Method: "GET", PathPrefix: "/tasks", Status: 500, Count: 1})
+
+	h.press("r")
+
+	if h.m.ErrorMsg == "" {
+		t.Error("expected an error message")
+	}
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("tasks were discarded on error: %d", len(h.m.Tasks))
+	}
+}
+
+func TestUnauthorizedReturnsToLogin(t *testing.T) {
+	h := newHarness(t)
+	h.srv.ExpireTokens()
+
+	h.press("r")
+
+	if h.m.State != StateLogin {
+		t.Errorf("state = %v, want StateLogin", h.m.State)
+	}
+}
+
+func TestLoadErrorMessage(t *testing.T) {
+	h := newHarness(t)
+
+	h.send(TasksLoadedMsg{Err: errors.New("boom")})
+
+	if h.m.ErrorMsg != "boom" {
+		t.Errorf("error = %q, want boom", h.m.ErrorMsg)
+	}
+}
+
+func TestLoginFlow(t *testing.T) {
+	h := newHarness(t)
+	h.press("L")
+	if h.m.State != StateLogin {
+		t.Fatalf("state = %v, want StateLogin", h.m.State)
+	}
+
+	h.typeText("demo@example.com")
+	h.press("tab")
+	h.typeText("wrong")
+	h.press("enter")
+	if h.m.State != StateLogin || h.m.ErrorMsg == "" {
+		t.Fatalf("bad password: state=%v error=%q", h.m.State, h.m.ErrorMsg)
+	}
+
+	h.m.PasswordInput.SetValue("demo")
+	h.press("enter")
+	if h.m.State != StateBrowse {
+		t.Fatalf("state = %v, want StateBrowse", h.m.State)
+	}
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("tasks after login = %d, want 4", len(h.m.Tasks))
+	}
+}
+
+func TestDetailViewToggle(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Answer email")
+
+	h.press("enter")
+	if h.m.State != StateViewTask {
+		t.Fatalf("state = %v, want StateViewTask", h.m.State)
+	}
+	h.press("space")
+
+	if st := h.serverTask("Answer email"); st.Status != "done" {
+		t.Errorf("status = %q, want done", st.Status)
+	}
+	h.press("esc")
+	if h.m.State != StateBrowse {
+		t.Errorf("state = %v, want StateBrowse", h.m.State)
+	}
+}
+
+func TestQuit(t *testing.T) {
+	h := newHarness(t)
+	h.press("q")
+	if !h.quit {
+		t.Error("q did not quit")
+	}
+}
+
+func TestDeleteSurvivesResortDuringAnimation(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+
+	// Sorting by priority moves "Buy milk" from index 1 to the end while
+	// the delete animation is still running
+	h.queue("d", "y", "s")
+	h.settle()
+
+	if h.serverTask("Buy milk") != nil {
+		t.Error("Buy milk still exists on the server")
+	}
+	want := []string{"Write report", "Answer email", "Fix tap"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestTwoDeletesDuringAnimation(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Answer email")
+
+	h.queue("d", "y", "j", "d", "y")
+	h.settle()
+
+	for _, title := range []string{"Answer email", "Buy milk"} {
+		if h.serverTask(title) != nil {
+			t.Errorf("%s still exists on the server", title)
+		}
+	}
+	want := []string{"Fix tap", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestRefreshDuringDeleteAnimation(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+
+	h.queue("d", "y", "r")
+	h.settle()
+
+	if h.serverTask("Fix tap") != nil {
+		t.Error("Fix tap still exists on the server")
+	}
+	want := []string{"Answer email", "Buy milk", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestCursorFollowsTaskAcrossSortAndReload(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+	h.press("v")
+
+	h.press("s")
+	if task := h.m.CurrentTask(); task == nil || task.Title != "Fix tap" {
+		t.Fatalf("cursor after sort on %+v, want Fix tap", task)
+	}
+
+	h.press("r")
+	task := h.m.CurrentTask()
+	if task == nil || task.Title != "Fix tap" {
+		t.Fatalf("cursor after reload on %+v, want Fix tap", task)
+	}
+	if !task.Expanded {
+		t.Error("expanded state lost on reload")
+	}
+}
+
+func TestDeleteFailureRestoresRow(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+	h.srv.InjectFault(fakeserver.Fault{Method: "DELETE", Status: 500, Count: 1})
+
+	h.press("d", "y")
+
+	want := []string{"Answer email", "Buy milk", "Fix tap", "Write report"}
+	if got := h.titles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+	for _, task := range h.m.Tasks {
+		if task.IsDeleting {
+			t.Errorf("%s still marked as deleting", task.Title)
+		}
+	}
+	if h.m.ErrorMsg == "" {
+		t.Error("expected an error message")
+	}
+}
+
+func TestToggleAppliesBeforeResponse(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+	h.srv.SetLatency(100 * time.Millisecond)
+
+	h.queue("space")
+
+	task := h.m.CurrentTask()
+	if task.Status != "done" {
+		t.Errorf("status before response = %q, want done", task.Status)
+	}
+	if !h.m.IsPending(task.ID) {
+		t.Error("task not marked pending while the request is in flight")
+	}
+
+	h.settle()
+	if h.m.IsPending(task.ID) {
+		t.Error("task still pending after the response")
+	}
+	if st := h.serverTask("Buy milk"); st.Status != "done" {
+		t.Errorf("server status = %q, want done", st.Status)
+	}
+}
+
+func TestToggleRollsBackOnFailure(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Buy milk")
+	h.srv.InjectFault(fakeserver.Fault{Method: "PATCH", Status: 500, Count: 1})
+
+	h.press("space")
+
+	task := h.m.CurrentTask()
+	if task.Title != "Buy milk" || task.Status != "open" {
+		t.Errorf("task after failed toggle = %q %q, want Buy milk open", task.Title, task.Status)
+	}
+	if h.m.IsPending(task.ID) {
+		t.Error("task still pending after rollback")
+	}
+	if h.m.ErrorMsg == "" {
+		t.Error("expected an error toast")
+	}
+}
+
+func TestCategoryRollsBackOnFailure(t *testing.T) {
+	h := newHarness(t)
+	h.selectTitle("Fix tap")
+	h.srv.InjectFault(fakeserver.Fault{Method: "PATCH", Status: 500, Count: 1})
+
+	h.press("c", "k", "enter")
+
+	task := h.m.CurrentTask()
+	if task.Category == nil || task.Category.Name != "Home" {
+		t.Errorf("category after failed change = %+v, want Home", task.Category)
+	}
+}
+
+func TestCreateShowsPlaceholderThenRealTask(t *testing.T) {
+	h := newHarness(t)
+	h.srv.SetLatency(100 * time.Millisecond)
+
+	h.press("n")
+	h.typeText("Walk the dog")
+	h.press("enter")
+	h.queue("enter")
+
+	task := h.m.CurrentTask()
+	if task == nil || task.Title != "Walk the dog" || task.ID >= 0 {
+		t.Fatalf("placeholder = %+v, want temporary Walk the dog", task)
+	}
+	h.queue("space")
+	if task := h.m.CurrentTask(); task.Status != "open" {
+		t.Error("placeholder task could be toggled before it was created")
+	}
+
+	h.settle()
+	task = h.m.CurrentTask()
+	if task == nil || task.Title != "Walk the dog" || task.ID <= 0 {
+		t.Fatalf("after create cursor on %+v, want the real task", task)
+	}
+	if len(h.m.Tasks) != 5 {
+		t.Errorf("tasks = %d, want 5", len(h.m.Tasks))
+	}
+}
+
+func TestCreateFailureRemovesPlaceholder(t *testing.T) {
+	h := newHarness(t)
+	h.srv.InjectFault(fakeserver.Fault{Method: "POST", PathPrefix: "/tasks", Status: 500, Count: 1})
+
+	h.press("n")
+	h.typeText("Walk the dog")
+	h.press("enter", "enter")
+
+	if len(h.m.Tasks) != 4 {
+		t.Errorf("tasks = %v, want the original four", h.titles())
+	}
+	if h.m.ErrorMsg == "" {
+		t.Error("expected an error toast")
+	}
+}
+
+func TestExportCurrentView(t *testing.T) {
+	h := newHarness(t)
+	dir := t.TempDir()
+	h.m.Config.ExportDir = dir
+
+	h.press("x", "j", "j", "enter")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+	if len(files) != 1 {
+		t.Fatalf("export files = %v, want one markdown file (status %q)", files, h.m.ErrorMsg)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "- [ ] Buy milk (P2)") || strings.Contains(string(data), "Old chore") {
+		t.Errorf("export should hold the open view only:\n%s", data)
+	}
+	if !strings.Contains(h.m.SuccessMsg, "Exported 4 tasks") {
+		t.Errorf("status = %q", h.m.SuccessMsg)
+	}
+}
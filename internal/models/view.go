@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/blackraven/todo-tui/internal/keymap"
 	"github.com/blackraven/todo-tui/internal/styles"
 	"github.com/blackraven/todo-tui/internal/themes"
 )
@@ -27,6 +28,22 @@ func (m Model) View() string {
 		return m.viewTaskDetail(currentTheme)
 	case StateConfirmDelete:
 		return m.viewConfirmDelete(currentTheme)
+	case StateExport:
+		return m.viewExport(currentTheme)
+	case StateRecurrence:
+		return m.viewRecurrence(currentTheme)
+	case StateTemplates, StateTemplatePrompt:
+		return m.viewTemplates(currentTheme)
+	case StateBreakdown:
+		return m.viewBreakdown(currentTheme)
+	case StateThemePicker:
+		return m.viewThemePicker(currentTheme)
+	case StateAnimations:
+		return m.viewAnimations(currentTheme)
+	case StateStats:
+		return m.viewStats(currentTheme)
+	case StatePlan:
+		return m.viewPlan(currentTheme)
 	default:
 		return m.viewMain(currentTheme)
 	}
@@ -87,7 +104,7 @@ func (m Model) viewAuth(t themes.Theme) string {
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Padding(2).
@@ -117,7 +134,7 @@ func (m Model) viewMain(t themes.Theme) string {
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Render(content)
@@ -126,25 +143,54 @@ func (m Model) viewMain(t themes.Theme) string {
 	sortStr := m.sortModeString()
 
 	// Build help text
-	fullHelp := fmt.Sprintf("Theme: %s (t) | Sort: %s (s) | New (n) | Edit (e) | Done (Space) | Del (d) | Category (c) | Help (?)",
-		t.Name, sortStr)
-	shortHelp := fmt.Sprintf("%s (t) | %s (s) | n/e/Space/d/c | ? Help", t.Name, sortStr)
+	k := m.Keys
+	fullHelp := fmt.Sprintf("Theme: %s (%s) | Sort: %s (%s) | New (%s) | Edit (%s) | Done (%s) | Del (%s) | Category (%s) | Help (%s)",
+		t.Name, keymap.Key(k.Theme), sortStr, keymap.Key(k.Sort), keymap.Key(k.New), keymap.Key(k.Edit),
+		keymap.Key(k.ToggleDone), keymap.Key(k.Delete), keymap.Key(k.Category), keymap.Key(k.Help))
+	shortHelp := fmt.Sprintf("%s (%s) | %s (%s) | %s/%s/%s/%s/%s | %s Help", t.Name, keymap.Key(k.Theme),
+		sortStr, keymap.Key(k.Sort), keymap.Key(k.New), keymap.Key(k.Edit), keymap.Key(k.ToggleDone),
+		keymap.Key(k.Delete), keymap.Key(k.Category), keymap.Key(k.Help))
 
 	help := fullHelp
-	if m.Width < 100 {
+	if m.GroupBy != GroupNone {
+		// The grouping goes first, and leaves less room for the rest
+		fullHelp = fmt.Sprintf("Group: %s (%s) | %s", m.groupByString(), keymap.Key(k.Group), fullHelp)
+		shortHelp = fmt.Sprintf("%s (%s) | %s", m.groupByString(), keymap.Key(k.Group), shortHelp)
+		help = fullHelp
+		if m.Width < 130 {
+			help = shortHelp
+		}
+	} else if m.Width < 100 {
 		help = shortHelp
 	}
+	if m.linkFrom != 0 {
+		help = m.linkHelp()
+	}
 
-	status := lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).
-		Render(styles.HelpStyle.Render(help))
+	status := m.renderStatusBar(help)
 
 	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
 	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
 }
 
+// renderStatusBar renders the bottom line, showing the current toast in
+// place of the help text while there is one
+func (m Model) renderStatusBar(help string) string {
+	line := styles.HelpStyle.Render(help)
+	if m.ErrorMsg != "" {
+		line = styles.ErrorStyle.Render(m.ErrorMsg)
+	} else if m.SuccessMsg != "" {
+		line = styles.SuccessStyle.Render(m.SuccessMsg)
+	}
+	if clock := m.timerStatus(); clock != "" {
+		line = clock + styles.HelpStyle.Render("  |  ") + line
+	}
+	return lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).Render(line)
+}
+
 // renderTabs renders the view mode tabs
 func (m Model) renderTabs(t themes.Theme) string {
-	tabs := []string{"Open", "Completed", "Shared"}
+	tabs := []string{"Open", "Completed", "Shared", "Today"}
 	var rendered []string
 
 	for i, tab := range tabs {
@@ -156,6 +202,9 @@ func (m Model) renderTabs(t themes.Theme) string {
 		}
 		rendered = append(rendered, style.Render(tab))
 	}
+	if m.Live {
+		rendered = append(rendered, lipgloss.NewStyle().Foreground(t.Success).Render(" ● live"))
+	}
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
 }
@@ -168,22 +217,29 @@ func (m Model) viewList(t themes.Theme) string {
 			emptyMsg = "No completed tasks."
 		} else if m.ViewMode == ViewShared {
 			emptyMsg = "No shared tasks."
+		} else if m.ViewMode == ViewToday {
+			emptyMsg = fmt.Sprintf("Nothing due or planned today. Press '%s' on a task to pin it here.", keymap.Key(m.Keys.Pin))
 		}
 		return styles.HelpStyle.Padding(2).Render(emptyMsg)
 	}
 
 	var s strings.Builder
+	if m.ViewMode == ViewToday {
+		s.WriteString(" " + m.todaySummary(t) + "\n")
+	}
 
-	// Get paginated tasks
-	pageTasks := m.PageTasks()
-	pageStart := m.PageStart()
+	// Get the rows on this page, and the collapsed section the cursor is
+	// on, if any
+	pageRows := m.pageRows()
+	var onSection *taskGroup
+	if rows := m.listRows(); len(rows) > 0 {
+		if r := rows[m.cursorRow(rows)]; r.header {
+			onSection = &r.group
+		}
+	}
 
 	// Layout calculations
-	availableWidth := m.Width - 4
-	textWidth := availableWidth - 30 // Room for number, checkbox, category, priority
-	if textWidth < 10 {
-		textWidth = 10
-	}
+	textWidth := m.listTextWidth()
 
 	// Determine if we're creating a new task on this page
 	creatingOnThisPage := false
@@ -222,17 +278,26 @@ func (m Model) viewList(t themes.Theme) string {
 			)
 			newTaskRow = lipgloss.JoinVertical(lipgloss.Left, titleRow, notesRow)
 		}
+		if m.CreateBreakdown {
+			newTaskRow += lipgloss.NewStyle().Foreground(t.Accent).Render("  + AI breakdown")
+		}
 
 		s.WriteString(styles.ListSelectedStyle.Render(newTaskRow))
 		s.WriteString("\n")
 	}
 
-	// Render tasks on this page
-	for pageIdx, task := range pageTasks {
-		globalIdx := pageStart + pageIdx
-		selected := m.Cursor == globalIdx
+	// Render the section headers and tasks on this page
+	for _, r := range pageRows {
+		if r.header {
+			s.WriteString(m.renderHeader(r, r.collapsed && onSection != nil && r.group == *onSection, t))
+			s.WriteString("\n")
+			continue
+		}
+		globalIdx := r.index
+		task := m.Tasks[globalIdx]
+		selected := m.Cursor == globalIdx && onSection == nil
 
-		numberStr := fmt.Sprintf("%d.", globalIdx+1)
+		numberStr := fmt.Sprintf("%d.", r.num)
 		var checkIcon string
 		var titleContent string
 		var categoryBadge string
@@ -273,6 +338,10 @@ func (m Model) viewList(t themes.Theme) string {
 
 			// Expansion indicator
 			displayTitle := rawTitle
+			if m.IsPending(task.ID) {
+				// Request in flight
+				displayTitle += lipgloss.NewStyle().Foreground(t.Dim).Render(" ~")
+			}
 			if (task.Notes != nil && *task.Notes != "") || len(task.Subtasks) > 0 {
 				arrow := " >"
 				if task.Expanded {
@@ -285,35 +354,39 @@ func (m Model) viewList(t themes.Theme) string {
 
 			// Category badge
 			if task.Category != nil {
-				catColor := lipgloss.Color(task.Category.Color)
-				categoryBadge = lipgloss.NewStyle().
-					Foreground(t.Bg).
-					Background(catColor).
-					Padding(0, 1).
-					Render(task.Category.Name)
+				categoryBadge = m.categoryBadge(task.Category, t)
 			}
 
 			// Priority badge
 			if task.Priority > 0 {
-				var prioStyle lipgloss.Style
-				if task.Priority >= 8 {
-					prioStyle = styles.PriorityHighStyle
-				} else if task.Priority >= 5 {
-					prioStyle = styles.PriorityMedStyle
-				} else {
-					prioStyle = styles.PriorityLowStyle
-				}
-				priorityBadge = prioStyle.Render(fmt.Sprintf("P%d", task.Priority))
+				priorityBadge = m.priorityBadge(task.Priority)
 			}
 
 			// Due date badge
 			if task.DueAt != nil {
-				dueStr := formatDue(*task.DueAt)
-				if task.DueAt.Before(time.Now()) && task.Status != "done" {
-					dueBadge = styles.OverdueStyle.Render(dueStr)
-				} else {
-					dueBadge = styles.DueStyle.Render(dueStr)
+				dueBadge = m.dueBadge(task)
+			}
+
+			// Repeat marker
+			if _, ok := m.Recurrence.Get(task.ID); ok {
+				dueBadge = strings.TrimSpace(dueBadge + " " + lipgloss.NewStyle().Foreground(t.Accent).Render("↻"))
+			}
+
+			// Pin marker, and the estimates the Today list adds up
+			if m.Pins.Has(task.ID) {
+				dueBadge = strings.TrimSpace(dueBadge + " " + lipgloss.NewStyle().Foreground(t.Accent).Render("⚑"))
+			}
+			if m.ViewMode == ViewToday && task.EffortMin > 0 {
+				dueBadge = strings.TrimSpace(dueBadge + " " + lipgloss.NewStyle().Foreground(t.Dim).Render(formatEffort(task.EffortMin)))
+			}
+
+			// Blocked marker, while a task it waits on is open
+			if m.isBlocked(task.ID) {
+				blocked := "⊘"
+				if m.TextMarkers {
+					blocked = "⊘ blocked"
 				}
+				dueBadge = strings.TrimSpace(dueBadge + " " + lipgloss.NewStyle().Foreground(t.Warning).Render(blocked))
 			}
 
 			// Subtask progress
@@ -339,6 +412,9 @@ func (m Model) viewList(t themes.Theme) string {
 
 		// Build right block (badges)
 		var badges []string
+		if task.IsHighlighted() {
+			badges = append(badges, lipgloss.NewStyle().Foreground(t.Success).Bold(true).Render(task.Highlight))
+		}
 		if categoryBadge != "" {
 			badges = append(badges, categoryBadge)
 		}
@@ -388,20 +464,11 @@ func (m Model) viewList(t themes.Theme) string {
 			// Notes
 			if task.Notes != nil && *task.Notes != "" {
 				notesIcon := lipgloss.NewStyle().Foreground(t.Dim).Render("+-")
-				notesText := *task.Notes
-				if task.IsDeleting {
-					notesText = RenderDeleteAnim(notesText, t)
-				} else if task.Status == "done" {
-					notesText = styles.StrikeStyle.Italic(true).Render(notesText)
-				} else {
-					notesText = lipgloss.NewStyle().Foreground(t.Fg).Italic(true).Render(notesText)
-				}
-
 				notesRow := lipgloss.JoinHorizontal(lipgloss.Top,
 					lipgloss.NewStyle().Width(7).Render(""),
 					notesIcon,
 					" ",
-					lipgloss.NewStyle().Width(textWidth-3).Render(notesText),
+					m.expandedNotes(task, textWidth-3, t),
 				)
 
 				if selected {
@@ -455,20 +522,40 @@ func (m Model) viewList(t themes.Theme) string {
 	if totalPages > 1 {
 		s.WriteString("\n")
 		pageInfo := fmt.Sprintf("Page %d/%d", m.Page+1, totalPages)
-		navHint := ""
-		if m.Page > 0 && m.Page < totalPages-1 {
-			navHint = " | < prev | next >"
-		} else if m.Page > 0 {
-			navHint = " | < prev"
-		} else if m.Page < totalPages-1 {
-			navHint = " | next >"
+		var prev, next string
+		if m.Page > 0 {
+			prev = keymap.Hint(m.Keys.PrevPage, "prev")
+		}
+		if m.Page < totalPages-1 {
+			next = keymap.Hint(m.Keys.NextPage, "next")
 		}
-		s.WriteString(styles.HelpStyle.Render(pageInfo + navHint))
+		s.WriteString(styles.HelpStyle.Render(keymap.Hints(pageInfo, prev, next)))
 	}
 
 	return s.String()
 }
 
+// listTextWidth is the room for a task's title and notes in the list,
+// leaving space for the number, checkbox and badges
+func (m Model) listTextWidth() int {
+	textWidth := m.Width - 4 - 30
+	if textWidth < 10 {
+		textWidth = 10
+	}
+	return textWidth
+}
+
+// expandedNotes renders an expanded task's notes in the given width
+func (m Model) expandedNotes(task Task, width int, t themes.Theme) string {
+	notesText := *task.Notes
+	if task.IsDeleting {
+		notesText = RenderDeleteAnim(notesText, t)
+	} else {
+		notesText = renderNotes(notesText, width, t, task.Status == "done")
+	}
+	return lipgloss.NewStyle().Width(width).Render(notesText)
+}
+
 // viewCategorySelect renders the category selection overlay
 func (m Model) viewCategorySelect(t themes.Theme) string {
 	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
@@ -502,17 +589,18 @@ func (m Model) viewCategorySelect(t themes.Theme) string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(styles.HelpStyle.Render("  Press 'C' to create new category"))
+	s.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  Press '%s' to create new category", keymap.Key(m.Keys.NewCategory))))
 
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Render(s.String())
 
-	help := "Enter: Select | C: Create New | Esc: Cancel"
+	help := keymap.Hints(keymap.Hint(m.Keys.Select, "Select"), keymap.Hint(m.Keys.NewCategory, "Create New"),
+		keymap.Hint(m.Keys.Back, "Cancel"))
 	status := lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).
 		Render(styles.HelpStyle.Render(help))
 
@@ -543,7 +631,7 @@ func (m Model) viewCategoryCreate(t themes.Theme) string {
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Padding(2).
@@ -559,11 +647,12 @@ func (m Model) viewCategoryCreate(t themes.Theme) string {
 
 // viewTaskDetail renders the task detail view
 func (m Model) viewTaskDetail(t themes.Theme) string {
-	if m.SelectedTaskIdx < 0 || m.SelectedTaskIdx >= len(m.Tasks) {
+	selected := m.SelectedTask()
+	if selected == nil {
 		return m.viewMain(t)
 	}
 
-	task := m.Tasks[m.SelectedTaskIdx]
+	task := *selected
 
 	header := lipgloss.Place(m.Width, 1, lipgloss.Center, lipgloss.Top,
 		styles.HeaderStyle.Render("// TASK DETAILS"))
@@ -587,23 +676,49 @@ func (m Model) viewTaskDetail(t themes.Theme) string {
 
 	// Category
 	if task.Category != nil {
-		catColor := lipgloss.Color(task.Category.Color)
-		catBadge := lipgloss.NewStyle().Background(catColor).Foreground(t.Bg).Padding(0, 1).Render(task.Category.Name)
-		s.WriteString(fmt.Sprintf("Category: %s\n", catBadge))
+		s.WriteString(fmt.Sprintf("Category: %s\n", m.categoryBadge(task.Category, t)))
 	}
 
 	// Due date
 	if task.DueAt != nil {
-		s.WriteString(fmt.Sprintf("Due: %s\n", task.DueAt.Format("Jan 2, 2006 3:04 PM")))
+		due := task.DueAt.Format("Jan 2, 2006 3:04 PM")
+		if m.TextMarkers && isOverdue(task) {
+			due += " (overdue)"
+		}
+		s.WriteString(fmt.Sprintf("Due: %s\n", due))
+	}
+
+	// Time block
+	if task.ScheduledStart != nil && task.ScheduledEnd != nil {
+		block := task.ScheduledStart.Local().Format("Jan 2, 3:04") + "-" + task.ScheduledEnd.Local().Format("3:04 PM")
+		if task.AutoScheduled {
+			block += " (planned)"
+		}
+		s.WriteString(fmt.Sprintf("Scheduled: %s\n", block))
+	}
+
+	// Recurrence
+	if r, ok := m.Recurrence.Get(task.ID); ok {
+		s.WriteString(fmt.Sprintf("Repeats: %s\n", r))
 	}
 
+	if task.NotificationsEnabled {
+		s.WriteString("Reminders: on\n")
+	}
+
+	// Estimated and logged time
+	s.WriteString(m.viewEffort(task, t))
+
+	// Dependencies
+	s.WriteString(m.viewDeps(task, t))
+
 	s.WriteString("\n")
 
 	// Notes
 	if task.Notes != nil && *task.Notes != "" {
 		notesLabel := styles.InputLabelStyle.Render("Notes:")
 		s.WriteString(notesLabel + "\n")
-		s.WriteString(lipgloss.NewStyle().Foreground(t.Fg).Italic(true).Render(*task.Notes))
+		s.WriteString(renderNotes(*task.Notes, m.Width-6, t, false))
 		s.WriteString("\n\n")
 	}
 
@@ -629,15 +744,17 @@ func (m Model) viewTaskDetail(t themes.Theme) string {
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Padding(1).
 		Render(s.String())
 
-	help := "e: Edit | Space: Toggle Done | b: Breakdown | c: Category | Esc: Back"
-	status := lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).
-		Render(styles.HelpStyle.Render(help))
+	k := m.Keys
+	help := keymap.Hints(keymap.Hint(k.Edit, "Edit"), keymap.Hint(k.ToggleDone, "Done"),
+		keymap.Hint(k.Breakdown, "Breakdown"), keymap.Hint(k.Category, "Category"),
+		keymap.Hint(k.Repeat, "Repeat"), keymap.Hint(k.Back, "Back"))
+	status := m.renderStatusBar(help)
 
 	ui := lipgloss.JoinVertical(lipgloss.Center, header, container, status)
 	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, ui)
@@ -649,8 +766,8 @@ func (m Model) viewConfirmDelete(t themes.Theme) string {
 		styles.HeaderStyle.Render("// CONFIRM DELETE"))
 
 	var taskTitle string
-	if m.SelectedTaskIdx >= 0 && m.SelectedTaskIdx < len(m.Tasks) {
-		taskTitle = m.Tasks[m.SelectedTaskIdx].Title
+	if task := m.SelectedTask(); task != nil {
+		taskTitle = task.Title
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Center,
@@ -683,44 +800,35 @@ func (m Model) viewHelp(t themes.Theme) string {
 
 	var s strings.Builder
 
-	s.WriteString(styles.InputLabelStyle.Render("Navigation:") + "\n")
-	s.WriteString("  Up/Down, k/j    Move cursor\n")
-	s.WriteString("  Left/Right, h/l Navigate pages\n")
-	s.WriteString("  PgUp/PgDown     Jump pages\n")
-	s.WriteString("  Tab             Cycle views (Open/Completed/Shared)\n")
-	s.WriteString("  Enter           Open task details\n")
-	s.WriteString("  v               Expand/collapse task\n\n")
-
-	s.WriteString(styles.InputLabelStyle.Render("Task Management:") + "\n")
-	s.WriteString("  n               New task\n")
-	s.WriteString("  e               Edit task title\n")
-	s.WriteString("  E               Edit task notes\n")
-	s.WriteString("  Space           Toggle task done/open\n")
-	s.WriteString("  d               Delete task\n")
-	s.WriteString("  c               Change category\n")
-	s.WriteString("  C               Create new category\n")
-	s.WriteString("  b               AI breakdown (create subtasks)\n\n")
-
-	s.WriteString(styles.InputLabelStyle.Render("Display:") + "\n")
-	s.WriteString("  t               Cycle themes\n")
-	s.WriteString("  s               Cycle sort modes\n\n")
-
-	s.WriteString(styles.InputLabelStyle.Render("Other:") + "\n")
-	s.WriteString("  ?               Toggle this help\n")
-	s.WriteString("  L               Logout\n")
-	s.WriteString("  Esc             Cancel/back\n")
-	s.WriteString("  Ctrl+C, q       Quit\n")
+	for i, sec := range keymap.Sections {
+		entries := m.Keys.HelpSection(sec)
+		if len(entries) == 0 {
+			continue
+		}
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(styles.InputLabelStyle.Render(sec+":") + "\n")
+		for _, e := range entries {
+			s.WriteString(fmt.Sprintf("  %-16s%s\n", e.Keys, e.Desc))
+		}
+	}
+
+	s.WriteString("\n" + styles.InputLabelStyle.Render("While typing:") + "\n")
+	s.WriteString("  Ctrl+B          Break down while creating\n")
+	s.WriteString("  Ctrl+J          New line in notes (also Alt+Enter)\n")
+	s.WriteString("  Ctrl+O          Edit notes in $EDITOR\n")
 
 	containerHeight := m.Height - 7
 	container := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Width(m.Width - 4).
 		Height(containerHeight).
 		Padding(1).
 		Render(s.String())
 
-	help := "Press ? to close"
+	help := fmt.Sprintf("Press %s to close", keymap.Key(m.Keys.Help))
 	status := lipgloss.NewStyle().Width(m.Width).Align(lipgloss.Center).
 		Render(styles.HelpStyle.Render(help))
 
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/blackraven/todo-tui/internal/styles"
+	"github.com/blackraven/todo-tui/internal/themes"
+)
+
+var viewSizes = []struct{ w, h int }{
+	{80, 24},
+	{120, 40},
+}
+
+var viewThemes = []string{"Catppuccin", "Nord"}
+
+// goldenScreens sets up each screen that is compared against golden files
+var goldenScreens = []struct {
+	name  string
+	setup func(h *harness)
+}{
+	{"browse", func(h *harness) {}},
+	{"browse_expanded", func(h *harness) {
+		h.selectTitle("Write report")
+		h.press("v")
+		h.selectTitle("Fix tap")
+		h.press("v")
+	}},
+	{"completed", func(h *harness) { h.press("tab") }},
+	{"detail", func(h *harness) {
+		h.selectTitle("Write report")
+		h.press("enter")
+	}},
+	{"confirm_delete", func(h *harness) { h.press("d") }},
+	{"category_select", func(h *harness) { h.press("c") }},
+	{"help", func(h *harness) { h.press("?") }},
+	{"login", func(h *harness) { h.press("L") }},
+}
+
+func TestViewGolden(t *testing.T) {
+	for _, screen := range goldenScreens {
+		for _, size := range viewSizes {
+			for _, theme := range viewThemes {
+				name := fmt.Sprintf("%s_%dx%d_%s", screen.name, size.w, size.h, theme)
+				t.Run(name, func(t *testing.T) {
+					h := newHarness(t)
+					setTheme(t, h, theme)
+					h.send(tea.WindowSizeMsg{Width: size.w, Height: size.h})
+					screen.setup(h)
+					assertGolden(t, name, h.m.View())
+				})
+			}
+		}
+	}
+}
+
+// setTheme switches the model and the shared styles to the named theme,
+// rendered in 256 colors so that themes differ in the golden files, and
+// restores the default afterwards
+func setTheme(t *testing.T, h *harness, name string) {
+	idx := themeIndex(t, name)
+	h.m.ThemeIndex = idx
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	styles.Update(themes.All[idx])
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		styles.Init()
+	})
+}
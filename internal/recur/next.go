@@ -0,0 +1,110 @@
+package recur
+
+import "time"
+
+// Next returns the due date of the occurrence after the one due at due and
+// completed at done. A task without a due date counts from its completion.
+// Fixed schedules skip occurrences that had already passed when the task
+// was done, so an overdue chore does not come back already overdue.
+func (r Rule) Next(due *time.Time, done time.Time) time.Time {
+	base := done
+	if due != nil {
+		base = *due
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	after := base
+	if done.After(after) {
+		after = done
+	}
+
+	switch r.Kind {
+	case AfterDone:
+		return onDay(done.AddDate(0, 0, interval), base)
+
+	case Daily:
+		t := base.AddDate(0, 0, interval)
+		for !t.After(after) {
+			t = t.AddDate(0, 0, interval)
+		}
+		return t
+
+	case Weekly:
+		days := r.Weekdays
+		if len(days) == 0 {
+			days = []time.Weekday{base.Weekday()}
+		}
+		start := weekStart(base)
+		for t := base.AddDate(0, 0, 1); ; t = t.AddDate(0, 0, 1) {
+			weeks := int(weekStart(t).Sub(start).Hours()+12) / (24 * 7)
+			if weeks%interval == 0 && hasDay(days, t.Weekday()) && t.After(after) {
+				return t
+			}
+		}
+
+	case Monthly:
+		for k := 0; ; k += interval {
+			t := r.inMonth(base, k)
+			if t.After(base) && t.After(after) {
+				return t
+			}
+		}
+	}
+	return base
+}
+
+// inMonth returns the rule's day in the month k months after base's, at
+// base's time of day
+func (r Rule) inMonth(base time.Time, k int) time.Time {
+	first := time.Date(base.Year(), base.Month()+time.Month(k), 1,
+		base.Hour(), base.Minute(), base.Second(), 0, base.Location())
+	last := first.AddDate(0, 1, -1).Day()
+
+	if r.Week != 0 {
+		if r.Week == LastDay {
+			t := first.AddDate(0, 0, last-1)
+			for t.Weekday() != r.Weekday {
+				t = t.AddDate(0, 0, -1)
+			}
+			return t
+		}
+		offset := (int(r.Weekday) - int(first.Weekday()) + 7) % 7
+		return first.AddDate(0, 0, offset+7*(r.Week-1))
+	}
+
+	day := r.MonthDay
+	switch {
+	case day == LastDay:
+		day = last
+	case day == 0:
+		day = base.Day()
+	}
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// onDay returns the date of day at the time of day of clock
+func onDay(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
+
+// weekStart returns midnight on the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+func hasDay(days []time.Weekday, d time.Weekday) bool {
+	for _, x := range days {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package recur
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	cases := map[string]string{
+		"daily":                     "daily",
+		"Every 3 days":              "every 3 days",
+		"weekly":                    "weekly",
+		"weekly on Thu, mon":        "weekly on mon,thu",
+		"every 2 weeks on friday":   "every 2 weeks on fri",
+		"every tue,sat":             "weekly on tue,sat",
+		"monthly":                   "monthly",
+		"monthly on the 15th":       "monthly on 15",
+		"monthly on last":           "monthly on last",
+		"every 3 months on 2nd tue": "every 3 months on 2nd tue",
+		"monthly on last fri":       "monthly on last fri",
+		"every 10 days after done":  "every 10 days after done",
+		"after 2 weeks":             "every 14 days after done",
+	}
+	for in, want := range cases {
+		r, err := Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if got := r.String(); got != want {
+			t.Errorf("Parse(%q) = %q, want %q", in, got, want)
+		}
+		if again, err := Parse(r.String()); err != nil || again.String() != want {
+			t.Errorf("%q does not round-trip: %v", want, err)
+		}
+	}
+
+	for _, in := range []string{"", "sometimes", "every 0 days", "monthly on 32", "weekly on blursday", "monthly after done"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded", in)
+		}
+	}
+}
+
+func date(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		rule, due, done, want string
+	}{
+		{"daily", "2026-01-05 09:00", "2026-01-05 08:00", "2026-01-06 09:00"},
+		// Missed occurrences are skipped
+		{"daily", "2026-01-01 09:00", "2026-01-05 10:00", "2026-01-06 09:00"},
+		{"every 3 days", "2026-01-05 09:00", "2026-01-05 08:00", "2026-01-08 09:00"},
+		// Mon 5 Jan 2026
+		{"weekly", "2026-01-05 18:00", "2026-01-05 12:00", "2026-01-12 18:00"},
+		{"weekly on mon,thu", "2026-01-05 18:00", "2026-01-05 12:00", "2026-01-08 18:00"},
+		{"weekly on mon,thu", "2026-01-08 18:00", "2026-01-08 12:00", "2026-01-12 18:00"},
+		{"every 2 weeks on mon,thu", "2026-01-08 18:00", "2026-01-08 12:00", "2026-01-19 18:00"},
+		{"monthly", "2026-01-31 09:00", "2026-01-30 09:00", "2026-02-28 09:00"},
+		{"monthly on 15", "2026-01-03 09:00", "2026-01-02 09:00", "2026-01-15 09:00"},
+		{"monthly on last", "2026-02-28 09:00", "2026-02-28 08:00", "2026-03-31 09:00"},
+		{"monthly on 2nd tue", "2026-01-13 09:00", "2026-01-13 08:00", "2026-02-10 09:00"},
+		{"monthly on last fri", "2026-01-30 09:00", "2026-01-30 08:00", "2026-02-27 09:00"},
+		{"every 5 days after done", "2026-01-01 09:00", "2026-01-10 22:00", "2026-01-15 09:00"},
+	}
+	for _, c := range cases {
+		r, err := Parse(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		due := date(c.due)
+		got := r.Next(&due, date(c.done))
+		if !got.Equal(date(c.want)) {
+			t.Errorf("%s from %s done %s = %s, want %s", c.rule, c.due, c.done, got.Format("2006-01-02 15:04 Mon"), c.want)
+		}
+	}
+
+	// Without a due date the schedule counts from completion
+	r, _ := Parse("daily")
+	if got := r.Next(nil, date("2026-01-05 10:30")); !got.Equal(date("2026-01-06 10:30")) {
+		t.Errorf("next without due = %s", got)
+	}
+}
+
+func TestMonthlyKeepsItsDay(t *testing.T) {
+	r, _ := Parse("monthly")
+	due := date("2026-01-31 09:00")
+	r = r.Anchor(&due)
+	if got := r.String(); got != "monthly on 31" {
+		t.Errorf("anchored rule = %s", got)
+	}
+	for _, want := range []string{"2026-02-28 09:00", "2026-03-31 09:00", "2026-04-30 09:00", "2026-05-31 09:00"} {
+		due = r.Next(&due, due)
+		if !due.Equal(date(want)) {
+			t.Fatalf("next = %s, want %s", due.Format("2006-01-02 15:04"), want)
+		}
+	}
+
+	// Leap years keep the 29th
+	due = date("2028-01-31 09:00")
+	due = r.Next(&due, due)
+	if !due.Equal(date("2028-02-29 09:00")) {
+		t.Errorf("next in a leap year = %s", due.Format("2006-01-02 15:04"))
+	}
+
+	// Only plain monthly rules take the due date's day
+	for _, spec := range []string{"monthly on 15", "monthly on last", "monthly on 2nd tue", "weekly"} {
+		r, _ := Parse(spec)
+		if got := r.Anchor(&due).String(); got != spec {
+			t.Errorf("%s anchored = %s", spec, got)
+		}
+	}
+}
+
+func TestStorePersists(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := Parse("weekly on mon")
+	if err := s.Set(42, r); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reloaded.Get(42); !ok || got.String() != "weekly on mon" {
+		t.Errorf("reloaded rule = %v, %v", got, ok)
+	}
+	if err := reloaded.Delete(42); err != nil {
+		t.Fatal(err)
+	}
+	if ids := reloaded.IDs(); len(ids) != 0 {
+		t.Errorf("IDs after delete = %v", ids)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	home := srv.AddCategory(fakeserver.DemoEmail, "Home", "#96CEB4")
+	due := date("2026-01-05 18:00")
+	task := srv.AddTask(fakeserver.DemoEmail, api.Task{
+		Title: "Take out bins", Priority: 4, DueAt: &due, CategoryID: &home.ID,
+		Subtasks: []api.Subtask{{Title: "Recycling", Status: "done"}, {Title: "General waste", Status: "done"}},
+	})
+
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := Load(cfg.DataDir)
+
+	if next, err := s.Complete(client, task, date("2026-01-05 19:00")); next != nil || err != nil {
+		t.Fatalf("task without a rule spawned %v, %v", next, err)
+	}
+
+	r, _ := Parse("weekly")
+	s.Set(task.ID, r)
+	next, err := s.Complete(client, task, date("2026-01-05 19:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.DueAt == nil || !next.DueAt.Equal(date("2026-01-12 18:00")) {
+		t.Errorf("next due = %v", next.DueAt)
+	}
+	if next.CategoryID == nil || *next.CategoryID != home.ID || next.Priority != 4 {
+		t.Errorf("next = %+v", next)
+	}
+	if len(next.Subtasks) != 2 || next.Subtasks[0].Title != "Recycling" || next.Subtasks[0].Status != "open" {
+		t.Errorf("next subtasks = %+v", next.Subtasks)
+	}
+	if _, ok := s.Get(task.ID); ok {
+		t.Error("rule still attached to the completed task")
+	}
+	if _, ok := s.Get(next.ID); !ok {
+		t.Error("rule not moved to the next occurrence")
+	}
+}
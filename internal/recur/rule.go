@@ -0,0 +1,271 @@
+// Package recur describes repeating tasks. The API has no notion of
+// recurrence, so rules are kept on the client, keyed by task ID, and the
+// next occurrence is created as a new task when the current one is done.
+package recur
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the shape of a recurrence rule
+type Kind int
+
+const (
+	// Daily repeats every Interval days on the due date's schedule
+	Daily Kind = iota
+	// Weekly repeats on Weekdays every Interval weeks
+	Weekly
+	// Monthly repeats every Interval months, on a day of the month or on
+	// the Nth weekday of the month
+	Monthly
+	// AfterDone repeats Interval days after each completion
+	AfterDone
+)
+
+// LastDay stands for the last day, or last weekday, of a month
+const LastDay = -1
+
+// Rule is a recurrence rule
+type Rule struct {
+	Kind     Kind
+	Interval int
+
+	// Weekdays are the days a Weekly rule falls on; empty means the
+	// weekday of the due date
+	Weekdays []time.Weekday
+
+	// MonthDay is the day of the month for a Monthly rule: 1-31, LastDay,
+	// or zero for the day of the due date
+	MonthDay int
+	// Week and Weekday select the Nth weekday of the month instead of a
+	// date when Week is non-zero; Week is 1-4 or LastDay
+	Week    int
+	Weekday time.Weekday
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var ordinals = map[string]int{
+	"1st": 1, "first": 1, "2nd": 2, "second": 2, "3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4, "last": LastDay,
+}
+
+// Parse reads a rule such as "daily", "every 3 days", "weekly on mon,thu",
+// "every 2 weeks on fri", "monthly on 15", "monthly on last",
+// "monthly on 2nd tue" or "every 10 days after done"
+func Parse(s string) (Rule, error) {
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " ")))
+	var words []string
+	for _, f := range fields {
+		if f != "on" && f != "the" && f != "every" {
+			words = append(words, f)
+		}
+	}
+	if len(fields) == 0 {
+		return Rule{}, fmt.Errorf("empty recurrence rule")
+	}
+	bad := func() (Rule, error) {
+		return Rule{}, fmt.Errorf("unrecognised recurrence rule %q", s)
+	}
+
+	// Trailing "after done" or "after completion" makes the rule relative
+	afterDone := false
+	if n := len(words); n >= 2 && words[n-2] == "after" && (words[n-1] == "done" || words[n-1] == "completion") {
+		afterDone = true
+		words = words[:n-2]
+	} else if len(words) > 0 && words[0] == "after" {
+		afterDone = true
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return bad()
+	}
+
+	r := Rule{Interval: 1}
+	if n, err := strconv.Atoi(words[0]); err == nil {
+		if n < 1 {
+			return Rule{}, fmt.Errorf("recurrence interval must be at least 1")
+		}
+		r.Interval = n
+		words = words[1:]
+		if len(words) == 0 {
+			return bad()
+		}
+	}
+
+	unit, rest := words[0], words[1:]
+	switch unit {
+	case "daily", "day", "days":
+		r.Kind = Daily
+	case "weekly", "week", "weeks":
+		r.Kind = Weekly
+	case "monthly", "month", "months":
+		r.Kind = Monthly
+	default:
+		// "every mon,thu" is weekly on those days
+		if _, ok := weekday(unit); !ok {
+			return bad()
+		}
+		r.Kind = Weekly
+		rest = words
+	}
+
+	if afterDone {
+		if len(rest) > 0 || r.Kind == Monthly {
+			return bad()
+		}
+		if r.Kind == Weekly {
+			r.Interval *= 7
+		}
+		r.Kind = AfterDone
+		return r, nil
+	}
+
+	switch r.Kind {
+	case Daily:
+		if len(rest) > 0 {
+			return bad()
+		}
+	case Weekly:
+		for _, w := range rest {
+			d, ok := weekday(w)
+			if !ok {
+				return bad()
+			}
+			r.Weekdays = appendDay(r.Weekdays, d)
+		}
+	case Monthly:
+		switch len(rest) {
+		case 0:
+		case 1:
+			if rest[0] == "last" {
+				r.MonthDay = LastDay
+				break
+			}
+			day, err := strconv.Atoi(strings.TrimRight(rest[0], "stndrh"))
+			if err != nil || day < 1 || day > 31 {
+				return bad()
+			}
+			r.MonthDay = day
+		case 2:
+			week, ok := ordinals[rest[0]]
+			d, okDay := weekday(rest[1])
+			if !ok || !okDay {
+				return bad()
+			}
+			r.Week, r.Weekday = week, d
+		default:
+			return bad()
+		}
+	}
+	return r, nil
+}
+
+// Anchor pins a plain monthly rule to the day of the month of due, so a
+// task due on the 31st that comes back on the 28th of February is due on
+// the 31st again in March. Other rules, and a nil due, are left as they are.
+func (r Rule) Anchor(due *time.Time) Rule {
+	if r.Kind == Monthly && r.MonthDay == 0 && r.Week == 0 && due != nil {
+		r.MonthDay = due.Day()
+	}
+	return r
+}
+
+// String formats the rule in the form Parse reads
+func (r Rule) String() string {
+	var b strings.Builder
+	every := func(one, many string) {
+		if r.Interval <= 1 {
+			b.WriteString(one)
+		} else {
+			fmt.Fprintf(&b, "every %d %s", r.Interval, many)
+		}
+	}
+	switch r.Kind {
+	case Daily:
+		every("daily", "days")
+	case Weekly:
+		every("weekly", "weeks")
+		if len(r.Weekdays) > 0 {
+			names := make([]string, len(r.Weekdays))
+			for i, d := range r.Weekdays {
+				names[i] = weekdayNames[d]
+			}
+			b.WriteString(" on " + strings.Join(names, ","))
+		}
+	case Monthly:
+		every("monthly", "months")
+		switch {
+		case r.Week != 0:
+			fmt.Fprintf(&b, " on %s %s", ordinal(r.Week), weekdayNames[r.Weekday])
+		case r.MonthDay == LastDay:
+			b.WriteString(" on last")
+		case r.MonthDay > 0:
+			fmt.Fprintf(&b, " on %d", r.MonthDay)
+		}
+	case AfterDone:
+		if r.Interval == 1 {
+			b.WriteString("every day after done")
+		} else {
+			fmt.Fprintf(&b, "every %d days after done", r.Interval)
+		}
+	}
+	return b.String()
+}
+
+// MarshalText stores rules in their text form
+func (r Rule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads a rule written by MarshalText
+func (r *Rule) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func weekday(s string) (time.Weekday, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	for i, name := range weekdayNames {
+		if strings.HasPrefix(s, name) {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// appendDay adds d to the set of days, keeping them in week order
+func appendDay(days []time.Weekday, d time.Weekday) []time.Weekday {
+	for i, existing := range days {
+		if existing == d {
+			return days
+		}
+		if existing > d {
+			return append(days[:i], append([]time.Weekday{d}, days[i:]...)...)
+		}
+	}
+	return append(days, d)
+}
+
+func ordinal(n int) string {
+	switch n {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	case LastDay:
+		return "last"
+	}
+	return fmt.Sprintf("%dth", n)
+}
@@ -0,0 +1,146 @@
+package recur
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// FileName is the name of the rules file within the data directory
+const FileName = "recurrence.json"
+
+// Store holds the recurrence rules of one account, keyed by task ID, and
+// writes them to disk on every change. It is safe for concurrent use.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	rules map[int]Rule
+}
+
+// Load reads the rules file in dataDir. A missing file gives an empty
+// store; on a malformed file the store is empty and the error is returned.
+func Load(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, FileName), rules: make(map[int]Rule)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.rules); err != nil {
+		s.rules = make(map[int]Rule)
+		return s, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// Get returns the rule for a task
+func (s *Store) Get(taskID int) (Rule, bool) {
+	if s == nil {
+		return Rule{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.rules[taskID]
+	return r, ok
+}
+
+// Set attaches a rule to a task
+func (s *Store) Set(taskID int, r Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[taskID] = r
+	return s.save()
+}
+
+// Delete removes a task's rule, if any
+func (s *Store) Delete(taskID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[taskID]; !ok {
+		return nil
+	}
+	delete(s.rules, taskID)
+	return s.save()
+}
+
+// IDs returns the IDs of tasks with a rule, in ascending order
+func (s *Store) IDs() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int, 0, len(s.rules))
+	for id := range s.rules {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// save writes the rules; the caller holds s.mu
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Complete creates the next occurrence of a task that has just been done
+// and moves the rule over to it. It returns nil if the task does not
+// repeat. The new task copies the title, notes, priority, effort, category,
+// tags and subtasks, with every subtask open again.
+func (s *Store) Complete(client *api.Client, t api.Task, done time.Time) (*api.Task, error) {
+	r, ok := s.Get(t.ID)
+	if !ok {
+		return nil, nil
+	}
+
+	due := r.Next(t.DueAt, done)
+	req := api.TaskCreateRequest{Title: t.Title, Notes: t.Notes, DueAt: &due, CategoryID: t.CategoryID, Tags: t.Tags}
+	if t.Priority > 0 {
+		p := t.Priority
+		req.Priority = &p
+	}
+	if t.EffortMin > 0 {
+		e := t.EffortMin
+		req.EffortMin = &e
+	}
+	next, err := client.CreateTask(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.rules, t.ID)
+	s.rules[next.ID] = r.Anchor(&due)
+	err = s.save()
+	s.mu.Unlock()
+	if err != nil {
+		return next, err
+	}
+
+	subtasks := append([]api.Subtask(nil), t.Subtasks...)
+	sort.SliceStable(subtasks, func(i, j int) bool { return subtasks[i].Sort < subtasks[j].Sort })
+	for _, st := range subtasks {
+		sub, err := client.CreateSubtask(next.ID, st.Title)
+		if err != nil {
+			return next, err
+		}
+		next.Subtasks = append(next.Subtasks, *sub)
+	}
+	return next, nil
+}
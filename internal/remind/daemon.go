@@ -0,0 +1,68 @@
+package remind
+
+import (
+	"context"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Daemon checks the server for tasks coming due and notifies about them
+type Daemon struct {
+	Client   *api.Client
+	Store    *Store
+	Notifier Notifier
+	Lead     time.Duration
+
+	// Now is the clock, time.Now when nil
+	Now func() time.Time
+}
+
+// Check fetches the open tasks and sends their due reminders. It returns
+// how many were sent; a failed notification is retried on the next check.
+func (d *Daemon) Check() (int, error) {
+	tasks, err := d.Client.ListTasks(api.TaskListParams{Status: "open", Scope: "all"})
+	if err != nil {
+		return 0, err
+	}
+	if err := d.Store.Prune(tasks); err != nil {
+		return 0, err
+	}
+	now := d.now()
+	sent := 0
+	for _, t := range d.Store.Due(tasks, now, d.Lead) {
+		title, body := Message(t, now)
+		if err := d.Notifier.Notify(title, body); err != nil {
+			return sent, err
+		}
+		sent++
+		if err := d.Store.MarkSent(t); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Run checks every interval until ctx is done. Errors go to report and do
+// not stop the daemon.
+func (d *Daemon) Run(ctx context.Context, every time.Duration, report func(error)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if _, err := d.Check(); err != nil {
+			report(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Daemon) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,61 @@
+package remind
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Notifier delivers a reminder
+type Notifier interface {
+	Notify(title, body string) error
+}
+
+// Desktop shows reminders as desktop notifications with notify-send
+type Desktop struct {
+	// Command is the notify-send binary; empty means notify-send on PATH
+	Command string
+}
+
+// Notify runs notify-send
+func (d Desktop) Notify(title, body string) error {
+	cmd := d.Command
+	if cmd == "" {
+		cmd = "notify-send"
+	}
+	out, err := exec.Command(cmd, "--app-name=todo-tui", title, body).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Terminal writes reminders to a terminal, ringing its bell
+type Terminal struct {
+	W io.Writer
+}
+
+// Notify prints the reminder
+func (t Terminal) Notify(title, body string) error {
+	_, err := fmt.Fprintf(t.W, "\a%s\n  %s\n", title, strings.ReplaceAll(body, "\n", "\n  "))
+	return err
+}
+
+// NewNotifier returns the notifier for a -notifier setting: "desktop",
+// "terminal", or "auto" for desktop notifications where notify-send is
+// installed and the terminal otherwise
+func NewNotifier(kind string, w io.Writer) (Notifier, error) {
+	switch kind {
+	case "desktop":
+		return Desktop{}, nil
+	case "terminal":
+		return Terminal{W: w}, nil
+	case "auto", "":
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			return Desktop{}, nil
+		}
+		return Terminal{W: w}, nil
+	}
+	return nil, fmt.Errorf("invalid notifier %q (want auto, desktop or terminal)", kind)
+}
@@ -0,0 +1,190 @@
+// Package remind fires reminders for tasks with notifications enabled as
+// they come due, remembering which it has sent and which are snoozed.
+package remind
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// FileName is the name of the reminder state within the data directory
+const FileName = "reminders.json"
+
+// DefaultLead is how long before a task is due its reminder fires
+const DefaultLead = 15 * time.Minute
+
+// Store records the reminders sent, by the due date they were for, and the
+// snoozed tasks. It is safe for concurrent use. The file is re-read before
+// each use, so a running daemon sees snoozes made from the command line.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	data file
+}
+
+type file struct {
+	Sent    map[int]time.Time `json:"sent"`
+	Snoozed map[int]time.Time `json:"snoozed"`
+}
+
+// Load reads the reminder state in dataDir. A missing file gives an empty
+// state; on a malformed file the state is empty and the error is returned.
+func Load(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, FileName)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		s.data = file{}
+		return s, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// reload re-reads the state another process may have changed; the caller
+// holds s.mu. A file that can't be read leaves the state as it was.
+func (s *Store) reload() {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.data = file{}
+		return
+	}
+	if err != nil {
+		return
+	}
+	var f file
+	if json.Unmarshal(data, &f) == nil {
+		s.data = f
+	}
+}
+
+// Due returns the tasks to remind about at now: open, with notifications
+// on, due within lead, not snoozed and not yet reminded of that due date
+func (s *Store) Due(tasks []api.Task, now time.Time, lead time.Duration) []api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	var out []api.Task
+	for _, t := range tasks {
+		if t.Status == "done" || !t.NotificationsEnabled || t.DueAt == nil || t.DueAt.Sub(now) > lead {
+			continue
+		}
+		if until, ok := s.data.Snoozed[t.ID]; ok && now.Before(until) {
+			continue
+		}
+		if sent, ok := s.data.Sent[t.ID]; ok && sent.Equal(*t.DueAt) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// MarkSent records that a task was reminded of its current due date and
+// ends any snooze
+func (s *Store) MarkSent(t api.Task) error {
+	if t.DueAt == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	if s.data.Sent == nil {
+		s.data.Sent = make(map[int]time.Time)
+	}
+	s.data.Sent[t.ID] = *t.DueAt
+	delete(s.data.Snoozed, t.ID)
+	return s.save()
+}
+
+// Snooze holds a task's reminder until the given time, when it fires
+// again even if it was already sent
+func (s *Store) Snooze(id int, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	if s.data.Snoozed == nil {
+		s.data.Snoozed = make(map[int]time.Time)
+	}
+	s.data.Snoozed[id] = until
+	delete(s.data.Sent, id)
+	return s.save()
+}
+
+// Prune forgets tasks that are no longer in the list
+func (s *Store) Prune(tasks []api.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	keep := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		keep[t.ID] = true
+	}
+	changed := false
+	for _, m := range []map[int]time.Time{s.data.Sent, s.data.Snoozed} {
+		for id := range m {
+			if !keep[id] {
+				delete(m, id)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// save writes the state; the caller holds s.mu
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Message is the title and body of a task's reminder at now
+func Message(t api.Task, now time.Time) (title, body string) {
+	due := t.DueAt.Local()
+	if left := t.DueAt.Sub(now); left > 0 {
+		title = fmt.Sprintf("Due in %s: %s", minutes(left), t.Title)
+	} else {
+		title = "Overdue: " + t.Title
+	}
+	body = "Due " + due.Format("Mon Jan 2 3:04 PM")
+	if t.Category != nil {
+		body += " · " + t.Category.Name
+	}
+	body += fmt.Sprintf("\nSnooze: todo-tui daemon snooze %d 10m", t.ID)
+	return title, body
+}
+
+// minutes renders a duration as e.g. "5m" or "1h 20m"
+func minutes(d time.Duration) string {
+	m := int(d.Round(time.Minute) / time.Minute)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	if m%60 == 0 {
+		return fmt.Sprintf("%dh", m/60)
+	}
+	return fmt.Sprintf("%dh %dm", m/60, m%60)
+}
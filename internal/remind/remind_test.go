@@ -0,0 +1,158 @@
+package remind
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+// recorder is a Notifier that keeps the reminders it is sent
+type recorder struct {
+	titles []string
+	fail   bool
+}
+
+func (r *recorder) Notify(title, body string) error {
+	if r.fail {
+		return fmt.Errorf("no display")
+	}
+	r.titles = append(r.titles, title)
+	return nil
+}
+
+func TestDue(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	tasks := []api.Task{
+		{ID: 1, Title: "soon", NotificationsEnabled: true, DueAt: at(10 * time.Minute)},
+		{ID: 2, Title: "later", NotificationsEnabled: true, DueAt: at(time.Hour)},
+		{ID: 3, Title: "overdue", NotificationsEnabled: true, DueAt: at(-time.Hour)},
+		{ID: 4, Title: "off", DueAt: at(time.Minute)},
+		{ID: 5, Title: "done", Status: "done", NotificationsEnabled: true, DueAt: at(time.Minute)},
+		{ID: 6, Title: "no date", NotificationsEnabled: true},
+	}
+	titles := func() string {
+		var out []string
+		for _, task := range s.Due(tasks, now, 15*time.Minute) {
+			out = append(out, task.Title)
+		}
+		return strings.Join(out, ", ")
+	}
+	if got := titles(); got != "soon, overdue" {
+		t.Fatalf("due = %s", got)
+	}
+
+	// Sent reminders are not repeated until the due date moves
+	s.MarkSent(tasks[0])
+	if got := titles(); got != "overdue" {
+		t.Errorf("after sending = %s", got)
+	}
+	tasks[0].DueAt = at(5 * time.Minute)
+	if got := titles(); got != "soon, overdue" {
+		t.Errorf("after moving the due date = %s", got)
+	}
+
+	// A snoozed reminder fires again once the snooze ends
+	s.Snooze(3, now.Add(10*time.Minute))
+	if got := titles(); got != "soon" {
+		t.Errorf("while snoozed = %s", got)
+	}
+	now = now.Add(11 * time.Minute)
+	if got := titles(); got != "soon, overdue" {
+		t.Errorf("after snooze = %s", got)
+	}
+}
+
+func TestStoresShareTheFile(t *testing.T) {
+	dir := t.TempDir()
+	daemon, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	due := now.Add(5 * time.Minute)
+	tasks := []api.Task{
+		{ID: 1, Title: "one", NotificationsEnabled: true, DueAt: &due},
+		{ID: 2, Title: "two", NotificationsEnabled: true, DueAt: &due},
+	}
+
+	// A snooze from the command line reaches the running daemon, which
+	// fires the reminder again once it ends
+	daemon.MarkSent(tasks[0])
+	cli.Snooze(1, now.Add(10*time.Minute))
+	if got := daemon.Due(tasks[:1], now.Add(11*time.Minute), 15*time.Minute); len(got) != 1 {
+		t.Errorf("after the snooze = %v, want task 1 again", got)
+	}
+
+	// and the daemon's own writes keep it
+	cli.Snooze(2, now.Add(10*time.Minute))
+	daemon.MarkSent(tasks[0])
+	if err := daemon.Prune(tasks); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fresh.Due(tasks[1:], now, 15*time.Minute); len(got) != 0 {
+		t.Errorf("snooze of task 2 lost: due = %v", got)
+	}
+}
+
+func TestDaemonCheck(t *testing.T) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	due := time.Now().Add(5 * time.Minute)
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Call the plumber", DueAt: &due, NotificationsEnabled: true})
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Quiet", DueAt: &due})
+
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Load(cfg.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := &recorder{fail: true}
+	d := &Daemon{Client: client, Store: store, Notifier: notes, Lead: DefaultLead}
+
+	// A failed notification is retried on the next check
+	if _, err := d.Check(); err == nil {
+		t.Error("no error from a failing notifier")
+	}
+	notes.fail = false
+	if n, err := d.Check(); n != 1 || err != nil {
+		t.Fatalf("check = %d, %v", n, err)
+	}
+	if len(notes.titles) != 1 || !strings.HasPrefix(notes.titles[0], "Due in 5m: Call the plumber") {
+		t.Errorf("titles = %q", notes.titles)
+	}
+
+	// The sent reminder survives a restart
+	if d.Store, err = Load(cfg.DataDir); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := d.Check(); n != 0 {
+		t.Errorf("reminded again after a restart: %q", notes.titles)
+	}
+}
@@ -0,0 +1,238 @@
+// Package schedule proposes time blocks for open tasks: it fits each task's
+// estimated effort into the free working hours of the next few days, around
+// the blocks already scheduled, before the task is due where it can.
+package schedule
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Step is the granularity of proposed start times
+const Step = 15 * time.Minute
+
+// Options are the working hours and how far ahead to plan
+type Options struct {
+	// Start and End are the working hours as offsets from midnight
+	Start, End time.Duration
+
+	// Days are the working weekdays; none means every day
+	Days []time.Weekday
+
+	// Ahead is how many days to plan, starting today
+	Ahead int
+}
+
+// Slot is a proposed block for a task. Late means it ends after the task
+// is due because no earlier time was free.
+type Slot struct {
+	Task       api.Task
+	Start, End time.Time
+	Late       bool
+}
+
+// Skip is a task left out of the plan and why
+type Skip struct {
+	Task   api.Task
+	Reason string
+}
+
+// Plan is a proposal: the slots in time order and the tasks that did not fit
+type Plan struct {
+	Slots   []Slot
+	Skipped []Skip
+}
+
+// span is a stretch of time
+type span struct {
+	start, end time.Time
+}
+
+// window is part of a day, as offsets from midnight
+type window struct {
+	start, end time.Duration
+}
+
+// windows are the parts of the day each preferred time of day covers
+var windows = map[string]window{
+	"morning":   {0, 12 * time.Hour},
+	"afternoon": {12 * time.Hour, 17 * time.Hour},
+	"evening":   {17 * time.Hour, 24 * time.Hour},
+}
+
+// Propose plans the open tasks that are estimated and not already
+// scheduled. Blocks already scheduled stay where they are. Tasks are placed
+// by due date, then priority, then quickest first, each at the earliest
+// free time, keeping to its preferred time of day if that still makes its
+// due date.
+func Propose(tasks []api.Task, now time.Time, opts Options) Plan {
+	var busy []span
+	var todo []api.Task
+	var plan Plan
+	for _, t := range tasks {
+		if t.Status == "done" {
+			continue
+		}
+		if t.ScheduledStart != nil && t.ScheduledEnd != nil && t.ScheduledEnd.After(now) {
+			busy = append(busy, span{*t.ScheduledStart, *t.ScheduledEnd})
+			continue
+		}
+		switch {
+		case t.IsOwner != nil && !*t.IsOwner:
+			plan.Skipped = append(plan.Skipped, Skip{t, "shared with you"})
+		case t.EffortMin <= 0:
+			plan.Skipped = append(plan.Skipped, Skip{t, "no estimate"})
+		default:
+			todo = append(todo, t)
+		}
+	}
+	sort.SliceStable(todo, func(i, j int) bool { return before(todo[i], todo[j]) })
+
+	for _, t := range todo {
+		d := time.Duration(t.EffortMin) * time.Minute
+		if d > opts.End-opts.Start {
+			plan.Skipped = append(plan.Skipped, Skip{t, "longer than a working day"})
+			continue
+		}
+		s, ok := place(t, d, now, opts, busy)
+		if !ok {
+			plan.Skipped = append(plan.Skipped, Skip{t, "no free time"})
+			continue
+		}
+		busy = append(busy, span{s.Start, s.End})
+		plan.Slots = append(plan.Slots, s)
+	}
+	sort.SliceStable(plan.Slots, func(i, j int) bool { return plan.Slots[i].Start.Before(plan.Slots[j].Start) })
+	return plan
+}
+
+// before orders tasks for placing: soonest due first, undated last, then
+// by priority and effort
+func before(a, b api.Task) bool {
+	switch {
+	case a.DueAt != nil && b.DueAt != nil && !a.DueAt.Equal(*b.DueAt):
+		return a.DueAt.Before(*b.DueAt)
+	case (a.DueAt == nil) != (b.DueAt == nil):
+		return a.DueAt != nil
+	case a.Priority != b.Priority:
+		return a.Priority > b.Priority
+	}
+	return a.EffortMin < b.EffortMin
+}
+
+// place finds the task's slot: in its preferred time of day before it is
+// due, then any time before it is due, then the preferred time late, then
+// the earliest time at all
+func place(t api.Task, d time.Duration, now time.Time, opts Options, busy []span) (Slot, bool) {
+	var pref window
+	hasPref := false
+	if t.PreferredTimeOfDay != nil {
+		pref, hasPref = windows[strings.ToLower(strings.TrimSpace(*t.PreferredTimeOfDay))]
+	}
+	onTime := func(end time.Time) bool { return t.DueAt == nil || !end.After(*t.DueAt) }
+	inPref := func(start, end time.Time) bool {
+		from := sinceMidnight(start)
+		return !hasPref || (from >= pref.start && from+end.Sub(start) <= pref.end)
+	}
+
+	for _, try := range []func(start, end time.Time) bool{
+		func(s, e time.Time) bool { return inPref(s, e) && onTime(e) },
+		func(s, e time.Time) bool { return onTime(e) },
+		inPref,
+		func(time.Time, time.Time) bool { return true },
+	} {
+		if start, ok := first(d, now, opts, busy, try); ok {
+			end := start.Add(d)
+			return Slot{Task: t, Start: start, End: end, Late: !onTime(end)}, true
+		}
+	}
+	return Slot{}, false
+}
+
+// first returns the earliest free start, on a Step boundary, for a block of
+// d that ok accepts
+func first(d time.Duration, now time.Time, opts Options, busy []span, ok func(start, end time.Time) bool) (time.Time, bool) {
+	y, mo, day := now.Date()
+	for i := 0; i < opts.Ahead; i++ {
+		midnight := time.Date(y, mo, day+i, 0, 0, 0, 0, now.Location())
+		if !workday(midnight.Weekday(), opts.Days) {
+			continue
+		}
+		open := span{midnight.Add(opts.Start), midnight.Add(opts.End)}
+		if open.start.Before(now) {
+			open.start = now
+		}
+		for _, gap := range gaps(open, busy) {
+			for start := ceil(gap.start, midnight); !start.Add(d).After(gap.end); start = start.Add(Step) {
+				if ok(start, start.Add(d)) {
+					return start, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// gaps returns the parts of open not covered by busy, in order
+func gaps(open span, busy []span) []span {
+	sorted := append([]span(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+	var out []span
+	at := open.start
+	for _, b := range sorted {
+		if !b.end.After(at) || !b.start.Before(open.end) {
+			continue
+		}
+		if b.start.After(at) {
+			out = append(out, span{at, b.start})
+		}
+		at = b.end
+	}
+	if at.Before(open.end) {
+		out = append(out, span{at, open.end})
+	}
+	return out
+}
+
+// ceil rounds t up to the next Step after midnight
+func ceil(t, midnight time.Time) time.Time {
+	since := t.Sub(midnight)
+	if r := since % Step; r != 0 {
+		since += Step - r
+	}
+	return midnight.Add(since)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	y, m, d := t.Date()
+	return t.Sub(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+}
+
+func workday(w time.Weekday, days []time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == w {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply writes the plan's slots to the server as auto-scheduled blocks. It
+// stops at the first error, returning how many were written.
+func Apply(client *api.Client, slots []Slot) (int, error) {
+	auto := true
+	for i, s := range slots {
+		start, end := s.Start, s.End
+		req := api.TaskUpdateRequest{ScheduledStart: &start, ScheduledEnd: &end, AutoScheduled: &auto}
+		if _, err := client.UpdateTask(s.Task.ID, req); err != nil {
+			return i, err
+		}
+	}
+	return len(slots), nil
+}
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+func TestPropose(t *testing.T) {
+	// Wednesday 10:05, working 9-17 on weekdays
+	now := time.Date(2025, 3, 12, 10, 5, 0, 0, time.UTC)
+	at := func(day, hour, min int) *time.Time {
+		v := time.Date(2025, 3, 12+day, hour, min, 0, 0, time.UTC)
+		return &v
+	}
+	afternoon := "afternoon"
+	no := false
+	opts := Options{
+		Start: 9 * time.Hour, End: 17 * time.Hour, Ahead: 3,
+		Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+	tasks := []api.Task{
+		{ID: 1, Title: "meeting", ScheduledStart: at(0, 11, 0), ScheduledEnd: at(0, 12, 0)},
+		{ID: 2, Title: "urgent", Priority: 9, EffortMin: 60, DueAt: at(0, 12, 30)},
+		{ID: 3, Title: "report", Priority: 5, EffortMin: 120},
+		{ID: 4, Title: "calls", Priority: 9, EffortMin: 30, PreferredTimeOfDay: &afternoon},
+		{ID: 5, Title: "unestimated", Priority: 9},
+		{ID: 6, Title: "marathon", EffortMin: 600},
+		{ID: 7, Title: "theirs", EffortMin: 30, IsOwner: &no},
+		{ID: 8, Title: "finished", Status: "done", EffortMin: 30},
+		{ID: 9, Title: "tomorrow", Priority: 1, EffortMin: 300, DueAt: at(1, 17, 0)},
+	}
+	plan := Propose(tasks, now, opts)
+
+	var got []string
+	for _, s := range plan.Slots {
+		line := fmt.Sprintf("%s %s-%s", s.Task.Title, s.Start.Format("Mon 15:04"), s.End.Format("15:04"))
+		if s.Late {
+			line += " late"
+		}
+		got = append(got, line)
+	}
+	for _, s := range plan.Skipped {
+		got = append(got, s.Task.Title+": "+s.Reason)
+	}
+
+	// The urgent task doesn't fit before the meeting, so it runs late
+	// after it; the five hour task no longer fits today
+	want := []string{
+		"urgent Wed 12:00-13:00 late",
+		"calls Wed 13:00-13:30",
+		"report Wed 13:30-15:30",
+		"tomorrow Thu 09:00-14:00",
+		"unestimated: no estimate",
+		"theirs: shared with you",
+		"marathon: longer than a working day",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProposePreferred(t *testing.T) {
+	// Saturday morning, so Monday is the first working day
+	now := time.Date(2025, 3, 15, 8, 0, 0, 0, time.UTC)
+	morning, evening := "Morning", "evening"
+	opts := Options{Start: 9 * time.Hour, End: 17 * time.Hour, Ahead: 5,
+		Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}}
+	due := time.Date(2025, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	plan := Propose([]api.Task{
+		{ID: 1, Title: "a", EffortMin: 60, PreferredTimeOfDay: &morning, Priority: 1},
+		{ID: 2, Title: "b", EffortMin: 240, Priority: 9},
+		{ID: 3, Title: "c", EffortMin: 30, PreferredTimeOfDay: &evening, DueAt: &due},
+	}, now, opts)
+
+	// c has no working evening hours before it is due, so it takes the
+	// first free time; a has no due date, so it waits for a free morning
+	var got []string
+	for _, s := range plan.Slots {
+		got = append(got, s.Task.Title+" "+s.Start.Format("Mon 15:04"))
+	}
+	if g := strings.Join(got, ", "); g != "c Mon 09:00, b Mon 09:30, a Tue 09:00" {
+		t.Errorf("slots = %s", g)
+	}
+}
@@ -0,0 +1,213 @@
+// Package stats summarises how many tasks get done and what is left open.
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// How far back the completion counts go
+const (
+	Days  = 14
+	Weeks = 8
+)
+
+const uncategorized = "Uncategorized"
+
+// Report holds the numbers shown on the stats screen and by `stats`
+type Report struct {
+	Generated time.Time `json:"generated"`
+	Open      int       `json:"open"`
+	Done      int       `json:"done"`
+	Overdue   int       `json:"overdue"`
+
+	// Tasks completed per day and per week (starting Monday), oldest first
+	Daily  []Count `json:"daily"`
+	Weekly []Count `json:"weekly"`
+
+	Categories []CategoryCount `json:"categories"`
+	Priorities []PriorityCount `json:"priorities"`
+	Subtasks   SubtaskCount    `json:"subtasks"`
+
+	// AvgCompletionHours is the mean time from creation to completion over
+	// the Measured done tasks that have both times
+	AvgCompletionHours float64 `json:"avg_completion_hours"`
+	Measured           int     `json:"measured"`
+}
+
+// Count is the number of tasks completed in the period starting on Start
+type Count struct {
+	Start string `json:"start"`
+	Done  int    `json:"done"`
+}
+
+// CategoryCount is a category's share of the tasks
+type CategoryCount struct {
+	Name    string `json:"name"`
+	Open    int    `json:"open"`
+	Overdue int    `json:"overdue"`
+	Done    int    `json:"done"`
+}
+
+// PriorityCount is how many tasks have a priority
+type PriorityCount struct {
+	Priority int `json:"priority"`
+	Open     int `json:"open"`
+	Done     int `json:"done"`
+}
+
+// SubtaskCount covers the subtasks of every task
+type SubtaskCount struct {
+	Total int     `json:"total"`
+	Done  int     `json:"done"`
+	Rate  float64 `json:"rate"`
+}
+
+// Fetch lists the open and done tasks in every scope, as the task lists
+// do, and computes their report
+func Fetch(client *api.Client) (Report, error) {
+	open, err := client.ListTasks(api.TaskListParams{Status: "open", Scope: "all"})
+	if err != nil {
+		return Report{}, err
+	}
+	done, err := client.ListTasks(api.TaskListParams{Status: "done", Scope: "all"})
+	if err != nil {
+		return Report{}, err
+	}
+	return Compute(open, done, time.Now()), nil
+}
+
+// Compute builds a report from the open and done task lists as of now.
+// Completions are counted in now's time zone.
+func Compute(open, done []api.Task, now time.Time) Report {
+	r := Report{Generated: now, Open: len(open), Done: len(done)}
+
+	today := startOfDay(now)
+	daily := make([]int, Days)
+	weekly := make([]int, Weeks)
+	thisWeek := today.AddDate(0, 0, -daysSinceMonday(today))
+
+	categories := map[string]*CategoryCount{}
+	category := func(t api.Task) *CategoryCount {
+		name := uncategorized
+		if t.Category != nil {
+			name = t.Category.Name
+		}
+		if categories[name] == nil {
+			categories[name] = &CategoryCount{Name: name}
+		}
+		return categories[name]
+	}
+	priorities := map[int]*PriorityCount{}
+	priority := func(t api.Task) *PriorityCount {
+		if priorities[t.Priority] == nil {
+			priorities[t.Priority] = &PriorityCount{Priority: t.Priority}
+		}
+		return priorities[t.Priority]
+	}
+	subtasks := func(t api.Task) {
+		for _, st := range t.Subtasks {
+			r.Subtasks.Total++
+			if st.Status == "done" {
+				r.Subtasks.Done++
+			}
+		}
+	}
+
+	for _, t := range open {
+		c := category(t)
+		c.Open++
+		if t.DueAt != nil && t.DueAt.Before(now) {
+			c.Overdue++
+			r.Overdue++
+		}
+		priority(t).Open++
+		subtasks(t)
+	}
+
+	var total time.Duration
+	for _, t := range done {
+		category(t).Done++
+		priority(t).Done++
+		subtasks(t)
+		if t.CompletedAt == nil {
+			continue
+		}
+		day := startOfDay(t.CompletedAt.In(now.Location()))
+		if i := Days - 1 - daysBetween(day, today); i >= 0 && i < Days {
+			daily[i]++
+		}
+		week := day.AddDate(0, 0, -daysSinceMonday(day))
+		if i := Weeks - 1 - daysBetween(week, thisWeek)/7; i >= 0 && i < Weeks {
+			weekly[i]++
+		}
+		if t.CreatedAt != nil && !t.CompletedAt.Before(*t.CreatedAt) {
+			total += t.CompletedAt.Sub(*t.CreatedAt)
+			r.Measured++
+		}
+	}
+
+	for i, n := range daily {
+		r.Daily = append(r.Daily, Count{Start: today.AddDate(0, 0, i-Days+1).Format(time.DateOnly), Done: n})
+	}
+	for i, n := range weekly {
+		r.Weekly = append(r.Weekly, Count{Start: thisWeek.AddDate(0, 0, 7*(i-Weeks+1)).Format(time.DateOnly), Done: n})
+	}
+	if r.Measured > 0 {
+		r.AvgCompletionHours = total.Hours() / float64(r.Measured)
+	}
+	if r.Subtasks.Total > 0 {
+		r.Subtasks.Rate = float64(r.Subtasks.Done) / float64(r.Subtasks.Total)
+	}
+
+	for _, c := range categories {
+		r.Categories = append(r.Categories, *c)
+	}
+	sort.Slice(r.Categories, func(i, j int) bool {
+		a, b := r.Categories[i], r.Categories[j]
+		if (a.Name == uncategorized) != (b.Name == uncategorized) {
+			return b.Name == uncategorized
+		}
+		return a.Name < b.Name
+	})
+	for _, p := range priorities {
+		r.Priorities = append(r.Priorities, *p)
+	}
+	sort.Slice(r.Priorities, func(i, j int) bool { return r.Priorities[i].Priority > r.Priorities[j].Priority })
+	return r
+}
+
+// DailyCounts returns the per-day completions, oldest first
+func (r Report) DailyCounts() []int {
+	return counts(r.Daily)
+}
+
+// WeeklyCounts returns the per-week completions, oldest first
+func (r Report) WeeklyCounts() []int {
+	return counts(r.Weekly)
+}
+
+func counts(cs []Count) []int {
+	out := make([]int, len(cs))
+	for i, c := range cs {
+		out[i] = c.Done
+	}
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func daysSinceMonday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// daysBetween counts whole days from a to b, both at midnight; it rounds so
+// daylight saving changes do not matter
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
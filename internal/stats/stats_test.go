@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+func TestCompute(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)
+	at := func(days, hours int) *time.Time {
+		v := now.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour)
+		return &v
+	}
+	work := &api.Category{Name: "Work"}
+
+	open := []api.Task{
+		{Title: "Late", Priority: 9, Category: work, DueAt: at(-1, 0),
+			Subtasks: []api.Subtask{{Status: "done"}, {Status: "open"}}},
+		{Title: "Later", Priority: 2, DueAt: at(3, 0)},
+	}
+	done := []api.Task{
+		{Title: "Today", Priority: 9, Category: work, CreatedAt: at(-2, 0), CompletedAt: at(0, -1)},
+		{Title: "Monday", Priority: 5, CreatedAt: at(-2, -10), CompletedAt: at(-2, -10),
+			Subtasks: []api.Subtask{{Status: "done"}, {Status: "done"}}},
+		{Title: "Last week", Priority: 5, CompletedAt: at(-3, 0)},
+		{Title: "Long ago", Priority: 5, CompletedAt: at(-100, 0)},
+		{Title: "No time", Priority: 5},
+	}
+	r := Compute(open, done, now)
+
+	if r.Open != 2 || r.Done != 5 || r.Overdue != 1 {
+		t.Errorf("open %d, done %d, overdue %d", r.Open, r.Done, r.Overdue)
+	}
+	if len(r.Daily) != Days || r.Daily[Days-1].Start != "2025-03-12" || r.Daily[Days-1].Done != 1 ||
+		r.Daily[Days-3].Done != 1 || r.Daily[Days-4].Done != 1 || sum(r.DailyCounts()) != 3 {
+		t.Errorf("daily = %v", r.Daily)
+	}
+	if len(r.Weekly) != Weeks || r.Weekly[Weeks-1].Start != "2025-03-10" || r.Weekly[Weeks-1].Done != 2 ||
+		r.Weekly[Weeks-2].Done != 1 {
+		t.Errorf("weekly = %v", r.Weekly)
+	}
+	want := []CategoryCount{{Name: "Work", Open: 1, Overdue: 1, Done: 1}, {Name: "Uncategorized", Open: 1, Done: 4}}
+	if len(r.Categories) != 2 || r.Categories[0] != want[0] || r.Categories[1] != want[1] {
+		t.Errorf("categories = %v", r.Categories)
+	}
+	if len(r.Priorities) != 3 || r.Priorities[0] != (PriorityCount{9, 1, 1}) || r.Priorities[1] != (PriorityCount{5, 0, 4}) {
+		t.Errorf("priorities = %v", r.Priorities)
+	}
+	// 47h and 0h
+	if r.Measured != 2 || r.AvgCompletionHours != 23.5 {
+		t.Errorf("average %v over %d", r.AvgCompletionHours, r.Measured)
+	}
+	if r.Subtasks != (SubtaskCount{Total: 4, Done: 3, Rate: 0.75}) {
+		t.Errorf("subtasks = %+v", r.Subtasks)
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil || !strings.Contains(string(data), `"avg_completion_hours":23.5`) {
+		t.Errorf("json = %s, %v", data, err)
+	}
+	var text strings.Builder
+	if err := WriteText(&text, r); err != nil || !strings.Contains(text.String(), "Subtasks done: 3 of 4 (75%)") {
+		t.Errorf("text = %q, %v", text.String(), err)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]int{0, 1, 2, 4}); got != "▁▂▄█" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	if got := Sparkline([]int{0, 0}); got != "▁▁" {
+		t.Errorf("Sparkline of zeros = %q", got)
+	}
+	if Bar(1, 100, 10) != "█" || Bar(0, 100, 10) != "" || Bar(5, 10, 10) != "█████" {
+		t.Error("Bar")
+	}
+}
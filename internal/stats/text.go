@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws one block per count, scaled to the largest
+func Sparkline(counts []int) string {
+	top := 0
+	for _, n := range counts {
+		top = max(top, n)
+	}
+	var sb strings.Builder
+	for _, n := range counts {
+		i := 0
+		if top > 0 {
+			i = n * (len(sparks) - 1) / top
+		}
+		sb.WriteRune(sparks[i])
+	}
+	return sb.String()
+}
+
+// Bar draws n out of top as a bar up to width cells long. Any n above zero
+// gets at least one cell.
+func Bar(n, top, width int) string {
+	if n <= 0 || top <= 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(n*width/top, 1))
+}
+
+// Duration describes a number of hours in the largest sensible unit
+func Duration(hours float64) string {
+	switch {
+	case hours < 1:
+		return fmt.Sprintf("%.0fm", hours*60)
+	case hours < 48:
+		return fmt.Sprintf("%.1fh", hours)
+	}
+	return fmt.Sprintf("%.1fd", hours/24)
+}
+
+// WriteText writes the report as plain text
+func WriteText(w io.Writer, r Report) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Open %d, overdue %d, done %d\n\n", r.Open, r.Overdue, r.Done)
+
+	fmt.Fprintf(&sb, "Completed per day, last %d days\n", len(r.Daily))
+	fmt.Fprintf(&sb, "  %s  %d total\n", Sparkline(r.DailyCounts()), sum(r.DailyCounts()))
+	fmt.Fprintf(&sb, "Completed per week, last %d weeks\n", len(r.Weekly))
+	top := max(1, maxOf(r.WeeklyCounts()))
+	for _, c := range r.Weekly {
+		start, _ := time.Parse(time.DateOnly, c.Start)
+		fmt.Fprintf(&sb, "  %s  %3d %s\n", start.Format("Jan 02"), c.Done, Bar(c.Done, top, 30))
+	}
+
+	sb.WriteString("\nBy category\n")
+	for _, c := range r.Categories {
+		fmt.Fprintf(&sb, "  %-16s %3d open  %3d overdue  %3d done\n", c.Name, c.Open, c.Overdue, c.Done)
+	}
+
+	sb.WriteString("\nBy priority\n")
+	top = 1
+	for _, p := range r.Priorities {
+		top = max(top, p.Open+p.Done)
+	}
+	for _, p := range r.Priorities {
+		fmt.Fprintf(&sb, "  P%-3d %3d open  %3d done  %s\n", p.Priority, p.Open, p.Done, Bar(p.Open+p.Done, top, 20))
+	}
+
+	sb.WriteString("\n")
+	if r.Measured > 0 {
+		fmt.Fprintf(&sb, "Average time to complete: %s (%d tasks)\n", Duration(r.AvgCompletionHours), r.Measured)
+	} else {
+		sb.WriteString("Average time to complete: no completed tasks with times\n")
+	}
+	fmt.Fprintf(&sb, "Subtasks done: %d of %d (%.0f%%)\n", r.Subtasks.Done, r.Subtasks.Total, r.Subtasks.Rate*100)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func sum(ns []int) int {
+	total := 0
+	for _, n := range ns {
+		total += n
+	}
+	return total
+}
+
+func maxOf(ns []int) int {
+	top := 0
+	for _, n := range ns {
+		top = max(top, n)
+	}
+	return top
+}
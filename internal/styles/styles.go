@@ -41,10 +41,13 @@ func Update(t themes.Theme) {
 
 	ListSelectedStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Padding(0, 1).
 		Foreground(t.Accent).
 		Bold(true)
+	if t.SelectionBg != "" {
+		ListSelectedStyle = ListSelectedStyle.Background(t.SelectionBg)
+	}
 
 	ListItemStyle = lipgloss.NewStyle().
 		PaddingLeft(4).
@@ -68,9 +71,9 @@ func Update(t themes.Theme) {
 		Padding(0, 1).
 		MarginRight(1)
 
-	PriorityHighStyle = lipgloss.NewStyle().Foreground(t.Warning).Bold(true)
-	PriorityMedStyle = lipgloss.NewStyle().Foreground(t.Secondary)
-	PriorityLowStyle = lipgloss.NewStyle().Foreground(t.Dim)
+	PriorityHighStyle = lipgloss.NewStyle().Foreground(t.PriorityHigh).Bold(true)
+	PriorityMedStyle = lipgloss.NewStyle().Foreground(t.PriorityMed)
+	PriorityLowStyle = lipgloss.NewStyle().Foreground(t.PriorityLow)
 
 	TabActiveStyle = lipgloss.NewStyle().
 		Foreground(t.Bg).
@@ -89,7 +92,7 @@ func Update(t themes.Theme) {
 
 	InputFieldStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(t.Accent).
+		BorderForeground(t.Border).
 		Padding(0, 1)
 }
 
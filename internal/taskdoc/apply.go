@@ -0,0 +1,267 @@
+package taskdoc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// DefaultColor is used for a category named in a document that does not
+// exist yet
+const DefaultColor = "#45B7D1"
+
+// Plan is the smallest set of API calls that turns a task into what an
+// edited document describes
+type Plan struct {
+	// Task holds the changed fields, Fields their names
+	Task   api.TaskUpdateRequest
+	Fields []string
+
+	// Category is the name of a new category, resolved when applying
+	Category string
+
+	Add    []Item
+	Remove []int
+	Change map[int]api.SubtaskUpdateRequest
+
+	// order is the subtask list as written, reorder whether it differs
+	// from the task's
+	order   []Item
+	reorder bool
+}
+
+// Empty reports whether the document left the task as it was
+func (p Plan) Empty() bool {
+	return len(p.Fields) == 0 && len(p.Add) == 0 && len(p.Remove) == 0 &&
+		len(p.Change) == 0 && !p.reorder
+}
+
+// Summary describes the plan in a few words, e.g. "title, due, 2 subtasks added"
+func (p Plan) Summary() string {
+	parts := append([]string(nil), p.Fields...)
+	count := func(n int, what string) {
+		if n == 1 {
+			parts = append(parts, "1 subtask "+what)
+		} else if n > 1 {
+			parts = append(parts, fmt.Sprintf("%d subtasks %s", n, what))
+		}
+	}
+	count(len(p.Add), "added")
+	count(len(p.Remove), "removed")
+	count(len(p.Change), "changed")
+	if p.reorder {
+		parts = append(parts, "subtasks reordered")
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Diff compares a document with the task it was made from
+func Diff(orig api.Task, doc Doc) (Plan, error) {
+	var p Plan
+	var errs Errors
+	set := func(field string) bool { return doc.present[field] }
+
+	if set("title") && doc.Title != orig.Title {
+		p.Task.Title = &doc.Title
+		p.Fields = append(p.Fields, "title")
+	}
+	if set("status") && doc.Status != orig.Status {
+		p.Task.Status = &doc.Status
+		p.Fields = append(p.Fields, "status")
+	}
+	if set("priority") && doc.Priority != orig.Priority {
+		p.Task.Priority = &doc.Priority
+		p.Fields = append(p.Fields, "priority")
+	}
+	if set("due") {
+		switch {
+		case doc.Due == nil && orig.DueAt != nil:
+			errs = append(errs, Problem{Msg: "due: the server cannot remove a due date"})
+		case doc.Due != nil && (orig.DueAt == nil ||
+			doc.Due.Format(dueLayout) != orig.DueAt.Local().Format(dueLayout)):
+			p.Task.DueAt = doc.Due
+			p.Fields = append(p.Fields, "due")
+		}
+	}
+	if set("effort_min") && doc.EffortMin != orig.EffortMin {
+		p.Task.EffortMin = &doc.EffortMin
+		p.Fields = append(p.Fields, "effort")
+	}
+	if set("category") {
+		name := ""
+		if orig.Category != nil {
+			name = orig.Category.Name
+		}
+		switch {
+		case doc.Category == "" && name != "":
+			errs = append(errs, Problem{Msg: "category: the server cannot remove a category"})
+		case !strings.EqualFold(doc.Category, name):
+			p.Category = doc.Category
+			p.Fields = append(p.Fields, "category")
+		}
+	}
+	if set("tags") && !slices.Equal(doc.Tags, orig.Tags) {
+		tags := append([]string{}, doc.Tags...)
+		p.Task.Tags = &tags
+		p.Fields = append(p.Fields, "tags")
+	}
+	notes := ""
+	if orig.Notes != nil {
+		notes = strings.TrimSpace(*orig.Notes)
+	}
+	if doc.Notes != notes {
+		p.Task.Notes = &doc.Notes
+		p.Fields = append(p.Fields, "notes")
+	}
+
+	// Subtasks
+	current := sortedSubtasks(orig.Subtasks)
+	byID := make(map[int]api.Subtask)
+	for _, st := range current {
+		byID[st.ID] = st
+	}
+	kept := make(map[int]bool)
+	p.Change = make(map[int]api.SubtaskUpdateRequest)
+	for _, item := range doc.Subtasks {
+		if item.ID == 0 {
+			p.Add = append(p.Add, item)
+			continue
+		}
+		st, ok := byID[item.ID]
+		if !ok {
+			errs = append(errs, Problem{Msg: fmt.Sprintf("subtask %d does not belong to this task; remove its <!-- %d --> to add it as new", item.ID, item.ID)})
+			continue
+		}
+		kept[item.ID] = true
+		var req api.SubtaskUpdateRequest
+		if item.Title != st.Title {
+			title := item.Title
+			req.Title = &title
+		}
+		if status := itemStatus(item); status != st.Status {
+			req.Status = &status
+		}
+		if req.Title != nil || req.Status != nil {
+			p.Change[item.ID] = req
+		}
+	}
+
+	// Without reordering, the kept subtasks stay in place and new ones
+	// are appended
+	var expected []Item
+	for _, st := range current {
+		if kept[st.ID] {
+			expected = append(expected, Item{ID: st.ID})
+		} else {
+			p.Remove = append(p.Remove, st.ID)
+		}
+	}
+	p.order = doc.Subtasks
+	for range p.Add {
+		expected = append(expected, Item{})
+	}
+	for i, item := range doc.Subtasks {
+		if i >= len(expected) || expected[i].ID != item.ID {
+			p.reorder = true
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return Plan{}, errs
+	}
+	return p, nil
+}
+
+func itemStatus(item Item) string {
+	if item.Done {
+		return "done"
+	}
+	return "open"
+}
+
+// Apply makes the calls in a plan and returns the task as it now stands.
+// categories are searched for a category named in the document, which is
+// created if it is missing.
+func Apply(client *api.Client, orig api.Task, p Plan, categories []api.Category) (*api.Task, error) {
+	if p.Category != "" {
+		id, err := categoryID(client, p.Category, categories)
+		if err != nil {
+			return nil, err
+		}
+		p.Task.CategoryID = &id
+	}
+	if len(p.Fields) > 0 {
+		if _, err := client.UpdateTask(orig.ID, p.Task); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range p.Remove {
+		if err := client.DeleteSubtask(id); err != nil {
+			return nil, fmt.Errorf("removing subtask: %w", err)
+		}
+	}
+
+	sorts := make(map[int]int)
+	for _, st := range orig.Subtasks {
+		sorts[st.ID] = st.Sort
+	}
+	order := append([]Item(nil), p.order...)
+	for i, item := range order {
+		if item.ID != 0 {
+			continue
+		}
+		st, err := client.CreateSubtask(orig.ID, item.Title)
+		if err != nil {
+			return nil, fmt.Errorf("adding subtask %q: %w", item.Title, err)
+		}
+		order[i].ID = st.ID
+		sorts[st.ID] = st.Sort
+		if item.Done {
+			done := "done"
+			if err := client.UpdateSubtask(st.ID, api.SubtaskUpdateRequest{Status: &done}); err != nil {
+				return nil, fmt.Errorf("completing subtask %q: %w", item.Title, err)
+			}
+		}
+	}
+
+	for id, req := range p.Change {
+		if err := client.UpdateSubtask(id, req); err != nil {
+			return nil, fmt.Errorf("updating subtask: %w", err)
+		}
+	}
+
+	if p.reorder {
+		for i, item := range order {
+			if sorts[item.ID] == i {
+				continue
+			}
+			sort := i
+			if err := client.UpdateSubtask(item.ID, api.SubtaskUpdateRequest{Sort: &sort}); err != nil {
+				return nil, fmt.Errorf("reordering subtasks: %w", err)
+			}
+		}
+	}
+
+	return client.GetTask(orig.ID)
+}
+
+// categoryID finds a category by name, creating it if necessary
+func categoryID(client *api.Client, name string, categories []api.Category) (int, error) {
+	for _, c := range categories {
+		if strings.EqualFold(c.Name, name) {
+			return c.ID, nil
+		}
+	}
+	cat, err := client.CreateCategory(name, DefaultColor)
+	if err != nil {
+		return 0, fmt.Errorf("creating category %q: %w", name, err)
+	}
+	return cat.ID, nil
+}
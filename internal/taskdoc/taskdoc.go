@@ -0,0 +1,335 @@
+// Package taskdoc turns a task into a text document that can be edited in
+// one go: YAML-style front matter for the fields, then the notes as
+// Markdown and a checklist of subtasks.
+//
+//	---
+//	title: Write report
+//	status: open
+//	priority: 5
+//	due: 2026-03-02 17:00
+//	effort_min: 30
+//	category: Work
+//	tags: [writing, q1]
+//	---
+//	Notes, in Markdown.
+//
+//	## Subtasks
+//	- [ ] Outline <!-- 12 -->
+//	- [x] Draft <!-- 13 -->
+//
+// The comment after a subtask keeps track of which subtask it is, so it can
+// be renamed or moved. Lines without one are new subtasks.
+package taskdoc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// SubtaskHeading starts the subtask checklist in the body
+const SubtaskHeading = "## Subtasks"
+
+// Due dates are written in local time in one of these layouts
+const (
+	dueLayout     = "2006-01-02 15:04"
+	dueDateLayout = "2006-01-02"
+)
+
+// Doc is the parsed form of a task document
+type Doc struct {
+	Title     string
+	Status    string
+	Priority  int
+	Due       *time.Time
+	EffortMin int
+	Category  string
+	Tags      []string
+	Notes     string
+	Subtasks  []Item
+
+	// present records the front matter fields the document sets; the
+	// others are left alone
+	present map[string]bool
+}
+
+// Item is a subtask line. ID is zero for subtasks added in the document.
+type Item struct {
+	ID    int
+	Title string
+	Done  bool
+}
+
+// Problem is a validation error, tied to a line of the document when
+// there is one
+type Problem struct {
+	Line int
+	Msg  string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Msg)
+	}
+	return p.Msg
+}
+
+// Errors lists everything wrong with a document
+type Errors []Problem
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, p := range e {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Marshal writes a task as a document
+func Marshal(t api.Task) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString("# status is open or done, priority 0-10, due YYYY-MM-DD [HH:MM]\n")
+	field := func(key, value string) {
+		b.WriteString(strings.TrimSpace(key + ": " + value))
+		b.WriteString("\n")
+	}
+	field("title", quote(t.Title))
+	field("status", t.Status)
+	field("priority", strconv.Itoa(t.Priority))
+	due := ""
+	if t.DueAt != nil {
+		due = t.DueAt.Local().Format(dueLayout)
+	}
+	field("due", due)
+	field("effort_min", strconv.Itoa(t.EffortMin))
+	category := ""
+	if t.Category != nil {
+		category = quote(t.Category.Name)
+	}
+	field("category", category)
+	tags := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		tags[i] = quote(tag)
+	}
+	field("tags", "["+strings.Join(tags, ", ")+"]")
+	b.WriteString("---\n")
+
+	if t.Notes != nil && *t.Notes != "" {
+		b.WriteString(*t.Notes)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(SubtaskHeading + "\n")
+	for _, st := range sortedSubtasks(t.Subtasks) {
+		box := " "
+		if st.Status == "done" {
+			box = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s <!-- %d -->\n", box, st.Title, st.ID)
+	}
+	return b.String()
+}
+
+// quote wraps a value in double quotes when it would not read back as is
+func quote(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "\"#,[]") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var (
+	fieldRe   = regexp.MustCompile(`^([a-z_]+):\s*(.*)$`)
+	subtaskRe = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*?)\s*(?:<!--\s*(\d+)\s*-->)?\s*$`)
+)
+
+// Parse reads a document. Every problem found is reported, as Errors.
+func Parse(text string) (Doc, error) {
+	var doc Doc
+	var errs Errors
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return doc, Errors{{Line: 1, Msg: "the document must start with a --- line"}}
+	}
+	end := -1
+	doc.present = make(map[string]bool)
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			end = i
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := fieldRe.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, Problem{i + 1, "expected 'field: value'"})
+			continue
+		}
+		key, value := m[1], m[2]
+		if doc.present[key] {
+			errs = append(errs, Problem{i + 1, key + " is given twice"})
+		}
+		doc.present[key] = true
+		if msg := doc.setField(key, value); msg != "" {
+			errs = append(errs, Problem{i + 1, msg})
+		}
+	}
+	if end < 0 {
+		return doc, append(errs, Problem{Msg: "the front matter is not closed with a --- line"})
+	}
+	if doc.present["title"] && strings.TrimSpace(doc.Title) == "" {
+		errs = append(errs, Problem{Msg: "title is required"})
+	}
+
+	// Everything after the last subtask heading is the checklist
+	body := lines[end+1:]
+	split := len(body)
+	for i := len(body) - 1; i >= 0; i-- {
+		if strings.TrimSpace(body[i]) == SubtaskHeading {
+			split = i
+			break
+		}
+	}
+	doc.Notes = strings.TrimSpace(strings.Join(body[:split], "\n"))
+
+	ids := make(map[int]bool)
+	for i := split + 1; i < len(body); i++ {
+		lineNo := end + 2 + i
+		line := strings.TrimSpace(body[i])
+		if line == "" {
+			continue
+		}
+		m := subtaskRe.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, Problem{lineNo, "expected a subtask like '- [ ] title'"})
+			continue
+		}
+		item := Item{Title: m[2], Done: m[1] != " "}
+		if m[3] != "" {
+			item.ID, _ = strconv.Atoi(m[3])
+			if ids[item.ID] {
+				errs = append(errs, Problem{lineNo, fmt.Sprintf("subtask %d is listed twice", item.ID)})
+			}
+			ids[item.ID] = true
+		}
+		if item.Title == "" {
+			errs = append(errs, Problem{lineNo, "subtask has no title"})
+		}
+		doc.Subtasks = append(doc.Subtasks, item)
+	}
+
+	if len(errs) > 0 {
+		return doc, errs
+	}
+	return doc, nil
+}
+
+// setField stores one front matter field, returning a message if the value
+// is not valid
+func (d *Doc) setField(key, value string) string {
+	switch key {
+	case "title":
+		d.Title = unquote(value)
+	case "status":
+		if value != "open" && value != "done" {
+			return "status must be open or done"
+		}
+		d.Status = value
+	case "priority":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > 10 {
+			return "priority must be a number from 0 to 10"
+		}
+		d.Priority = n
+	case "due":
+		if value == "" {
+			return ""
+		}
+		due, err := time.ParseInLocation(dueLayout, value, time.Local)
+		if err != nil {
+			due, err = time.ParseInLocation(dueDateLayout, value, time.Local)
+		}
+		if err != nil {
+			return "due must look like 2026-03-02 or 2026-03-02 17:00"
+		}
+		d.Due = &due
+	case "effort_min":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "effort_min must be a number of minutes"
+		}
+		d.EffortMin = n
+	case "category":
+		d.Category = unquote(value)
+	case "tags":
+		d.Tags = nil
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		for _, tag := range strings.Split(value, ",") {
+			if tag = unquote(strings.TrimSpace(tag)); tag != "" {
+				d.Tags = append(d.Tags, tag)
+			}
+		}
+	default:
+		return "unknown field " + key
+	}
+	return ""
+}
+
+func unquote(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
+
+// sortedSubtasks returns subtasks in display order
+func sortedSubtasks(subtasks []api.Subtask) []api.Subtask {
+	out := append([]api.Subtask(nil), subtasks...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Sort < out[j].Sort })
+	return out
+}
+
+// Annotate puts the problems at the top of the front matter as comments, so
+// they can be fixed when the document is opened again. Comments from an
+// earlier attempt are replaced, and line numbers refer to the new text.
+func Annotate(text string, err error) string {
+	lines := strings.Split(text, "\n")
+	var kept []string
+	removed := make([]int, len(lines)+1) // comments dropped before each line
+	for i, line := range lines {
+		removed[i+1] = removed[i]
+		if strings.HasPrefix(line, "# error: ") {
+			removed[i+1]++
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	problems, ok := err.(Errors)
+	if !ok {
+		problems = Errors{{Msg: err.Error()}}
+	}
+	header := len(kept) > 0 && strings.TrimSpace(kept[0]) == "---"
+	var notes []string
+	for _, p := range problems {
+		if p.Line > 1 && p.Line <= len(lines) {
+			p.Line = p.Line - removed[p.Line-1] + len(problems)
+		}
+		notes = append(notes, "# error: "+p.String())
+	}
+	if header {
+		return strings.Join(append(append([]string{kept[0]}, notes...), kept[1:]...), "\n")
+	}
+	return strings.Join(append(notes, kept...), "\n")
+}
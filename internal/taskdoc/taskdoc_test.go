@@ -0,0 +1,140 @@
+package taskdoc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+func sample() api.Task {
+	notes := "Remember the *appendix*"
+	due := time.Date(2026, 3, 2, 17, 0, 0, 0, time.Local)
+	return api.Task{
+		ID: 7, Title: "Write report", Status: "open", Priority: 5, EffortMin: 30,
+		Notes: &notes, DueAt: &due, Tags: []string{"writing", "q1"},
+		Category: &api.Category{ID: 1, Name: "Work"},
+		Subtasks: []api.Subtask{
+			{ID: 13, Title: "Draft", Status: "done", Sort: 1},
+			{ID: 12, Title: "Outline", Status: "open", Sort: 0},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	text := Marshal(sample())
+	if !strings.Contains(text, "- [ ] Outline <!-- 12 -->\n- [x] Draft <!-- 13 -->") {
+		t.Errorf("subtasks not in order:\n%s", text)
+	}
+	doc, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Diff(sample(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() {
+		t.Errorf("unchanged document gives %s", plan.Summary())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	text := Marshal(sample())
+	text = strings.Replace(text, "priority: 5", "priority: 9", 1)
+	text = strings.Replace(text, "tags: [writing, q1]", "tags: []", 1)
+	text = strings.Replace(text, "- [ ] Outline <!-- 12 -->\n", "", 1)
+	text += "- [ ] Proofread\n"
+
+	doc, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Diff(sample(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plan.Summary(); got != "priority, tags, 1 subtask added, 1 subtask removed" {
+		t.Errorf("Summary = %q", got)
+	}
+	if plan.Task.Title != nil || plan.Task.Notes != nil || plan.Task.Tags == nil || len(*plan.Task.Tags) != 0 {
+		t.Errorf("update request = %+v", plan.Task)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	text := Marshal(sample())
+	text = strings.Replace(text, "priority: 5", "priority: high", 1)
+	text = strings.Replace(text, "status: open", "status: later", 1)
+	_, err := Parse(text)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || errs[0].Line != 4 || errs[1].Line != 5 {
+		t.Fatalf("err = %v", err)
+	}
+
+	// Fixed on the second attempt, the comments are ignored
+	again := Annotate(text, err)
+	if !strings.HasPrefix(again, "---\n# error: line 6: status") {
+		t.Errorf("annotated:\n%s", again)
+	}
+	again = strings.Replace(again, "priority: high", "priority: 5", 1)
+	again = strings.Replace(again, "status: later", "status: open", 1)
+	if _, err := Parse(again); err != nil {
+		t.Errorf("fixed document: %v", err)
+	}
+
+	doc, _ := Parse(strings.Replace(Marshal(sample()), "due: 2026-03-02 17:00", "due:", 1))
+	if _, err := Diff(sample(), doc); err == nil {
+		t.Error("removing the due date accepted")
+	}
+}
+
+func TestApply(t *testing.T) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	srv.AddTask(fakeserver.DemoEmail, api.Task{Title: "Write report", Subtasks: []api.Subtask{
+		{Title: "Outline"}, {Title: "Draft"},
+	}})
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+	orig := srv.Tasks()[0]
+
+	lines := strings.Split(Marshal(orig), "\n")
+	n := len(lines)
+	// Swap the two subtasks, tick the outline and add one at the top
+	lines[n-3], lines[n-2] = lines[n-2], strings.Replace(lines[n-3], "[ ]", "[x]", 1)
+	text := strings.Join(lines[:n-3], "\n") + "\n- [ ] Research\n" + strings.Join(lines[n-3:], "\n")
+	text = strings.Replace(text, "category:\n", "category: Home\n", 1)
+
+	doc, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Diff(orig, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := Apply(client, orig, plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, st := range sortedSubtasks(task.Subtasks) {
+		got = append(got, st.Title+"="+st.Status)
+	}
+	if strings.Join(got, ",") != "Research=open,Draft=open,Outline=done" {
+		t.Errorf("subtasks = %v", got)
+	}
+	if task.Category == nil || task.Category.Name != "Home" {
+		t.Errorf("category = %+v", task.Category)
+	}
+}
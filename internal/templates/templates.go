@@ -0,0 +1,267 @@
+// Package templates stores reusable task blueprints, such as a release
+// checklist, and turns them into real tasks with their subtasks.
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// DirName is the directory under the data directory that holds templates
+const DirName = "templates"
+
+// DefaultColor is used for a template's category if it has to be created
+const DefaultColor = "#45B7D1"
+
+// Template is a named task blueprint. Text fields may contain variables:
+// {{date}}, {{time}}, {{weekday}} and {{prompt:name}}, whose value is
+// asked for when the template is used.
+type Template struct {
+	Name      string   `json:"-"`
+	Title     string   `json:"title"`
+	Notes     string   `json:"notes,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	Priority  int      `json:"priority,omitempty"`
+	EffortMin int      `json:"effort_min,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Subtasks  []string `json:"subtasks,omitempty"`
+}
+
+// Dir returns the template directory within a data directory
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, DirName)
+}
+
+// validName keeps template names usable as file names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Load reads every template in dataDir, sorted by name. A missing
+// directory means there are no templates.
+func Load(dataDir string) ([]Template, error) {
+	paths, err := filepath.Glob(filepath.Join(Dir(dataDir), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []Template
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return out, err
+		}
+		var t Template
+		if err := json.Unmarshal(data, &t); err != nil {
+			return out, fmt.Errorf("%s: %w", path, err)
+		}
+		t.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Find returns the template with the given name
+func Find(list []Template, name string) (Template, bool) {
+	for _, t := range list {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Save writes a template to dataDir, replacing one with the same name
+func Save(dataDir string, t Template) error {
+	if err := checkName(t.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return fmt.Errorf("template %q has no title", t.Name)
+	}
+	dir := Dir(dataDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, t.Name+".json"), append(data, '\n'), 0600)
+}
+
+// Delete removes a template, matching its name as Find does. The file is
+// not read, so a template that no longer parses can still be deleted.
+func Delete(dataDir, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(Dir(dataDir), "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if strings.EqualFold(strings.TrimSuffix(filepath.Base(path), ".json"), name) {
+			return os.Remove(path)
+		}
+	}
+	return fmt.Errorf("no template named %q", name)
+}
+
+// checkName rejects names that can't be used as file names
+func checkName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid template name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// FromTask captures an existing task as a template
+func FromTask(name string, task api.Task) Template {
+	t := Template{
+		Name:      name,
+		Title:     task.Title,
+		Priority:  task.Priority,
+		EffortMin: task.EffortMin,
+		Tags:      append([]string(nil), task.Tags...),
+	}
+	if task.Notes != nil {
+		t.Notes = *task.Notes
+	}
+	if task.Category != nil {
+		t.Category = task.Category.Name
+	}
+	subtasks := append([]api.Subtask(nil), task.Subtasks...)
+	sort.SliceStable(subtasks, func(i, j int) bool { return subtasks[i].Sort < subtasks[j].Sort })
+	for _, st := range subtasks {
+		t.Subtasks = append(t.Subtasks, st.Title)
+	}
+	return t
+}
+
+var variable = regexp.MustCompile(`\{\{\s*([a-z]+)(?::\s*([^}]*?))?\s*\}\}`)
+
+// Prompts returns the names of the {{prompt:name}} variables in the
+// template, in the order they first appear
+func (t Template) Prompts() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, text := range t.texts() {
+		for _, m := range variable.FindAllStringSubmatch(text, -1) {
+			if m[1] == "prompt" && m[2] != "" && !seen[m[2]] {
+				seen[m[2]] = true
+				out = append(out, m[2])
+			}
+		}
+	}
+	return out
+}
+
+// texts lists the fields that may contain variables
+func (t Template) texts() []string {
+	return append([]string{t.Title, t.Notes, t.Category}, append(t.Tags, t.Subtasks...)...)
+}
+
+// Expand substitutes the template's variables. vars supplies the prompted
+// values; a missing one is an error, as is an unknown variable.
+func (t Template) Expand(vars map[string]string, now time.Time) (Template, error) {
+	var err error
+	expand := func(s string) string {
+		return variable.ReplaceAllStringFunc(s, func(match string) string {
+			m := variable.FindStringSubmatch(match)
+			switch m[1] {
+			case "date":
+				return now.Format("2006-01-02")
+			case "time":
+				return now.Format("15:04")
+			case "weekday":
+				return now.Format("Monday")
+			case "prompt":
+				if v, ok := vars[m[2]]; ok {
+					return v
+				}
+				if err == nil {
+					err = fmt.Errorf("template %q needs a value for %q", t.Name, m[2])
+				}
+			default:
+				if err == nil {
+					err = fmt.Errorf("template %q: unknown variable %s", t.Name, match)
+				}
+			}
+			return match
+		})
+	}
+
+	out := t
+	out.Title = expand(t.Title)
+	out.Notes = expand(t.Notes)
+	out.Category = expand(t.Category)
+	out.Tags = nil
+	for _, tag := range t.Tags {
+		out.Tags = append(out.Tags, expand(tag))
+	}
+	out.Subtasks = nil
+	for _, st := range t.Subtasks {
+		out.Subtasks = append(out.Subtasks, expand(st))
+	}
+	return out, err
+}
+
+// Instantiate creates a task from an expanded template, then its subtasks.
+// The category is looked up by name among categories and created if it does
+// not exist yet. The returned task includes the new subtasks.
+func Instantiate(client *api.Client, t Template, categories []api.Category) (*api.Task, error) {
+	req := api.TaskCreateRequest{Title: t.Title, Tags: t.Tags}
+	if t.Notes != "" {
+		notes := t.Notes
+		req.Notes = &notes
+	}
+	if t.Priority > 0 {
+		p := t.Priority
+		req.Priority = &p
+	}
+	if t.EffortMin > 0 {
+		e := t.EffortMin
+		req.EffortMin = &e
+	}
+	if t.Category != "" {
+		id, err := categoryID(client, t.Category, categories)
+		if err != nil {
+			return nil, err
+		}
+		req.CategoryID = &id
+	}
+
+	task, err := client.CreateTask(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, title := range t.Subtasks {
+		sub, err := client.CreateSubtask(task.ID, title)
+		if err != nil {
+			return task, fmt.Errorf("adding subtask %q: %w", title, err)
+		}
+		task.Subtasks = append(task.Subtasks, *sub)
+	}
+	return task, nil
+}
+
+// categoryID finds a category by name, creating it if necessary
+func categoryID(client *api.Client, name string, categories []api.Category) (int, error) {
+	for _, c := range categories {
+		if strings.EqualFold(c.Name, name) {
+			return c.ID, nil
+		}
+	}
+	cat, err := client.CreateCategory(name, DefaultColor)
+	if err != nil {
+		return 0, fmt.Errorf("creating category %q: %w", name, err)
+	}
+	return cat.ID, nil
+}
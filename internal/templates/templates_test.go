@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+	"github.com/blackraven/todo-tui/internal/api/fakeserver"
+	"github.com/blackraven/todo-tui/internal/config"
+)
+
+var release = Template{
+	Name:     "release",
+	Title:    "Release {{prompt:version}}",
+	Notes:    "Cut on {{date}} ({{weekday}})",
+	Category: "Work",
+	Priority: 8,
+	Subtasks: []string{"Tag v{{ prompt:version }}", "Announce to {{prompt:channel}}"},
+}
+
+func TestPrompts(t *testing.T) {
+	if got := strings.Join(release.Prompts(), ","); got != "version,channel" {
+		t.Errorf("Prompts = %s, want version,channel", got)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+	got, err := release.Expand(map[string]string{"version": "1.4", "channel": "#eng"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Release 1.4" || got.Notes != "Cut on 2026-03-02 (Monday)" {
+		t.Errorf("expanded = %+v", got)
+	}
+	if got.Subtasks[0] != "Tag v1.4" || got.Subtasks[1] != "Announce to #eng" {
+		t.Errorf("subtasks = %v", got.Subtasks)
+	}
+	if release.Title != "Release {{prompt:version}}" {
+		t.Error("Expand modified the template")
+	}
+
+	if _, err := release.Expand(map[string]string{"version": "1.4"}, now); err == nil || !strings.Contains(err.Error(), "channel") {
+		t.Errorf("missing prompt error = %v", err)
+	}
+	bad := Template{Name: "bad", Title: "{{nope}}"}
+	if _, err := bad.Expand(nil, now); err == nil {
+		t.Error("unknown variable accepted")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, release); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(dir, Template{Name: "onboarding", Title: "Onboard {{prompt:name}}"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(dir, Template{Name: "../escape", Title: "x"}); err == nil {
+		t.Error("path-like name accepted")
+	}
+
+	list, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "onboarding" || list[1].Name != "release" {
+		t.Fatalf("Load = %+v", list)
+	}
+	if got, ok := Find(list, "Release"); !ok || len(got.Subtasks) != 2 {
+		t.Errorf("Find = %+v, %v", got, ok)
+	}
+
+	if err := Delete(dir, "Onboarding"); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := Load(dir); len(list) != 1 {
+		t.Errorf("after delete = %+v", list)
+	}
+
+	// Names that reach outside the templates directory are refused
+	outside := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(outside, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(dir, "../config"); err == nil {
+		t.Error("path-like name deleted")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the templates directory: %v", err)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	srv := fakeserver.New()
+	defer srv.Close()
+	srv.AddUser(fakeserver.DemoEmail, fakeserver.DemoPassword)
+	cfg := config.ForDataDir(t.TempDir())
+	cfg.APIURL = srv.URL()
+	client := api.NewClient(cfg)
+	if err := client.Login(fakeserver.DemoEmail, fakeserver.DemoPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := release.Expand(map[string]string{"version": "1.4", "channel": "#eng"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := Instantiate(client, tmpl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Subtasks) != 2 {
+		t.Errorf("returned subtasks = %+v", task.Subtasks)
+	}
+
+	stored := srv.Tasks()
+	if len(stored) != 1 {
+		t.Fatalf("server tasks = %+v", stored)
+	}
+	got := stored[0]
+	if got.Title != "Release 1.4" || got.Priority != 8 || got.Category == nil || got.Category.Name != "Work" {
+		t.Errorf("task = %+v", got)
+	}
+	if len(got.Subtasks) != 2 || got.Subtasks[0].Title != "Tag v1.4" {
+		t.Errorf("subtasks = %+v", got.Subtasks)
+	}
+
+	// The category created the first time is reused afterwards
+	if _, err := Instantiate(client, tmpl, srv.Categories()); err != nil {
+		t.Fatal(err)
+	}
+	if cats := srv.Categories(); len(cats) != 1 {
+		t.Errorf("categories = %+v", cats)
+	}
+}
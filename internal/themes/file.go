@@ -0,0 +1,237 @@
+package themes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Dir is the directory in the data directory that holds theme files
+const Dir = "themes"
+
+// colorKeys maps the keys of a theme file to the color they set
+var colorKeys = map[string]func(*Theme) *lipgloss.Color{
+	"bg":            func(t *Theme) *lipgloss.Color { return &t.Bg },
+	"fg":            func(t *Theme) *lipgloss.Color { return &t.Fg },
+	"dim":           func(t *Theme) *lipgloss.Color { return &t.Dim },
+	"accent":        func(t *Theme) *lipgloss.Color { return &t.Accent },
+	"secondary":     func(t *Theme) *lipgloss.Color { return &t.Secondary },
+	"success":       func(t *Theme) *lipgloss.Color { return &t.Success },
+	"warning":       func(t *Theme) *lipgloss.Color { return &t.Warning },
+	"badge_fg":      func(t *Theme) *lipgloss.Color { return &t.BadgeFg },
+	"selection_bg":  func(t *Theme) *lipgloss.Color { return &t.SelectionBg },
+	"border":        func(t *Theme) *lipgloss.Color { return &t.Border },
+	"priority_high": func(t *Theme) *lipgloss.Color { return &t.PriorityHigh },
+	"priority_med":  func(t *Theme) *lipgloss.Color { return &t.PriorityMed },
+	"priority_low":  func(t *Theme) *lipgloss.Color { return &t.PriorityLow },
+}
+
+// required are the colors a theme must set unless it extends another
+var required = []string{"bg", "fg", "dim", "accent", "secondary", "success", "warning"}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Load reads the .json and .toml files in dir. Themes may extend one of
+// base by name. Files that cannot be used are skipped and reported in the
+// error; a missing directory is not an error.
+func Load(dir string, base []Theme) ([]Theme, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Theme
+	var errs []error
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var t Theme
+			t, err = Parse(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), ext, data, base)
+			t.Source = path
+			if err == nil {
+				out = append(out, t)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// Parse reads a theme file in the format given by ext (".json" or
+// ".toml"). name is used when the file does not set one.
+func Parse(name, ext string, data []byte, base []Theme) (Theme, error) {
+	var fields map[string]string
+	var err error
+	if ext == ".json" {
+		fields, err = parseJSON(data)
+	} else {
+		fields, err = parseTOML(data)
+	}
+	if err != nil {
+		return Theme{}, err
+	}
+
+	var t Theme
+	if parent := fields["extends"]; parent != "" {
+		i := Find(base, parent)
+		if i < 0 {
+			return Theme{}, fmt.Errorf("extends: no theme named %q", parent)
+		}
+		t = base[i].unresolve()
+	}
+	t.Name = name
+	if v, ok := fields["name"]; ok && v != "" {
+		t.Name = v
+	}
+	if v, ok := fields["light"]; ok {
+		light, err := strconv.ParseBool(v)
+		if err != nil {
+			return Theme{}, fmt.Errorf("light: %q is not true or false", v)
+		}
+		t.Light = light
+	}
+	t.Family = fields["family"]
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch k {
+		case "name", "extends", "light", "family":
+			continue
+		}
+		slot, ok := colorKeys[k]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown key %q", k)
+		}
+		v := fields[k]
+		if !validColor(v) {
+			return Theme{}, fmt.Errorf("%s: %q is not a #rrggbb color or a number from 0 to 255", k, v)
+		}
+		*slot(&t) = lipgloss.Color(v)
+	}
+	if fields["extends"] == "" {
+		for _, k := range required {
+			if fields[k] == "" {
+				return Theme{}, fmt.Errorf("missing %s", k)
+			}
+		}
+	}
+	return t.Resolve(), nil
+}
+
+func validColor(v string) bool {
+	if hexColor.MatchString(v) {
+		return true
+	}
+	n, err := strconv.Atoi(v)
+	return err == nil && n >= 0 && n <= 255
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			fields[k] = v
+		case bool:
+			fields[k] = strconv.FormatBool(v)
+		case float64:
+			fields[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("%s: expected a string", k)
+		}
+	}
+	return fields, nil
+}
+
+// parseTOML reads the flat subset of TOML theme files need: key = value
+// lines, # comments and table headers, which are ignored so colors may sit
+// under [colors]
+func parseTOML(data []byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		k = strings.Trim(strings.TrimSpace(k), `"`)
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, `"`) {
+			end := strings.Index(v[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", i+1)
+			}
+			v = v[1 : end+1]
+		} else if c := strings.Index(v, "#"); c >= 0 {
+			v = strings.TrimSpace(v[:c])
+		}
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+// unresolve clears the slots that only repeat what Resolve would give, so
+// a theme extending this one derives them from its own colors
+func (t Theme) unresolve() Theme {
+	t.Family = ""
+	t.Source = ""
+	if t.BadgeFg == t.Bg {
+		t.BadgeFg = ""
+	}
+	if t.Border == t.Accent {
+		t.Border = ""
+	}
+	if t.PriorityHigh == t.Warning {
+		t.PriorityHigh = ""
+	}
+	if t.PriorityMed == t.Secondary {
+		t.PriorityMed = ""
+	}
+	if t.PriorityLow == t.Dim {
+		t.PriorityLow = ""
+	}
+	return t
+}
+
+// Merge returns base followed by extra, where a theme in extra replaces
+// the one in base with the same name
+func Merge(base, extra []Theme) []Theme {
+	out := append([]Theme(nil), base...)
+	for _, t := range extra {
+		if i := Find(out, t.Name); i >= 0 {
+			out[i] = t
+		} else {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package themes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Find returns the index of the theme with the given name, ignoring case,
+// or -1
+func Find(list []Theme, name string) int {
+	for i, t := range list {
+		if strings.EqualFold(t.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Variant returns the index of the theme in the same family as list[i]
+// that suits a light or dark background, or i when the family has none
+func Variant(list []Theme, i int, light bool) int {
+	if i < 0 || i >= len(list) || list[i].Light == light {
+		return i
+	}
+	for j, t := range list {
+		if t.Family == list[i].Family && t.Light == light {
+			return j
+		}
+	}
+	return i
+}
+
+// Degrade adapts a theme to a terminal with only 16 colors. Colors are
+// mapped to the nearest of the 16, and those that would become
+// indistinguishable from the text or background are replaced. Richer
+// terminals are left to lipgloss, which rounds to the 256-color palette.
+func Degrade(t Theme, p termenv.Profile) Theme {
+	if p != termenv.ANSI {
+		return t
+	}
+	for _, slot := range colorKeys {
+		c := slot(&t)
+		*c = ansi(*c)
+	}
+	t.SelectionBg = ""
+	if t.Dim == t.Fg {
+		t.Dim = "8"
+		if t.Fg == "8" {
+			t.Dim = "7"
+		}
+	}
+	for _, c := range []*lipgloss.Color{&t.Accent, &t.Border, &t.Secondary} {
+		if *c == t.Bg {
+			*c = t.Fg
+		}
+	}
+	return t
+}
+
+// ansi maps a color to the nearest of the 16 basic colors
+func ansi(c lipgloss.Color) lipgloss.Color {
+	if c == "" {
+		return c
+	}
+	if a, ok := termenv.ANSI.Color(string(c)).(termenv.ANSIColor); ok {
+		return lipgloss.Color(strconv.Itoa(int(a)))
+	}
+	return c
+}
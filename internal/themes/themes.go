@@ -12,20 +12,93 @@ type Theme struct {
 	Secondary lipgloss.Color
 	Success   lipgloss.Color
 	Warning   lipgloss.Color
+
+	// Optional slots, filled from the colors above by Resolve
+	BadgeFg      lipgloss.Color // text on category badges
+	SelectionBg  lipgloss.Color // behind the selected task, none if empty
+	Border       lipgloss.Color
+	PriorityHigh lipgloss.Color
+	PriorityMed  lipgloss.Color
+	PriorityLow  lipgloss.Color
+
+	// Light marks themes for light terminal backgrounds. Themes sharing a
+	// Family are variants of each other.
+	Light  bool
+	Family string
+
+	// Source is the file a theme was loaded from, empty for built-ins
+	Source string
 }
 
-// All available themes
+// Resolve fills the optional slots that are not set
+func (t Theme) Resolve() Theme {
+	if t.Family == "" {
+		t.Family = t.Name
+	}
+	if t.BadgeFg == "" {
+		t.BadgeFg = t.Bg
+	}
+	if t.Border == "" {
+		t.Border = t.Accent
+	}
+	if t.PriorityHigh == "" {
+		t.PriorityHigh = t.Warning
+	}
+	if t.PriorityMed == "" {
+		t.PriorityMed = t.Secondary
+	}
+	if t.PriorityLow == "" {
+		t.PriorityLow = t.Dim
+	}
+	return t
+}
+
+func builtin(name string, bg, fg, dim, accent, secondary, success, warning lipgloss.Color) Theme {
+	return Theme{Name: name, Bg: bg, Fg: fg, Dim: dim, Accent: accent,
+		Secondary: secondary, Success: success, Warning: warning}.Resolve()
+}
+
+// lightOf marks a built-in as the light variant of a dark theme
+func (t Theme) lightOf(family string) Theme {
+	t.Light = true
+	t.Family = family
+	return t
+}
+
+// All available themes: the dark ones first, then their light variants
 var All = []Theme{
-	{"Catppuccin", "#000000", "#cdd6f4", "#6c7086", "#cba6f7", "#f5c2e7", "#a6e3a1", "#f38ba8"},
-	{"Nord", "#2e3440", "#eceff4", "#4c566a", "#88c0d0", "#81a1c1", "#a3be8c", "#bf616a"},
-	{"Gruvbox", "#282828", "#ebdbb2", "#928374", "#fabd2f", "#fe8019", "#b8bb26", "#fb4934"},
-	{"Dracula", "#282a36", "#f8f8f2", "#6272a4", "#bd93f9", "#ff79c6", "#50fa7b", "#ff5555"},
-	{"Tokyo Night", "#1a1b26", "#c0caf5", "#565f89", "#7aa2f7", "#bb9af7", "#9ece6a", "#f7768e"},
-	{"Rose Pine", "#191724", "#e0def4", "#6e6a86", "#ebbcba", "#c4a7e7", "#31748f", "#eb6f92"},
-	{"Everforest", "#272e33", "#d3c6aa", "#859289", "#a7c080", "#7fbbb3", "#a7c080", "#e67e80"},
-	{"One Dark", "#282c34", "#abb2bf", "#5c6370", "#61afef", "#c678dd", "#98c379", "#e06c75"},
-	{"Solarized", "#002b36", "#839496", "#586e75", "#268bd2", "#2aa198", "#859900", "#dc322f"},
-	{"Kanagawa", "#1f1f28", "#dcd7ba", "#727169", "#7e9cd8", "#957fb8", "#76946a", "#c34043"},
+	builtin("Catppuccin", "#000000", "#cdd6f4", "#6c7086", "#cba6f7", "#f5c2e7", "#a6e3a1", "#f38ba8"),
+	builtin("Nord", "#2e3440", "#eceff4", "#4c566a", "#88c0d0", "#81a1c1", "#a3be8c", "#bf616a"),
+	builtin("Gruvbox", "#282828", "#ebdbb2", "#928374", "#fabd2f", "#fe8019", "#b8bb26", "#fb4934"),
+	builtin("Dracula", "#282a36", "#f8f8f2", "#6272a4", "#bd93f9", "#ff79c6", "#50fa7b", "#ff5555"),
+	builtin("Tokyo Night", "#1a1b26", "#c0caf5", "#565f89", "#7aa2f7", "#bb9af7", "#9ece6a", "#f7768e"),
+	builtin("Rose Pine", "#191724", "#e0def4", "#6e6a86", "#ebbcba", "#c4a7e7", "#31748f", "#eb6f92"),
+	builtin("Everforest", "#272e33", "#d3c6aa", "#859289", "#a7c080", "#7fbbb3", "#a7c080", "#e67e80"),
+	builtin("One Dark", "#282c34", "#abb2bf", "#5c6370", "#61afef", "#c678dd", "#98c379", "#e06c75"),
+	builtin("Solarized", "#002b36", "#839496", "#586e75", "#268bd2", "#2aa198", "#859900", "#dc322f"),
+	builtin("Kanagawa", "#1f1f28", "#dcd7ba", "#727169", "#7e9cd8", "#957fb8", "#76946a", "#c34043"),
+
+	builtin("Catppuccin Latte", "#eff1f5", "#4c4f69", "#9ca0b0", "#8839ef", "#ea76cb", "#40a02b", "#d20f39").lightOf("Catppuccin"),
+	builtin("Nord Light", "#eceff4", "#2e3440", "#7b88a1", "#5e81ac", "#81a1c1", "#5d7d3f", "#bf616a").lightOf("Nord"),
+	builtin("Gruvbox Light", "#fbf1c7", "#3c3836", "#928374", "#b57614", "#af3a03", "#79740e", "#9d0006").lightOf("Gruvbox"),
+	builtin("Alucard", "#fffbeb", "#1f1f1f", "#6c664b", "#644ac9", "#a3144d", "#14710a", "#cb3a2a").lightOf("Dracula"),
+	builtin("Tokyo Night Day", "#e1e2e7", "#3760bf", "#848cb5", "#2e7de9", "#9854f1", "#587539", "#f52a65").lightOf("Tokyo Night"),
+	builtin("Rose Pine Dawn", "#faf4ed", "#575279", "#9893a5", "#d7827e", "#907aa9", "#286983", "#b4637a").lightOf("Rose Pine"),
+	builtin("Everforest Light", "#fdf6e3", "#5c6a72", "#939f91", "#8da101", "#3a94c5", "#8da101", "#f85552").lightOf("Everforest"),
+	builtin("One Light", "#fafafa", "#383a42", "#a0a1a7", "#4078f2", "#a626a4", "#50a14f", "#e45649").lightOf("One Dark"),
+	builtin("Solarized Light", "#fdf6e3", "#657b83", "#93a1a1", "#268bd2", "#2aa198", "#859900", "#dc322f").lightOf("Solarized"),
+	builtin("Kanagawa Lotus", "#f2ecbc", "#545464", "#8a8980", "#4d699b", "#624c83", "#6f894e", "#c84053").lightOf("Kanagawa"),
+
+	// Maximum contrast, for low vision
+	highContrast(builtin("High Contrast", "#000000", "#ffffff", "#d0d0d0", "#ffff00", "#00ffff", "#00ff00", "#ff8080")),
+	highContrast(builtin("High Contrast Light", "#ffffff", "#000000", "#303030", "#0000c0", "#800080", "#006000", "#c00000").lightOf("High Contrast")),
+}
+
+// highContrast puts black text on category badges, which have mid-tone
+// colors, whatever the background
+func highContrast(t Theme) Theme {
+	t.BadgeFg = "#000000"
+	return t
 }
 
 // GetTheme returns a theme by index (wraps around)
@@ -0,0 +1,92 @@
+package themes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"paper.toml": `# A light theme
+name = "Paper"
+light = true
+
+[colors]
+bg = "#ffffff"
+fg = "#222222"
+dim = "#888888"
+accent = "#0055cc"   # links
+secondary = "#aa00aa"
+success = "#008800"
+warning = "#cc0000"
+selection_bg = "#e0e8ff"
+`,
+		"nord.json":   `{"extends": "Nord", "name": "Nord", "accent": "#ff8800", "priority_high": "196"}`,
+		"broken.json": `{"bg": "#000"}`,
+		"typo.toml":   "extends = \"Nord\"\naccnet = \"#fff\"\n",
+		"notes.txt":   "not a theme",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := Load(dir, All)
+	if err == nil || !strings.Contains(err.Error(), "broken.json: missing fg") ||
+		!strings.Contains(err.Error(), `typo.toml: unknown key "accnet"`) {
+		t.Errorf("err = %v", err)
+	}
+	list := Merge(All, loaded)
+	if len(list) != len(All)+1 {
+		t.Fatalf("got %d themes, want %d", len(list), len(All)+1)
+	}
+
+	paper := list[Find(list, "paper")]
+	if !paper.Light || paper.Accent != "#0055cc" || paper.SelectionBg != "#e0e8ff" || paper.Border != "#0055cc" {
+		t.Errorf("paper = %+v", paper)
+	}
+	nord := list[Find(list, "Nord")]
+	if nord.Source == "" || nord.Accent != "#ff8800" || nord.Border != "#ff8800" ||
+		nord.PriorityHigh != "196" || nord.Bg != All[1].Bg {
+		t.Errorf("nord = %+v", nord)
+	}
+}
+
+func TestVariant(t *testing.T) {
+	nord := Find(All, "Nord")
+	light := Variant(All, nord, true)
+	if All[light].Name != "Nord Light" {
+		t.Errorf("light Nord = %s", All[light].Name)
+	}
+	if Variant(All, light, false) != nord || Variant(All, nord, false) != nord {
+		t.Error("dark variant not found")
+	}
+
+	custom := append(All[:len(All):len(All)], Theme{Name: "Mine"}.Resolve())
+	if i := len(custom) - 1; Variant(custom, i, true) != i {
+		t.Error("theme without variants switched")
+	}
+}
+
+func TestDegrade(t *testing.T) {
+	th := All[Find(All, "Nord")]
+	if Degrade(th, termenv.TrueColor) != th {
+		t.Error("true color theme changed")
+	}
+	d := Degrade(th, termenv.ANSI)
+	for _, c := range []lipgloss.Color{d.Bg, d.Fg, d.Dim, d.Accent} {
+		if strings.HasPrefix(string(c), "#") {
+			t.Errorf("%s not mapped to the basic colors", c)
+		}
+	}
+	if d.Dim == d.Fg || d.Accent == d.Bg {
+		t.Errorf("colors collapsed: %+v", d)
+	}
+}
@@ -0,0 +1,289 @@
+// Package timelog records the time spent on tasks: the running timer,
+// optionally in pomodoro cycles, and the sessions it has logged.
+package timelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the time log within the data directory
+const FileName = "timelog.json"
+
+// Pomodoro splits a timer into work intervals separated by breaks
+type Pomodoro struct {
+	Work  time.Duration `json:"work"`
+	Break time.Duration `json:"break"`
+}
+
+// Timer is a running timer on a task; Pomodoro is nil for a plain timer
+type Timer struct {
+	TaskID   int       `json:"task_id"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	Pomodoro *Pomodoro `json:"pomodoro,omitempty"`
+}
+
+// Session is a stretch of time logged on a task
+type Session struct {
+	TaskID int       `json:"task_id"`
+	Title  string    `json:"title"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Duration is how long the session lasted
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Phase is where a pomodoro timer is: the Cycle'th work interval or the
+// break after it, with Left until the next phase
+type Phase struct {
+	Work  bool
+	Cycle int
+	Left  time.Duration
+}
+
+// Phase returns the timer's phase at now. A plain timer is always working.
+func (t Timer) Phase(now time.Time) Phase {
+	elapsed := max(now.Sub(t.Start), 0)
+	p := t.Pomodoro
+	if p == nil || p.Work <= 0 {
+		return Phase{Work: true, Cycle: 1}
+	}
+	cycle := p.Work + p.Break
+	into := elapsed % cycle
+	n := int(elapsed/cycle) + 1
+	if into < p.Work {
+		return Phase{Work: true, Cycle: n, Left: p.Work - into}
+	}
+	return Phase{Cycle: n, Left: cycle - into}
+}
+
+// Sessions returns the time worked from the start until end: one session
+// for a plain timer, one per work interval for a pomodoro timer
+func (t Timer) Sessions(end time.Time) []Session {
+	if !end.After(t.Start) {
+		return nil
+	}
+	p := t.Pomodoro
+	if p == nil || p.Work <= 0 {
+		return []Session{{TaskID: t.TaskID, Title: t.Title, Start: t.Start, End: end}}
+	}
+	var out []Session
+	for start := t.Start; start.Before(end); start = start.Add(p.Work + p.Break) {
+		stop := start.Add(p.Work)
+		if stop.After(end) {
+			stop = end
+		}
+		out = append(out, Session{TaskID: t.TaskID, Title: t.Title, Start: start, End: stop})
+	}
+	return out
+}
+
+// Worked is the time worked from the start until now, leaving out breaks
+func (t Timer) Worked(now time.Time) time.Duration {
+	var total time.Duration
+	for _, s := range t.Sessions(now) {
+		total += s.Duration()
+	}
+	return total
+}
+
+// Store holds the running timer and the logged sessions, and writes them
+// to disk on every change. It is safe for concurrent use. The file is
+// re-read before each change, so the TUI and the timer command can share
+// it, and a file that can't be parsed is never overwritten.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	data file
+}
+
+type file struct {
+	Active   *Timer    `json:"active,omitempty"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Load reads the time log in dataDir. A missing file gives an empty log;
+// on a malformed file the log is empty and the error is returned.
+func Load(dataDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dataDir, FileName)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		s.data = file{}
+		return s, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// Active returns the running timer, if any
+func (s *Store) Active() (Timer, bool) {
+	if s == nil {
+		return Timer{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Active == nil {
+		return Timer{}, false
+	}
+	return *s.data.Active, true
+}
+
+// Start starts a timer, first stopping and logging the running one. It
+// returns the sessions that were logged.
+func (s *Store) Start(t Timer) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	logged := s.stop(t.Start)
+	s.data.Active = &t
+	return logged, s.save()
+}
+
+// Stop stops the running timer at now and logs its sessions. It returns
+// false if no timer was running.
+func (s *Store) Stop(now time.Time) ([]Session, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, false, err
+	}
+	if s.data.Active == nil {
+		return nil, false, nil
+	}
+	logged := s.stop(now)
+	return logged, true, s.save()
+}
+
+// stop logs and clears the running timer; the caller holds s.mu
+func (s *Store) stop(now time.Time) []Session {
+	if s.data.Active == nil {
+		return nil
+	}
+	logged := s.data.Active.Sessions(now)
+	s.data.Sessions = append(s.data.Sessions, logged...)
+	s.data.Active = nil
+	return logged
+}
+
+// Sessions returns the logged sessions that ended after since, oldest
+// first; taskID 0 means every task
+func (s *Store) Sessions(taskID int, since time.Time) []Session {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Session
+	for _, sess := range s.data.Sessions {
+		if (taskID == 0 || sess.TaskID == taskID) && sess.End.After(since) {
+			out = append(out, sess)
+		}
+	}
+	return out
+}
+
+// Logged is the total time on a task: its sessions plus the running timer
+// up to now if it is on that task
+func (s *Store) Logged(taskID int, now time.Time) time.Duration {
+	var total time.Duration
+	for _, sess := range s.Sessions(taskID, time.Time{}) {
+		total += sess.Duration()
+	}
+	if t, ok := s.Active(); ok && t.TaskID == taskID {
+		total += t.Worked(now)
+	}
+	return total
+}
+
+// Total is the time logged on one task
+type Total struct {
+	TaskID   int
+	Title    string
+	Logged   time.Duration
+	Sessions int
+}
+
+// Totals adds up sessions per task, most time first
+func Totals(sessions []Session) []Total {
+	byTask := map[int]*Total{}
+	var order []int
+	for _, sess := range sessions {
+		t, ok := byTask[sess.TaskID]
+		if !ok {
+			t = &Total{TaskID: sess.TaskID}
+			byTask[sess.TaskID] = t
+			order = append(order, sess.TaskID)
+		}
+		t.Title = sess.Title
+		t.Logged += sess.Duration()
+		t.Sessions++
+	}
+	out := make([]Total, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byTask[id])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Logged > out[j].Logged })
+	return out
+}
+
+// reload re-reads the log another process may have changed; the caller
+// holds s.mu. It fails, leaving the state as it was, if the file can't be
+// read or parsed.
+func (s *Store) reload() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.data = file{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+	s.data = f
+	return nil
+}
+
+// save writes the log; the caller holds s.mu
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Format renders a duration as a clock, e.g. "4:05" or "1:02:03"
+func Format(d time.Duration) string {
+	d = max(d, 0).Round(time.Second)
+	h, m, sec := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
@@ -0,0 +1,167 @@
+package timelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPomodoro(t *testing.T) {
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	timer := Timer{TaskID: 1, Start: start, Pomodoro: &Pomodoro{Work: 25 * time.Minute, Break: 5 * time.Minute}}
+
+	tests := []struct {
+		after time.Duration
+		want  Phase
+	}{
+		{0, Phase{Work: true, Cycle: 1, Left: 25 * time.Minute}},
+		{24 * time.Minute, Phase{Work: true, Cycle: 1, Left: time.Minute}},
+		{25 * time.Minute, Phase{Cycle: 1, Left: 5 * time.Minute}},
+		{31 * time.Minute, Phase{Work: true, Cycle: 2, Left: 24 * time.Minute}},
+	}
+	for _, tt := range tests {
+		if got := timer.Phase(start.Add(tt.after)); got != tt.want {
+			t.Errorf("Phase(+%s) = %+v, want %+v", tt.after, got, tt.want)
+		}
+	}
+
+	// Breaks are not logged: 25m, then 10m into the second interval
+	sessions := timer.Sessions(start.Add(40 * time.Minute))
+	if len(sessions) != 2 || sessions[1].Start != start.Add(30*time.Minute) {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+	if got := timer.Worked(start.Add(40 * time.Minute)); got != 35*time.Minute {
+		t.Errorf("worked = %s", got)
+	}
+	if got := timer.Worked(start.Add(28 * time.Minute)); got != 25*time.Minute {
+		t.Errorf("worked during break = %s", got)
+	}
+
+	plain := Timer{TaskID: 1, Start: start}
+	if got := plain.Phase(start.Add(time.Hour)); !got.Work || got.Cycle != 1 {
+		t.Errorf("plain phase = %+v", got)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	if _, err := s.Start(Timer{TaskID: 1, Title: "Write report", Start: start}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting another timer logs the running one
+	logged, err := s.Start(Timer{TaskID: 2, Title: "Fix tap", Start: start.Add(time.Hour)})
+	if err != nil || len(logged) != 1 || logged[0].Duration() != time.Hour {
+		t.Fatalf("switch logged %+v, %v", logged, err)
+	}
+	if got := s.Logged(2, start.Add(90*time.Minute)); got != 30*time.Minute {
+		t.Errorf("running logged = %s", got)
+	}
+
+	// The running timer survives a reload
+	s, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active, ok := s.Active(); !ok || active.TaskID != 2 {
+		t.Fatalf("active = %+v, %v", active, ok)
+	}
+	if _, ok, err := s.Stop(start.Add(150 * time.Minute)); !ok || err != nil {
+		t.Fatalf("stop = %v, %v", ok, err)
+	}
+	if _, ok, _ := s.Stop(start.Add(3 * time.Hour)); ok {
+		t.Error("stopped twice")
+	}
+	if got := len(s.Sessions(0, start.Add(90*time.Minute))); got != 1 {
+		t.Errorf("sessions since = %d", got)
+	}
+
+	totals := Totals(s.Sessions(0, time.Time{}))
+	if len(totals) != 2 || totals[0].Title != "Fix tap" || totals[1].Logged != time.Hour {
+		t.Errorf("totals = %+v", totals)
+	}
+
+	// A nil store, as when the log failed to load, has nothing running
+	var none *Store
+	if _, ok := none.Active(); ok || none.Logged(1, start) != 0 {
+		t.Error("nil store reports time")
+	}
+}
+
+func TestStoresShareTheFile(t *testing.T) {
+	dir := t.TempDir()
+	tui, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+
+	// A session logged from the command line survives the TUI's next write
+	if _, err := cli.Start(Timer{TaskID: 1, Start: start}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := cli.Stop(start.Add(time.Hour)); !ok || err != nil {
+		t.Fatalf("stop = %v, %v", ok, err)
+	}
+	if _, err := tui.Start(Timer{TaskID: 2, Start: start.Add(2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// and the command line can stop the TUI's timer
+	logged, ok, err := cli.Stop(start.Add(3 * time.Hour))
+	if !ok || err != nil || len(logged) != 1 || logged[0].TaskID != 2 {
+		t.Fatalf("stop = %+v, %v, %v", logged, ok, err)
+	}
+	if got := Totals(cli.Sessions(0, time.Time{})); len(got) != 2 {
+		t.Errorf("totals = %+v, want both tasks", got)
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(dir)
+	if err == nil {
+		t.Error("no error for malformed log")
+	}
+	if _, ok := s.Active(); ok {
+		t.Error("malformed log has a timer")
+	}
+
+	// The sessions it may hold are not overwritten
+	if _, err := s.Start(Timer{TaskID: 1, Start: time.Now()}); err == nil {
+		t.Error("started a timer over a malformed log")
+	}
+	if _, _, err := s.Stop(time.Now()); err == nil {
+		t.Error("stopped a timer in a malformed log")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{" {
+		t.Errorf("malformed log overwritten with %s", data)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                 "0:00",
+		245 * time.Second: "4:05",
+		time.Hour + 2*time.Minute + 3*time.Second: "1:02:03",
+		-time.Minute: "0:00",
+	} {
+		if got := Format(d); got != want {
+			t.Errorf("Format(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
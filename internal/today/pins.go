@@ -0,0 +1,100 @@
+package today
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileName is the name of the pins file within the data directory
+const FileName = "pins.json"
+
+// Pins holds the IDs of tasks pinned to today and writes them to disk on
+// every change. It is safe for concurrent use.
+type Pins struct {
+	path string
+	mu   sync.Mutex
+	ids  map[int]bool
+}
+
+// Load reads the pins file in dataDir. A missing file gives no pins; on a
+// malformed file there are no pins and the error is returned.
+func Load(dataDir string) (*Pins, error) {
+	p := &Pins{path: filepath.Join(dataDir, FileName), ids: make(map[int]bool)}
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	var ids []int
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return p, fmt.Errorf("%s: %w", p.path, err)
+	}
+	for _, id := range ids {
+		p.ids[id] = true
+	}
+	return p, nil
+}
+
+// Has reports whether a task is pinned
+func (p *Pins) Has(taskID int) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ids[taskID]
+}
+
+// Set pins or unpins a task
+func (p *Pins) Set(taskID int, pinned bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ids[taskID] == pinned {
+		return nil
+	}
+	if pinned {
+		p.ids[taskID] = true
+	} else {
+		delete(p.ids, taskID)
+	}
+	return p.save()
+}
+
+// IDs returns the pinned task IDs in ascending order
+func (p *Pins) IDs() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]int, 0, len(p.ids))
+	for id := range p.ids {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// save writes the pins; the caller holds p.mu
+func (p *Pins) save() error {
+	ids := make([]int, 0, len(p.ids))
+	for id := range p.ids {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
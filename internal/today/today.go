@@ -0,0 +1,112 @@
+// Package today picks the tasks to work on today: overdue ones, ones due
+// or scheduled today and ones pinned by hand.
+package today
+
+import (
+	"sort"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+// Reason says why a task is on today's list
+type Reason string
+
+const (
+	Overdue   Reason = "overdue"
+	DueToday  Reason = "due today"
+	Scheduled Reason = "scheduled"
+	Pinned    Reason = "pinned"
+)
+
+// DeferHour is the time of day deferred tasks without a due date get
+const DeferHour = 17
+
+// Why returns the reason an open task belongs on today's list as of now,
+// or false if it does not
+func Why(t api.Task, pins *Pins, now time.Time) (Reason, bool) {
+	if t.Status == "done" {
+		return "", false
+	}
+	end := startOfDay(now).AddDate(0, 0, 1)
+	switch {
+	case t.DueAt != nil && t.DueAt.Before(now):
+		return Overdue, true
+	case t.DueAt != nil && t.DueAt.Before(end):
+		return DueToday, true
+	case t.ScheduledStart != nil && !t.ScheduledStart.Before(startOfDay(now)) && t.ScheduledStart.Before(end):
+		return Scheduled, true
+	case pins.Has(t.ID):
+		return Pinned, true
+	}
+	return "", false
+}
+
+// Select returns the tasks on today's list, in the order to do them
+func Select(tasks []api.Task, pins *Pins, now time.Time) []api.Task {
+	var out []api.Task
+	for _, t := range tasks {
+		if _, ok := Why(t, pins, now); ok {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return Less(out[i], out[j]) })
+	return out
+}
+
+// Less orders the plan: higher priority first, then quicker tasks, with
+// tasks that have no estimate after those that do
+func Less(a, b api.Task) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if (a.EffortMin > 0) != (b.EffortMin > 0) {
+		return a.EffortMin > 0
+	}
+	return a.EffortMin < b.EffortMin
+}
+
+// Effort adds up the estimated minutes of the open tasks
+func Effort(tasks []api.Task) int {
+	total := 0
+	for _, t := range tasks {
+		if t.Status != "done" {
+			total += t.EffortMin
+		}
+	}
+	return total
+}
+
+// Tomorrow is the due date a task deferred at now gets: tomorrow, at the
+// time it was due or DeferHour if it had no due date
+func Tomorrow(t api.Task, now time.Time) time.Time {
+	day := startOfDay(now).AddDate(0, 0, 1)
+	hour, minute := DeferHour, 0
+	if t.DueAt != nil {
+		due := t.DueAt.In(now.Location())
+		hour, minute = due.Hour(), due.Minute()
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+}
+
+// TomorrowBlock is the scheduled block a task deferred at now gets: the
+// same times tomorrow. The start is nil if the task has no block before
+// tomorrow, and the end is nil if the block has none.
+func TomorrowBlock(t api.Task, now time.Time) (start, end *time.Time) {
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	if t.ScheduledStart == nil || !t.ScheduledStart.Before(tomorrow) {
+		return nil, nil
+	}
+	from := t.ScheduledStart.In(now.Location())
+	days := int(tomorrow.Sub(startOfDay(from)).Hours()+12) / 24
+	s := from.AddDate(0, 0, days)
+	if t.ScheduledEnd != nil {
+		e := t.ScheduledEnd.In(now.Location()).AddDate(0, 0, days)
+		end = &e
+	}
+	return &s, end
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
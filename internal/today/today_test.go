@@ -0,0 +1,88 @@
+package today
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackraven/todo-tui/internal/api"
+)
+
+func TestSelect(t *testing.T) {
+	dir := t.TempDir()
+	pins, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pins.Set(5, true); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	at := func(days, hour int) *time.Time {
+		v := time.Date(2025, 3, 12+days, hour, 0, 0, 0, time.Local)
+		return &v
+	}
+	tasks := []api.Task{
+		{ID: 1, Title: "overdue", Priority: 3, DueAt: at(-1, 9)},
+		{ID: 2, Title: "due tonight", Priority: 3, EffortMin: 90, DueAt: at(0, 23)},
+		{ID: 3, Title: "due tomorrow", Priority: 9, DueAt: at(1, 9)},
+		{ID: 4, Title: "scheduled", Priority: 3, EffortMin: 15, ScheduledStart: at(0, 14)},
+		{ID: 5, Title: "pinned", Priority: 8},
+		{ID: 6, Title: "done", Priority: 9, Status: "done", DueAt: at(0, 12)},
+		{ID: 7, Title: "someday", Priority: 9},
+	}
+	var titles []string
+	for _, task := range Select(tasks, pins, now) {
+		titles = append(titles, task.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "pinned, scheduled, due tonight, overdue" {
+		t.Errorf("Select = %s", got)
+	}
+	if r, _ := Why(tasks[1], pins, now); r != DueToday {
+		t.Errorf("Why = %q", r)
+	}
+	if Effort(tasks) != 105 {
+		t.Errorf("Effort = %d", Effort(tasks))
+	}
+
+	// Pins survive a reload
+	pins, err = Load(dir)
+	if err != nil || !pins.Has(5) || len(pins.IDs()) != 1 {
+		t.Errorf("reloaded pins %v, %v", pins.IDs(), err)
+	}
+	os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0600)
+	if _, err := Load(dir); err == nil {
+		t.Error("loaded a malformed pins file")
+	}
+}
+
+func TestTomorrow(t *testing.T) {
+	now := time.Date(2025, 3, 31, 22, 0, 0, 0, time.Local)
+	due := time.Date(2025, 3, 30, 8, 30, 0, 0, time.Local)
+	if got := Tomorrow(api.Task{DueAt: &due}, now); !got.Equal(time.Date(2025, 4, 1, 8, 30, 0, 0, time.Local)) {
+		t.Errorf("Tomorrow = %v", got)
+	}
+	if got := Tomorrow(api.Task{}, now); !got.Equal(time.Date(2025, 4, 1, DeferHour, 0, 0, 0, time.Local)) {
+		t.Errorf("Tomorrow without a due date = %v", got)
+	}
+}
+
+func TestTomorrowBlock(t *testing.T) {
+	now := time.Date(2025, 3, 31, 22, 0, 0, 0, time.Local)
+	start := time.Date(2025, 3, 31, 14, 0, 0, 0, time.Local)
+	end := time.Date(2025, 3, 31, 15, 30, 0, 0, time.Local)
+	s, e := TomorrowBlock(api.Task{ScheduledStart: &start, ScheduledEnd: &end}, now)
+	if s == nil || !s.Equal(time.Date(2025, 4, 1, 14, 0, 0, 0, time.Local)) ||
+		e == nil || !e.Equal(time.Date(2025, 4, 1, 15, 30, 0, 0, time.Local)) {
+		t.Errorf("TomorrowBlock = %v, %v", s, e)
+	}
+
+	// A block already tomorrow or later stays where it is
+	later := time.Date(2025, 4, 1, 9, 0, 0, 0, time.Local)
+	if s, _ := TomorrowBlock(api.Task{ScheduledStart: &later}, now); s != nil {
+		t.Errorf("moved a block that isn't today: %v", s)
+	}
+}